@@ -0,0 +1,172 @@
+// Package activities adapts script runs to workflow engines such as Temporal and River without depending on
+// their SDKs. Inputs and outputs are plain serializable structs, heartbeats are sent through a function with
+// the signature of Temporal's activity.RecordHeartbeat, and cancellation follows the activity context.
+//
+// With Temporal, register the Run method of an Activity:
+//
+//	act := &activities.Activity{Runner: runner, Heartbeat: activity.RecordHeartbeat}
+//	worker.RegisterActivityWithOptions(act.Run, activity.RegisterOptions{Name: "RunScript"})
+//
+// With River, use JobArgs as the job arguments and call Run from the worker:
+//
+//	func (w *ScriptWorker) Work(ctx context.Context, job *river.Job[activities.JobArgs]) error {
+//		_, err := w.Activity.Run(ctx, job.Args.Input)
+//		return err
+//	}
+package activities
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/joeychilson/uvgo"
+)
+
+// Input describes a script run in a serializable form
+type Input struct {
+	// ScriptPath is the script to run, or empty when Script holds the script content
+	ScriptPath string   `json:"script_path,omitempty"`
+	Script     string   `json:"script,omitempty"`
+	Args       []string `json:"args,omitempty"`
+	// Payload is passed to the script in the UVGO_INPUT environment variable
+	Payload json.RawMessage `json:"payload,omitempty"`
+}
+
+// Output is the serializable result of a script run
+type Output struct {
+	Stdout   string        `json:"stdout"`
+	Stderr   string        `json:"stderr"`
+	ExitCode int           `json:"exit_code"`
+	Duration time.Duration `json:"duration"`
+}
+
+// JobArgs wraps an Input as River job arguments
+type JobArgs struct {
+	Input
+}
+
+// Kind implements river.JobArgs
+func (JobArgs) Kind() string {
+	return "uvgo_script"
+}
+
+// HeartbeatFunc records that an activity is alive, like Temporal's activity.RecordHeartbeat
+type HeartbeatFunc func(ctx context.Context, details ...any)
+
+// Progress is sent as heartbeat details
+type Progress struct {
+	// Elapsed is the time since the script started
+	Elapsed time.Duration `json:"elapsed"`
+	// Lines is the number of lines the script has written to stdout
+	Lines int `json:"lines"`
+	// LastLine is the most recent line the script wrote to stdout
+	LastLine string `json:"last_line,omitempty"`
+}
+
+// Activity runs scripts as workflow activities
+type Activity struct {
+	Runner *uvgo.Runner
+	// Heartbeat, when set, is called every HeartbeatInterval and whenever the script writes output, at most
+	// once per HeartbeatInterval. Progress is tracked with a line handler replacing any set on Runner.
+	Heartbeat HeartbeatFunc
+	// HeartbeatInterval defaults to 10 seconds
+	HeartbeatInterval time.Duration
+	// MapError, when set, converts run errors before they are returned, for example into non-retryable
+	// application errors of the workflow engine
+	MapError func(err error) error
+}
+
+// Run executes the script described by in. When the activity context is cancelled the script is killed and
+// the context error is returned, so the workflow engine records a cancellation rather than a failure.
+func (a *Activity) Run(ctx context.Context, in Input) (*Output, error) {
+	interval := a.HeartbeatInterval
+	if interval <= 0 {
+		interval = 10 * time.Second
+	}
+
+	var (
+		mu       sync.Mutex
+		progress Progress
+		lastBeat time.Time
+	)
+	start := time.Now()
+
+	beat := func(force bool) {
+		if a.Heartbeat == nil {
+			return
+		}
+		mu.Lock()
+		if !force && time.Since(lastBeat) < interval {
+			mu.Unlock()
+			return
+		}
+		lastBeat = time.Now()
+		progress.Elapsed = time.Since(start)
+		details := progress
+		mu.Unlock()
+
+		a.Heartbeat(ctx, details)
+	}
+
+	options := []uvgo.Option{uvgo.WithLineHandler(func(line string) {
+		mu.Lock()
+		progress.Lines++
+		progress.LastLine = line
+		mu.Unlock()
+		beat(false)
+	})}
+	if len(in.Payload) > 0 {
+		options = append(options, uvgo.WithInput(in.Payload))
+	}
+	runner := a.Runner.With(options...)
+
+	done := make(chan struct{})
+	defer close(done)
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ticker.C:
+				beat(true)
+			case <-done:
+				return
+			}
+		}
+	}()
+
+	var (
+		result *uvgo.Result
+		err    error
+	)
+	if in.ScriptPath != "" {
+		result, err = runner.Run(ctx, in.ScriptPath, in.Args...)
+	} else {
+		result, err = runner.RunFromString(ctx, in.Script, in.Args...)
+	}
+
+	var out *Output
+	if result != nil {
+		out = &Output{
+			Stdout:   result.Stdout,
+			Stderr:   result.Stderr,
+			ExitCode: result.ExitCode,
+			Duration: result.WallTime,
+		}
+	}
+
+	if err != nil {
+		if ctxErr := ctx.Err(); ctxErr != nil && !errors.Is(ctxErr, context.DeadlineExceeded) {
+			return out, ctxErr
+		}
+		if a.MapError != nil {
+			err = a.MapError(err)
+		}
+		return out, fmt.Errorf("script activity failed: %w", err)
+	}
+	return out, nil
+}
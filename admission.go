@@ -0,0 +1,64 @@
+package uvgo
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync/atomic"
+)
+
+// ErrQueueFull is returned when a run is rejected because the maximum number of runs are already waiting
+var ErrQueueFull = errors.New("run queue is full")
+
+// WithConcurrencyLimit allows at most maxRunning scripts to run at once. Further runs wait for a slot until
+// their context ends; when maxPending is positive, runs arriving while maxPending runs are already waiting
+// fail immediately with ErrQueueFull. The limit is shared by runners derived with With.
+func WithConcurrencyLimit(maxRunning, maxPending int) Option {
+	return func(r *Runner) {
+		if maxRunning <= 0 {
+			r.admission = nil
+			return
+		}
+		r.admission = &admission{
+			slots:      make(chan struct{}, maxRunning),
+			maxPending: int64(maxPending),
+		}
+	}
+}
+
+// admission bounds the number of running and waiting runs
+type admission struct {
+	slots      chan struct{}
+	maxPending int64
+	pending    atomic.Int64
+}
+
+// acquire takes a run slot, returning a function releasing it
+func (a *admission) acquire(ctx context.Context) (release func(), err error) {
+	if a == nil {
+		return func() {}, nil
+	}
+
+	select {
+	case a.slots <- struct{}{}:
+		return a.release, nil
+	default:
+	}
+
+	if n := a.pending.Add(1); a.maxPending > 0 && n > a.maxPending {
+		a.pending.Add(-1)
+		return nil, ErrQueueFull
+	}
+	defer a.pending.Add(-1)
+
+	select {
+	case a.slots <- struct{}{}:
+		return a.release, nil
+	case <-ctx.Done():
+		return nil, fmt.Errorf("run queue wait cancelled: %w", ctx.Err())
+	}
+}
+
+func (a *admission) release() {
+	<-a.slots
+}
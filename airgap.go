@@ -0,0 +1,47 @@
+package uvgo
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+)
+
+// WithAirGapped configures the runner for machines without network access: uv runs offline, installs packages
+// only from the wheels in wheelDir instead of any index, and never downloads a Python interpreter, so the
+// requested Python must already be installed. Call Validate before the first run to check that uv, the
+// interpreter, the wheels for every dependency and the cache are all present locally.
+func WithAirGapped(wheelDir string) Option {
+	return func(r *Runner) { r.airGapped = wheelDir }
+}
+
+// airGapEnv returns the environment variables keeping uv off the network in air-gapped mode
+func (r *Runner) airGapEnv() []string {
+	if r.airGapped == "" {
+		return nil
+	}
+	wheelDir, err := filepath.Abs(r.airGapped)
+	if err != nil {
+		wheelDir = r.airGapped
+	}
+	return []string{
+		"UV_OFFLINE=1",
+		"UV_NO_INDEX=1",
+		"UV_FIND_LINKS=" + wheelDir,
+		"UV_PYTHON_DOWNLOADS=never",
+	}
+}
+
+// checkWheelDir checks that the wheel directory of air-gapped mode holds wheels
+func checkWheelDir(dir string) (string, error) {
+	if _, err := os.Stat(dir); err != nil {
+		return "", err
+	}
+	wheels, err := filepath.Glob(filepath.Join(dir, "*.whl"))
+	if err != nil {
+		return "", err
+	}
+	if len(wheels) == 0 {
+		return "", fmt.Errorf("no wheels found in %s", dir)
+	}
+	return fmt.Sprintf("%d wheels in %s", len(wheels), dir), nil
+}
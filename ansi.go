@@ -0,0 +1,18 @@
+package uvgo
+
+import "regexp"
+
+// ansiPattern matches ANSI escape sequences: CSI sequences such as colors and cursor movement, OSC sequences
+// such as hyperlinks and window titles, and two-character escapes
+var ansiPattern = regexp.MustCompile(`\x1b\[[0-?]*[ -/]*[@-~]|\x1b\][^\x07\x1b]*(?:\x07|\x1b\\)|\x1b[@-Z\\-_]`)
+
+// StripANSI removes ANSI escape sequences, such as color codes, from s
+func StripANSI(s string) string {
+	return ansiPattern.ReplaceAllString(s, "")
+}
+
+// WithStripANSI removes ANSI escape sequences from the captured output of every run and from the lines passed
+// to output handlers, for tools that colorize their output regardless of where it goes
+func WithStripANSI() Option {
+	return func(r *Runner) { r.stripANSI = true }
+}
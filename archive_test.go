@@ -0,0 +1,143 @@
+package uvgo
+
+import (
+	"archive/tar"
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// tarEntry is a file, directory or symlink in a test archive
+type tarEntry struct {
+	name     string
+	typeflag byte
+	body     string
+	linkname string
+}
+
+func buildTar(t *testing.T, entries []tarEntry) *bytes.Buffer {
+	t.Helper()
+	var buf bytes.Buffer
+	tw := tar.NewWriter(&buf)
+	for _, e := range entries {
+		header := &tar.Header{Name: e.name, Typeflag: e.typeflag, Mode: 0o644, Size: int64(len(e.body)), Linkname: e.linkname}
+		if e.typeflag != tar.TypeReg {
+			header.Size = 0
+		}
+		if err := tw.WriteHeader(header); err != nil {
+			t.Fatal(err)
+		}
+		if e.typeflag == tar.TypeReg {
+			if _, err := tw.Write([]byte(e.body)); err != nil {
+				t.Fatal(err)
+			}
+		}
+	}
+	if err := tw.Close(); err != nil {
+		t.Fatal(err)
+	}
+	return &buf
+}
+
+func TestUntar(t *testing.T) {
+	tests := []struct {
+		name    string
+		entries []tarEntry
+		files   map[string]string
+		wantErr string
+	}{
+		{
+			name: "files and directories",
+			entries: []tarEntry{
+				{name: "./", typeflag: tar.TypeDir},
+				{name: "out/", typeflag: tar.TypeDir},
+				{name: "out/result.json", typeflag: tar.TypeReg, body: "{}"},
+				{name: "nested/deep/file.txt", typeflag: tar.TypeReg, body: "hi"},
+			},
+			files: map[string]string{"out/result.json": "{}", "nested/deep/file.txt": "hi"},
+		},
+		{
+			name:    "cleaned path inside the directory",
+			entries: []tarEntry{{name: "a/../b.txt", typeflag: tar.TypeReg, body: "b"}},
+			files:   map[string]string{"b.txt": "b"},
+		},
+		{
+			name:    "symlinks skipped",
+			entries: []tarEntry{{name: "link", typeflag: tar.TypeSymlink, linkname: "/etc/passwd"}},
+			files:   map[string]string{},
+		},
+		{
+			name:    "parent traversal",
+			entries: []tarEntry{{name: "../escape.txt", typeflag: tar.TypeReg, body: "x"}},
+			wantErr: "escapes the directory",
+		},
+		{
+			name:    "nested parent traversal",
+			entries: []tarEntry{{name: "a/../../escape.txt", typeflag: tar.TypeReg, body: "x"}},
+			wantErr: "escapes the directory",
+		},
+		{
+			name:    "absolute path",
+			entries: []tarEntry{{name: "/tmp/escape.txt", typeflag: tar.TypeReg, body: "x"}},
+			wantErr: "escapes the directory",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			root := t.TempDir()
+			dir := filepath.Join(root, "dest")
+			if err := os.Mkdir(dir, 0o755); err != nil {
+				t.Fatal(err)
+			}
+
+			err := untar(buildTar(t, tt.entries), dir)
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("untar() error = %v, want %q", err, tt.wantErr)
+				}
+				if _, err := os.Stat(filepath.Join(root, "escape.txt")); !os.IsNotExist(err) {
+					t.Error("untar() wrote a file outside the directory")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("untar(): %v", err)
+			}
+
+			got := map[string]string{}
+			filepath.WalkDir(dir, func(p string, d os.DirEntry, err error) error {
+				if err != nil || d.IsDir() {
+					return err
+				}
+				rel, _ := filepath.Rel(dir, p)
+				data, _ := os.ReadFile(p)
+				got[filepath.ToSlash(rel)] = string(data)
+				return nil
+			})
+			if len(got) != len(tt.files) {
+				t.Errorf("untar() extracted %v, want %v", got, tt.files)
+			}
+			for name, body := range tt.files {
+				if got[name] != body {
+					t.Errorf("%s = %q, want %q", name, got[name], body)
+				}
+			}
+		})
+	}
+}
+
+func TestUntarOverwrites(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "result.txt"), []byte("old content"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if err := untar(buildTar(t, []tarEntry{{name: "result.txt", typeflag: tar.TypeReg, body: "new"}}), dir); err != nil {
+		t.Fatal(err)
+	}
+	if data, _ := os.ReadFile(filepath.Join(dir, "result.txt")); string(data) != "new" {
+		t.Errorf("result.txt = %q, want %q", data, "new")
+	}
+}
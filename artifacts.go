@@ -0,0 +1,119 @@
+package uvgo
+
+import (
+	"fmt"
+	"io"
+	"io/fs"
+	"mime"
+	"net/http"
+	"os"
+	"path/filepath"
+)
+
+// Artifact represents a file the script wrote to its output directory
+type Artifact struct {
+	// Name is the slash-separated path relative to the output directory
+	Name        string
+	Size        int64
+	ContentType string
+	Data        []byte
+	// Truncated reports whether Data was cut off at the configured size limit
+	Truncated bool
+}
+
+// Artifact returns the collected artifact with the given name
+func (r *Result) Artifact(name string) (*Artifact, bool) {
+	for i := range r.Artifacts {
+		if r.Artifacts[i].Name == name {
+			return &r.Artifacts[i], true
+		}
+	}
+	return nil, false
+}
+
+// withArtifacts exposes a managed output directory to the script through UVGO_OUTPUT_DIR and collects its files
+func (x *execution) withArtifacts(maxFileSize int64) error {
+	outputDir, err := x.tempPath("output")
+	if err != nil {
+		return err
+	}
+	if err := os.Mkdir(outputDir, 0o755); err != nil {
+		return fmt.Errorf("failed to create output directory: %w", err)
+	}
+
+	x.env = append(x.env, "UVGO_OUTPUT_DIR="+outputDir)
+	x.collect = append(x.collect, func(result *Result) error {
+		artifacts, err := collectArtifacts(outputDir, maxFileSize)
+		if err != nil {
+			return err
+		}
+		result.Artifacts = artifacts
+		return nil
+	})
+	return nil
+}
+
+func collectArtifacts(dir string, maxFileSize int64) ([]Artifact, error) {
+	var artifacts []Artifact
+
+	err := filepath.WalkDir(dir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil || d.IsDir() || !d.Type().IsRegular() {
+			return err
+		}
+
+		rel, err := filepath.Rel(dir, path)
+		if err != nil {
+			return err
+		}
+
+		artifact, err := readArtifact(path, maxFileSize)
+		if err != nil {
+			return err
+		}
+		artifact.Name = filepath.ToSlash(rel)
+		artifacts = append(artifacts, *artifact)
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to collect artifacts: %w", err)
+	}
+	return artifacts, nil
+}
+
+func readArtifact(path string, maxFileSize int64) (*Artifact, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	info, err := f.Stat()
+	if err != nil {
+		return nil, err
+	}
+
+	var reader io.Reader = f
+	if maxFileSize > 0 {
+		reader = io.LimitReader(f, maxFileSize)
+	}
+
+	data, err := io.ReadAll(reader)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Artifact{
+		Size:        info.Size(),
+		ContentType: contentType(path, data),
+		Data:        data,
+		Truncated:   int64(len(data)) < info.Size(),
+	}, nil
+}
+
+// contentType determines a file's MIME type from its extension, falling back to sniffing its content
+func contentType(path string, data []byte) string {
+	if t := mime.TypeByExtension(filepath.Ext(path)); t != "" {
+		return t
+	}
+	return http.DetectContentType(data)
+}
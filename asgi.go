@@ -0,0 +1,97 @@
+package uvgo
+
+import (
+	"context"
+	"net"
+	"regexp"
+	"strconv"
+	"time"
+)
+
+// uvicornPackage is the package providing the ASGI server
+const uvicornPackage = "uvicorn"
+
+// ASGIOptions configures an ASGI application served with ServeASGI
+type ASGIOptions struct {
+	ServeOptions
+	// Reload restarts the application whenever a Python file in ReloadDirs changes
+	Reload bool
+	// ReloadDirs are the directories watched for changes, defaulting to the working directory
+	ReloadDirs []string
+	// GracefulTimeout bounds how long uvicorn waits for in-flight requests when shutting down or reloading
+	GracefulTimeout time.Duration
+	// AccessLog, when set, receives every request logged by uvicorn
+	AccessLog func(AccessLogEntry)
+}
+
+// AccessLogEntry represents a single request logged by uvicorn
+type AccessLogEntry struct {
+	Client   string
+	Method   string
+	Path     string
+	Protocol string
+	Status   int
+}
+
+// accessLogPattern matches uvicorn access log lines such as `INFO:     127.0.0.1:5000 - "GET / HTTP/1.1" 200 OK`
+var accessLogPattern = regexp.MustCompile(`^(?:\w+:\s+)?(\S+) - "(\S+) (\S+) ([^"]+)" (\d{3})`)
+
+// ServeASGI serves an ASGI application, given as "module:attribute" like "main:app", with uvicorn and waits
+// until it is ready. uvicorn is added to the dependencies automatically and the application module is imported
+// relative to the working directory. ServeOptions.Args are passed to uvicorn as extra flags.
+func (r *Runner) ServeASGI(ctx context.Context, app string, opts ASGIOptions) (*Server, error) {
+	spec := serveSpec{
+		with: []string{uvicornPackage},
+		command: func(s *Server) []string {
+			args := []string{"python", "-m", "uvicorn", app}
+			if s.network == "unix" {
+				args = append(args, "--uds", s.Addr)
+			} else {
+				host, port, _ := net.SplitHostPort(s.Addr)
+				args = append(args, "--host", host, "--port", port)
+			}
+			if opts.Reload {
+				args = append(args, "--reload")
+				for _, dir := range opts.ReloadDirs {
+					args = append(args, "--reload-dir", dir)
+				}
+			}
+			if opts.GracefulTimeout > 0 {
+				args = append(args, "--timeout-graceful-shutdown", strconv.Itoa(int(opts.GracefulTimeout.Seconds())))
+			}
+			if opts.AccessLog == nil {
+				args = append(args, "--no-access-log")
+			}
+			return append(args, opts.Args...)
+		},
+	}
+
+	if opts.AccessLog != nil {
+		spec.stdout = func(line string) {
+			if entry, ok := parseAccessLog(line); ok {
+				opts.AccessLog(entry)
+			}
+		}
+	}
+
+	// Close must leave uvicorn time to finish in-flight requests before killing it
+	if opts.GracefulTimeout > 0 && opts.StopTimeout < opts.GracefulTimeout+5*time.Second {
+		opts.StopTimeout = opts.GracefulTimeout + 5*time.Second
+	}
+	return r.serve(ctx, opts.ServeOptions, spec)
+}
+
+func parseAccessLog(line string) (AccessLogEntry, bool) {
+	m := accessLogPattern.FindStringSubmatch(line)
+	if m == nil {
+		return AccessLogEntry{}, false
+	}
+	status, _ := strconv.Atoi(m[5])
+	return AccessLogEntry{
+		Client:   m[1],
+		Method:   m[2],
+		Path:     m[3],
+		Protocol: m[4],
+		Status:   status,
+	}, true
+}
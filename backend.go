@@ -0,0 +1,85 @@
+package uvgo
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"sync"
+)
+
+// BackendPolicy chooses the backend a run executes on by name, an empty name selecting the runner's executor
+type BackendPolicy func(ctx context.Context, req *RunRequest) string
+
+// Backends is a set of named executors, such as a LocalExecutor, a DockerExecutor and an SSHExecutor, that a
+// runner routes runs between
+type Backends struct {
+	mu        sync.RWMutex
+	executors map[string]Executor
+}
+
+// NewBackends creates an empty backend set
+func NewBackends() *Backends {
+	return &Backends{executors: make(map[string]Executor)}
+}
+
+// Register adds an executor under name, replacing any executor registered under the same name
+func (b *Backends) Register(name string, executor Executor) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.executors[name] = executor
+}
+
+// Get returns the executor registered under name
+func (b *Backends) Get(name string) (Executor, bool) {
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+	executor, ok := b.executors[name]
+	return executor, ok
+}
+
+// Names returns the names of the registered executors in sorted order
+func (b *Backends) Names() []string {
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+
+	names := make([]string, 0, len(b.executors))
+	for name := range b.executors {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// WithBackends routes every run to one of backends. The backend set with WithBackend takes precedence, then
+// the one chosen by policy, which may be nil; runs without a backend use the runner's executor.
+func WithBackends(backends *Backends, policy BackendPolicy) Option {
+	return func(r *Runner) {
+		r.backends = backends
+		r.backendPolicy = policy
+	}
+}
+
+// WithBackend selects the backend runs execute on, typically applied to a single run with Runner.With
+func WithBackend(name string) Option {
+	return func(r *Runner) { r.backend = name }
+}
+
+// route returns the executor a run executes on
+func (r *Runner) route(ctx context.Context, req *RunRequest) (Executor, error) {
+	name := r.backend
+	if name == "" && r.backendPolicy != nil {
+		name = r.backendPolicy(ctx, req)
+	}
+	if name == "" {
+		return r.executor, nil
+	}
+
+	if r.backends == nil {
+		return nil, fmt.Errorf("unknown backend %q: no backends configured", name)
+	}
+	executor, ok := r.backends.Get(name)
+	if !ok {
+		return nil, fmt.Errorf("unknown backend %q", name)
+	}
+	return executor, nil
+}
@@ -0,0 +1,91 @@
+package uvgo
+
+import (
+	"context"
+	"slices"
+	"sync"
+)
+
+// RunSpec describes one script run of a batch
+type RunSpec struct {
+	// ScriptPath is the script to run, or empty when Script holds the script content
+	ScriptPath string
+	Script     string
+	Args       []string
+	// Options are applied on top of the runner's configuration for this run only
+	Options []Option
+}
+
+// RunAll executes every spec concurrently and returns their results in spec order. The first failure cancels
+// the remaining runs and is returned as an ItemError identifying the failed spec.
+func (r *Runner) RunAll(ctx context.Context, specs ...RunSpec) ([]*Result, error) {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	var (
+		once     sync.Once
+		firstErr error
+	)
+	results := r.runBatch(ctx, specs, func(i int, err error) {
+		once.Do(func() {
+			firstErr = ItemError{Index: i, Err: err}
+			cancel()
+		})
+	})
+	return results, firstErr
+}
+
+// RunAllSettled executes every spec concurrently and waits for all of them, regardless of failures. Results
+// are returned in spec order, and every failure is listed in the returned *MapError.
+func (r *Runner) RunAllSettled(ctx context.Context, specs ...RunSpec) ([]*Result, error) {
+	var (
+		mu   sync.Mutex
+		errs []ItemError
+	)
+	results := r.runBatch(ctx, specs, func(i int, err error) {
+		mu.Lock()
+		defer mu.Unlock()
+		errs = append(errs, ItemError{Index: i, Err: err})
+	})
+
+	if len(errs) > 0 {
+		slices.SortFunc(errs, func(a, b ItemError) int { return a.Index - b.Index })
+		return results, &MapError{Errors: errs}
+	}
+	return results, nil
+}
+
+// runBatch runs specs concurrently, reporting each failure to fail
+func (r *Runner) runBatch(ctx context.Context, specs []RunSpec, fail func(i int, err error)) []*Result {
+	results := make([]*Result, len(specs))
+
+	var wg sync.WaitGroup
+	for i, spec := range specs {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+
+			runner := r
+			if len(spec.Options) > 0 {
+				runner = r.With(spec.Options...)
+			}
+
+			var (
+				result *Result
+				err    error
+			)
+			if spec.ScriptPath != "" {
+				result, err = runner.Run(ctx, spec.ScriptPath, spec.Args...)
+			} else {
+				result, err = runner.RunFromString(ctx, spec.Script, spec.Args...)
+			}
+
+			results[i] = result
+			if err != nil {
+				fail(i, err)
+			}
+		}()
+	}
+	wg.Wait()
+	return results
+}
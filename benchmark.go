@@ -0,0 +1,118 @@
+package uvgo
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"os"
+	"slices"
+	"time"
+)
+
+// BenchmarkOptions configures a benchmark
+type BenchmarkOptions struct {
+	// Runs is the number of measured runs, defaulting to 10
+	Runs int
+	// Warmups is the number of unmeasured runs after the cold run, defaulting to 1
+	Warmups int
+	// NoWarmup skips the warmup runs, so the measured runs start right after the cold run
+	NoWarmup bool
+	// Args are passed to the script on every run
+	Args []string
+}
+
+// BenchmarkResult represents the timing statistics of a benchmark
+type BenchmarkResult struct {
+	Runs         []time.Duration
+	Min          time.Duration
+	Median       time.Duration
+	P95          time.Duration
+	Max          time.Duration
+	Mean         time.Duration
+	SystemTime   time.Duration
+	UserTime     time.Duration
+	ColdStart    time.Duration
+	ColdOverhead time.Duration
+}
+
+// Benchmark runs a Python script file repeatedly and reports its timing statistics
+func (r *Runner) Benchmark(ctx context.Context, scriptPath string, opts BenchmarkOptions) (*BenchmarkResult, error) {
+	if _, err := os.Stat(scriptPath); os.IsNotExist(err) {
+		return nil, fmt.Errorf("script file does not exist: %w", err)
+	}
+	return benchmark(ctx, opts, func(ctx context.Context) (*Result, error) {
+		return r.execute(ctx, scriptPath, "", opts.Args)
+	})
+}
+
+// BenchmarkFromString runs a Python script from a string repeatedly and reports its timing statistics
+func (r *Runner) BenchmarkFromString(ctx context.Context, script string, opts BenchmarkOptions) (*BenchmarkResult, error) {
+	if script == "" {
+		return nil, fmt.Errorf("empty script provided")
+	}
+	return benchmark(ctx, opts, func(ctx context.Context) (*Result, error) {
+		return r.execute(ctx, "-", script, opts.Args)
+	})
+}
+
+func benchmark(ctx context.Context, opts BenchmarkOptions, run func(context.Context) (*Result, error)) (*BenchmarkResult, error) {
+	if opts.Runs <= 0 {
+		opts.Runs = 10
+	}
+	if opts.NoWarmup {
+		opts.Warmups = 0
+	} else if opts.Warmups <= 0 {
+		opts.Warmups = 1
+	}
+
+	// the first run pays for resolving and installing the environment
+	cold, err := run(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("cold run failed: %w", err)
+	}
+
+	for i := 0; i < opts.Warmups; i++ {
+		if _, err := run(ctx); err != nil {
+			return nil, fmt.Errorf("warmup run %d failed: %w", i+1, err)
+		}
+	}
+
+	b := &BenchmarkResult{ColdStart: cold.WallTime}
+	for i := 0; i < opts.Runs; i++ {
+		result, err := run(ctx)
+		if err != nil {
+			return nil, fmt.Errorf("run %d failed: %w", i+1, err)
+		}
+		b.Runs = append(b.Runs, result.WallTime)
+		b.SystemTime += result.SystemTime
+		b.UserTime += result.UserTime
+	}
+
+	sorted := slices.Clone(b.Runs)
+	slices.Sort(sorted)
+
+	var total time.Duration
+	for _, d := range sorted {
+		total += d
+	}
+
+	n := time.Duration(len(sorted))
+	b.Min = sorted[0]
+	b.Max = sorted[len(sorted)-1]
+	b.Median = percentile(sorted, 50)
+	b.P95 = percentile(sorted, 95)
+	b.Mean = total / n
+	b.SystemTime /= n
+	b.UserTime /= n
+	b.ColdOverhead = max(b.ColdStart-b.Median, 0)
+	return b, nil
+}
+
+// percentile returns the nearest-rank percentile of sorted durations
+func percentile(sorted []time.Duration, p float64) time.Duration {
+	if len(sorted) == 0 {
+		return 0
+	}
+	rank := int(math.Ceil(p/100*float64(len(sorted)))) - 1
+	return sorted[min(max(rank, 0), len(sorted)-1)]
+}
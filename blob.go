@@ -0,0 +1,121 @@
+package uvgo
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"os"
+	"path/filepath"
+)
+
+// blobDirective prefixes the stderr lines a script uses to return binary blobs
+const blobDirective = "@uvgo:blob"
+
+// Blob is binary data a script returned next to its output
+type Blob struct {
+	Name        string
+	ContentType string
+	Data        []byte
+}
+
+// Reader returns a reader over the blob data
+func (b *Blob) Reader() io.Reader {
+	return bytes.NewReader(b.Data)
+}
+
+// Blob returns the blob with the given name
+func (r *Result) Blob(name string) (*Blob, bool) {
+	for i := range r.Blobs {
+		if r.Blobs[i].Name == name {
+			return &r.Blobs[i], true
+		}
+	}
+	return nil, false
+}
+
+// BlobSnippet is Python source defining a blob function that returns binary data to the Go runner, either
+// inline or as the path of a file the script wrote, leaving stdout free for the JSON result
+const BlobSnippet = `import base64
+import json
+import os
+import sys
+
+
+def blob(name, data=None, path=None, content_type=None):
+    """Return binary data, or the file at path, to the Go runner as a named blob."""
+    entry = {"name": name}
+    if content_type is not None:
+        entry["content_type"] = content_type
+    if path is not None:
+        entry["path"] = os.path.abspath(path)
+    else:
+        entry["data"] = base64.b64encode(data).decode()
+    sys.stderr.write("@uvgo:blob " + json.dumps(entry) + "\n")
+    sys.stderr.flush()
+`
+
+// WithBlobs collects the binary blobs a script returns by writing lines of the form
+// "@uvgo:blob {"name": ..., "data": <base64>}" or "@uvgo:blob {"name": ..., "path": ...}" to stderr, as
+// BlobSnippet does, into Result.Blobs. Files referenced by path are read once the script exits, so they must
+// be on the host the executor runs them on.
+func WithBlobs() Option {
+	return func(r *Runner) { r.blobs = true }
+}
+
+// BlobOutput runs a script with blobs enabled and parses its output like StructuredOutput, so the typed data
+// and the blobs the script returned are available together
+func BlobOutput[T any](ctx context.Context, r *Runner, scriptPath string, args ...string) (*StructuredResult[T], error) {
+	if !r.blobs {
+		r = r.With(WithBlobs())
+	}
+	return StructuredOutput[T](ctx, r, scriptPath, args...)
+}
+
+// blobRef is a blob announced by a script, holding either its data or the path of the file holding it
+type blobRef struct {
+	Name        string `json:"name"`
+	ContentType string `json:"content_type"`
+	Data        []byte `json:"data"`
+	Path        string `json:"path"`
+}
+
+// withBlobs resolves the blobs announced by the script once it finishes and attaches them to the result
+func (x *execution) withBlobs() {
+	x.collect = append(x.collect, func(result *Result) error {
+		if x.blobErr != nil {
+			return x.blobErr
+		}
+		for _, ref := range x.blobRefs {
+			blob := Blob{Name: ref.Name, ContentType: ref.ContentType, Data: ref.Data}
+			if ref.Path != "" {
+				data, err := os.ReadFile(ref.Path)
+				if err != nil {
+					return fmt.Errorf("failed to read blob %s: %w", ref.Name, err)
+				}
+				blob.Data = data
+				if blob.ContentType == "" {
+					blob.ContentType = mime.TypeByExtension(filepath.Ext(ref.Path))
+				}
+			}
+			if blob.ContentType == "" {
+				blob.ContentType = http.DetectContentType(blob.Data)
+			}
+			result.Blobs = append(result.Blobs, blob)
+		}
+		return nil
+	})
+}
+
+// addBlob records a blob announced by the script
+func (x *execution) addBlob(payload string) {
+	var ref blobRef
+	if err := json.Unmarshal([]byte(payload), &ref); err != nil {
+		x.blobErr = fmt.Errorf("failed to parse blob: %w", err)
+		return
+	}
+	x.blobRefs = append(x.blobRefs, ref)
+}
@@ -0,0 +1,150 @@
+// Package bolthistory is a uvgo.HistoryStore backed by a bbolt database. Records are indexed by time, script
+// hash and failure, so queries only read the records they return rather than the whole history.
+package bolthistory
+
+import (
+	"bytes"
+	"context"
+	"encoding/binary"
+	"encoding/json"
+	"fmt"
+	"slices"
+	"time"
+
+	"github.com/joeychilson/uvgo"
+	bolt "go.etcd.io/bbolt"
+)
+
+var (
+	// recordsBucket maps the time key of every record to the record as JSON
+	recordsBucket = []byte("records")
+	// scriptsBucket holds the script hash followed by the time key of every record
+	scriptsBucket = []byte("scripts")
+	// failedBucket holds the time key of every failed run
+	failedBucket = []byte("failed")
+)
+
+// Store is a uvgo.HistoryStore keeping run records in a bbolt database file. The file is locked while the
+// store is open, so only one process can use it at a time.
+type Store struct {
+	db *bolt.DB
+}
+
+// Open opens the history database at path, creating it if needed
+func Open(path string) (*Store, error) {
+	db, err := bolt.Open(path, 0o600, &bolt.Options{Timeout: time.Second})
+	if err != nil {
+		return nil, fmt.Errorf("failed to open history database: %w", err)
+	}
+
+	err = db.Update(func(tx *bolt.Tx) error {
+		for _, name := range [][]byte{recordsBucket, scriptsBucket, failedBucket} {
+			if _, err := tx.CreateBucketIfNotExists(name); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		db.Close()
+		return nil, fmt.Errorf("failed to initialize history database: %w", err)
+	}
+	return &Store{db: db}, nil
+}
+
+// Close closes the database
+func (s *Store) Close() error {
+	return s.db.Close()
+}
+
+// Append implements uvgo.HistoryStore
+func (s *Store) Append(ctx context.Context, record uvgo.RunRecord) error {
+	data, err := json.Marshal(record)
+	if err != nil {
+		return fmt.Errorf("failed to marshal run record: %w", err)
+	}
+
+	key := timeKey(record.Time, record.ID)
+	err = s.db.Update(func(tx *bolt.Tx) error {
+		if err := tx.Bucket(recordsBucket).Put(key, data); err != nil {
+			return err
+		}
+		if err := tx.Bucket(scriptsBucket).Put(slices.Concat([]byte(record.ScriptHash), key), nil); err != nil {
+			return err
+		}
+		if record.Error != "" {
+			return tx.Bucket(failedBucket).Put(key, nil)
+		}
+		return nil
+	})
+	if err != nil {
+		return fmt.Errorf("failed to write run record: %w", err)
+	}
+	return nil
+}
+
+// Query implements uvgo.HistoryStore, returning the records matching q in the order they were recorded. It
+// walks the narrowest index from the most recent record back, stopping at Since or once Limit records match.
+func (s *Store) Query(ctx context.Context, q uvgo.HistoryQuery) ([]uvgo.RunRecord, error) {
+	var records []uvgo.RunRecord
+	err := s.db.View(func(tx *bolt.Tx) error {
+		index, prefix := tx.Bucket(recordsBucket), []byte(nil)
+		switch {
+		case q.ScriptHash != "":
+			index, prefix = tx.Bucket(scriptsBucket), []byte(q.ScriptHash)
+		case q.FailedOnly:
+			index = tx.Bucket(failedBucket)
+		}
+		values := tx.Bucket(recordsBucket)
+
+		end := slices.Concat(prefix, bytes.Repeat([]byte{0xff}, 8))
+		if !q.Until.IsZero() {
+			end = slices.Concat(prefix, timeKey(q.Until.Add(time.Nanosecond), ""))
+		}
+
+		c := index.Cursor()
+		k, _ := c.Seek(end)
+		if k == nil {
+			k, _ = c.Last()
+		} else {
+			k, _ = c.Prev()
+		}
+		for ; k != nil && bytes.HasPrefix(k, prefix); k, _ = c.Prev() {
+			if err := ctx.Err(); err != nil {
+				return err
+			}
+			key := k[len(prefix):]
+			if len(key) < 8 {
+				continue
+			}
+			if !q.Since.IsZero() && int64(binary.BigEndian.Uint64(key)) < q.Since.UnixNano() {
+				break
+			}
+
+			var record uvgo.RunRecord
+			if err := json.Unmarshal(values.Get(key), &record); err != nil {
+				return fmt.Errorf("failed to unmarshal run record: %w", err)
+			}
+			if !q.Match(record) {
+				continue
+			}
+			records = append(records, record)
+			if q.Limit > 0 && len(records) == q.Limit {
+				break
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	slices.Reverse(records)
+	return records, nil
+}
+
+// timeKey returns the key ordering a record by time, with its ID telling apart records of the same instant
+func timeKey(t time.Time, id string) []byte {
+	key := binary.BigEndian.AppendUint64(make([]byte, 0, 8+len(id)), uint64(t.UnixNano()))
+	return append(key, id...)
+}
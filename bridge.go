@@ -0,0 +1,185 @@
+package uvgo
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net"
+	"os"
+	"sync"
+)
+
+// Message represents a typed message a script sent to the runner over the bridge
+type Message struct {
+	Type string          `json:"type"`
+	Data json.RawMessage `json:"data"`
+}
+
+// Decode unmarshals the message data into v
+func (m Message) Decode(v any) error {
+	if err := json.Unmarshal(m.Data, v); err != nil {
+		return fmt.Errorf("failed to unmarshal %s message: %w", m.Type, err)
+	}
+	return nil
+}
+
+// WithBridge opens a unix socket for every run that scripts can send typed messages over with the bundled
+// uvgo module, such as uvgo.send("progress", {"done": 3}) and uvgo.result({...}). Messages are collected into
+// Result.Messages and passed to handler, when set, as they arrive. The bridge requires an executor running
+// on the local host.
+func WithBridge(handler func(Message)) Option {
+	return func(r *Runner) {
+		r.bridge = true
+		r.onMessage = handler
+	}
+}
+
+// BridgeOutput runs a script with the bridge enabled and decodes the data of the last result message it sent
+func BridgeOutput[T any](ctx context.Context, r *Runner, scriptPath string, args ...string) (*StructuredResult[T], error) {
+	if !r.bridge {
+		r = r.With(WithBridge(nil))
+	}
+
+	result, err := r.Run(ctx, scriptPath, args...)
+	if err != nil {
+		return &StructuredResult[T]{Result: result}, err
+	}
+
+	var output T
+	if err := result.bridgeResult(&output); err != nil {
+		return &StructuredResult[T]{Result: result}, err
+	}
+	return &StructuredResult[T]{Result: result, Data: output}, nil
+}
+
+// bridgeResult decodes the last result message into v
+func (r *Result) bridgeResult(v any) error {
+	for i := len(r.Messages) - 1; i >= 0; i-- {
+		if r.Messages[i].Type == "result" {
+			if err := r.Messages[i].Decode(v); err != nil {
+				return &PhaseError{Phase: PhaseOutput, Err: err}
+			}
+			return nil
+		}
+	}
+	return &PhaseError{Phase: PhaseOutput, Err: fmt.Errorf("script did not send a result message")}
+}
+
+const bridgeModule = `import json
+import os
+import socket
+import threading
+
+_sock = None
+_lock = threading.Lock()
+
+
+def send(type, data=None):
+    """Send a typed message to the Go runner."""
+    global _sock
+    line = json.dumps({"type": type, "data": data}).encode() + b"\n"
+    with _lock:
+        if _sock is None:
+            path = os.environ.get("UVGO_BRIDGE_SOCKET")
+            if not path:
+                raise RuntimeError("the uvgo bridge is not enabled")
+            _sock = socket.socket(socket.AF_UNIX, socket.SOCK_STREAM)
+            _sock.connect(path)
+        _sock.sendall(line)
+
+
+def result(data):
+    """Send the result of the script to the Go runner."""
+    send("result", data)
+`
+
+const bridgeSetup = `
+sys.path.append(os.environ["UVGO_BRIDGE_LIB"])
+`
+
+// withBridge listens on a unix socket for messages from the script and exposes the bundled uvgo module to it
+func (x *execution) withBridge(handler func(Message)) error {
+	lib, err := x.tempPath("bridge")
+	if err != nil {
+		return err
+	}
+	if err := os.Mkdir(lib, 0o755); err != nil {
+		return fmt.Errorf("failed to create bridge directory: %w", err)
+	}
+	if _, err := x.writeFile("bridge/uvgo.py", bridgeModule); err != nil {
+		return err
+	}
+
+	socketPath, err := x.tempPath("bridge.sock")
+	if err != nil {
+		return err
+	}
+	listener, err := net.Listen("unix", socketPath)
+	if err != nil {
+		return fmt.Errorf("failed to open bridge socket: %w", err)
+	}
+
+	var (
+		mu       sync.Mutex
+		wg       sync.WaitGroup
+		messages []Message
+		readErr  error
+	)
+
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		for {
+			conn, err := listener.Accept()
+			if err != nil {
+				return
+			}
+
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				defer conn.Close()
+
+				scanner := bufio.NewScanner(conn)
+				scanner.Buffer(make([]byte, 64*1024), 16*1024*1024)
+				for scanner.Scan() {
+					var m Message
+					if err := json.Unmarshal(scanner.Bytes(), &m); err != nil {
+						mu.Lock()
+						readErr = fmt.Errorf("failed to unmarshal bridge message: %w", err)
+						mu.Unlock()
+						continue
+					}
+
+					mu.Lock()
+					messages = append(messages, m)
+					mu.Unlock()
+
+					if handler != nil {
+						handler(m)
+					}
+				}
+			}()
+		}
+	}()
+
+	// stop waits for the script's connections to drain, which happens once the process has exited
+	stop := sync.OnceFunc(func() {
+		listener.Close()
+		wg.Wait()
+	})
+
+	x.env = append(x.env, "UVGO_BRIDGE_SOCKET="+socketPath, "UVGO_BRIDGE_LIB="+lib)
+	x.setup = append(x.setup, bridgeSetup)
+	x.release = append(x.release, stop)
+	x.collect = append(x.collect, func(result *Result) error {
+		stop()
+
+		mu.Lock()
+		defer mu.Unlock()
+		result.Messages = messages
+		return readErr
+	})
+	return nil
+}
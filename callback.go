@@ -0,0 +1,128 @@
+package uvgo
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"maps"
+	"os"
+)
+
+// callDirective prefixes the stderr lines carrying callback requests from a script
+const callDirective = "@uvgo:call"
+
+// Callback is a Go function scripts can call mid-run. params holds the JSON parameters sent by the script, and
+// the returned value is sent back as JSON.
+type Callback func(ctx context.Context, params json.RawMessage) (any, error)
+
+// WithCallback registers a Go function scripts can call by name while they run, such as to fetch a secret or
+// query a database owned by the Go process. Scripts use the bundled uvgo_callback module:
+//
+//	from uvgo_callback import call
+//	token = call("secret", {"name": "api"})
+//
+// call blocks until the function returns and raises uvgo_callback.CallbackError when it fails. Requests travel
+// over stderr and replies over stdin, so the script cannot read its own standard input.
+func WithCallback(method string, fn Callback) Option {
+	return func(r *Runner) {
+		callbacks := maps.Clone(r.callbacks)
+		if callbacks == nil {
+			callbacks = make(map[string]Callback)
+		}
+		callbacks[method] = fn
+		r.callbacks = callbacks
+	}
+}
+
+const callbackModule = `import json
+import sys
+import threading
+
+_lock = threading.Lock()
+_next_id = 0
+
+
+class CallbackError(Exception):
+    """An error returned by a Go callback."""
+
+
+def call(method, params=None):
+    """Call a Go callback registered on the runner and return its result."""
+    global _next_id
+    with _lock:
+        _next_id += 1
+        request = {"id": _next_id, "method": method, "params": params}
+        sys.stderr.write("@uvgo:call " + json.dumps(request) + "\n")
+        sys.stderr.flush()
+        line = sys.stdin.readline()
+    if not line:
+        raise CallbackError("the Go runner closed the callback channel")
+    reply = json.loads(line)
+    if reply.get("error"):
+        raise CallbackError(reply["error"])
+    return reply.get("result")
+`
+
+const callbackSetup = `
+sys.path.append(os.environ["UVGO_CALLBACK_LIB"])
+`
+
+// withCallbacks exposes the bundled uvgo_callback module to the script
+func (x *execution) withCallbacks() error {
+	lib, err := x.tempPath("callbacks")
+	if err != nil {
+		return err
+	}
+	if err := os.Mkdir(lib, 0o755); err != nil {
+		return fmt.Errorf("failed to create callback directory: %w", err)
+	}
+	if _, err := x.writeFile("callbacks/uvgo_callback.py", callbackModule); err != nil {
+		return err
+	}
+
+	x.env = append(x.env, "UVGO_CALLBACK_LIB="+lib)
+	x.setup = append(x.setup, callbackSetup)
+	return nil
+}
+
+// callRequest is a callback request sent by a script
+type callRequest struct {
+	ID     int64           `json:"id"`
+	Method string          `json:"method"`
+	Params json.RawMessage `json:"params"`
+}
+
+// callReply is the reply to a callback request
+type callReply struct {
+	ID     int64  `json:"id"`
+	Result any    `json:"result"`
+	Error  string `json:"error,omitempty"`
+}
+
+// call runs the callback a script requested and writes the reply to its stdin
+func (r *Runner) call(ctx context.Context, stdin io.Writer, payload string) {
+	var req callRequest
+	reply := callReply{}
+	if err := json.Unmarshal([]byte(payload), &req); err != nil {
+		reply.Error = fmt.Sprintf("invalid callback request: %v", err)
+	} else if fn, ok := r.callbacks[req.Method]; !ok {
+		reply.ID = req.ID
+		reply.Error = fmt.Sprintf("unknown callback method %q", req.Method)
+	} else {
+		reply.ID = req.ID
+		result, err := fn(ctx, req.Params)
+		if err != nil {
+			reply.Error = err.Error()
+		} else {
+			reply.Result = result
+		}
+	}
+
+	data, err := json.Marshal(reply)
+	if err != nil {
+		data, _ = json.Marshal(callReply{ID: reply.ID, Error: fmt.Sprintf("failed to marshal callback result: %v", err)})
+	}
+	// the script only fails to receive the reply when it has already exited
+	stdin.Write(append(data, '\n'))
+}
@@ -0,0 +1,138 @@
+package uvgo
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// channel is an extra pipe between the runner and a script
+type channel struct {
+	name string
+	// w receives what the script writes, for channels from the script
+	w io.Writer
+	// r is copied to the script, for channels to the script
+	r io.Reader
+}
+
+// WithChannel passes the script an extra pipe named for its role, such as "result", "progress" or "logs",
+// to write to alongside stdout and stderr. What the script writes is copied to w as it is written, or collected
+// into Result.Channels when w is nil. The script finds the file descriptor in the UVGO_FD_<NAME> environment
+// variable, with the name upper-cased, and ChannelSnippet opens it. Channels need an executor running uv as a
+// local subprocess.
+func WithChannel(name string, w io.Writer) Option {
+	return func(r *Runner) {
+		r.channels = append(r.channels, channel{name: name, w: w})
+	}
+}
+
+// WithInputChannel passes the script an extra pipe named for its role to read from, fed with the contents of
+// rd. It is exposed to the script like the channels of WithChannel.
+func WithInputChannel(name string, rd io.Reader) Option {
+	return func(r *Runner) {
+		r.channels = append(r.channels, channel{name: name, r: rd})
+	}
+}
+
+// ChannelSnippet is Python source defining a channel function that opens a pipe passed with WithChannel or
+// WithInputChannel
+const ChannelSnippet = `import os
+
+
+def channel(name, mode="w", **kwargs):
+    """Open the channel the Go runner passed under name, in "w" mode for output channels or "r" for input."""
+    fd = os.environ.get("UVGO_FD_" + name.upper())
+    if fd is None:
+        raise KeyError("no channel named %r" % name)
+    return os.fdopen(int(fd), mode, **kwargs)
+`
+
+// channelPipes connects the channels of a run to the pipes passed to its process
+type channelPipes struct {
+	// files are the ends of the pipes passed to the process
+	files []*os.File
+	// inputs and outputs are the ends of the pipes kept by the runner
+	inputs  []*os.File
+	outputs []*os.File
+	env     []string
+	copies  sync.WaitGroup
+	buffers map[string]*bytes.Buffer
+}
+
+// openChannels creates a pipe for every channel, numbering descriptors from 3 in order
+func openChannels(channels []channel) (*channelPipes, error) {
+	p := &channelPipes{buffers: make(map[string]*bytes.Buffer)}
+	var names []string
+	for i, ch := range channels {
+		pr, pw, err := os.Pipe()
+		if err != nil {
+			p.wait()
+			return nil, fmt.Errorf("failed to create channel %s: %w", ch.name, err)
+		}
+
+		fd := strconv.Itoa(3 + i)
+		p.env = append(p.env, "UVGO_FD_"+strings.ToUpper(ch.name)+"="+fd)
+		names = append(names, ch.name+"="+fd)
+
+		if ch.r != nil {
+			p.files = append(p.files, pr)
+			p.inputs = append(p.inputs, pw)
+			p.copies.Add(1)
+			go func() {
+				defer p.copies.Done()
+				io.Copy(pw, ch.r)
+				pw.Close()
+			}()
+			continue
+		}
+
+		w := ch.w
+		if w == nil {
+			buf := &bytes.Buffer{}
+			p.buffers[ch.name] = buf
+			w = buf
+		}
+		p.files = append(p.files, pw)
+		p.outputs = append(p.outputs, pr)
+		p.copies.Add(1)
+		go func() {
+			defer p.copies.Done()
+			io.Copy(w, pr)
+		}()
+	}
+	p.env = append(p.env, "UVGO_CHANNELS="+strings.Join(names, ","))
+	return p, nil
+}
+
+// started closes the runner's copies of the process ends, so output channels reach EOF when the process exits
+func (p *channelPipes) started() {
+	for _, f := range p.files {
+		f.Close()
+	}
+}
+
+// wait closes the pipes once the process has exited and returns the output collected without a writer
+func (p *channelPipes) wait() map[string][]byte {
+	p.started()
+	// input the process did not read to the end would otherwise block its copy forever
+	for _, f := range p.inputs {
+		f.Close()
+	}
+	p.copies.Wait()
+	for _, f := range p.outputs {
+		f.Close()
+	}
+
+	if len(p.buffers) == 0 {
+		return nil
+	}
+	out := make(map[string][]byte, len(p.buffers))
+	for name, buf := range p.buffers {
+		out[name] = buf.Bytes()
+	}
+	return out
+}
@@ -0,0 +1,87 @@
+// Command uvgo-server serves the uvgo REST execution API.
+//
+// The runner is configured from UVGO_* environment variables as described by uvgo.NewFromEnv. When
+// UVGO_SERVER_TOKEN is set, every request must carry it as a bearer token. Without a token the server only
+// listens on the loopback interface, refusing any other address.
+package main
+
+import (
+	"context"
+	"crypto/subtle"
+	"errors"
+	"flag"
+	"log"
+	"net"
+	"net/http"
+	"os"
+	"os/signal"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/joeychilson/uvgo"
+	"github.com/joeychilson/uvgo/server"
+)
+
+func main() {
+	addr := flag.String("addr", "127.0.0.1:8080", "address to listen on")
+	allowInline := flag.Bool("allow-inline", false, "accept runs of inline script content")
+	maxRuns := flag.Int("max-runs", 1000, "number of finished runs kept for retrieval")
+	flag.Parse()
+
+	runner, err := uvgo.NewFromEnv()
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	opts := server.Options{AllowInline: *allowInline, MaxRuns: *maxRuns}
+	if token := os.Getenv("UVGO_SERVER_TOKEN"); token != "" {
+		opts.Authenticate = bearerAuth(token)
+	} else if !isLoopback(*addr) {
+		log.Fatalf("refusing to listen on %s without UVGO_SERVER_TOKEN", *addr)
+	}
+
+	srv := &http.Server{
+		Addr:    *addr,
+		Handler: server.New(runner, uvgo.NewRegistry(), opts),
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	go func() {
+		<-ctx.Done()
+		shutdownCtx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+		defer cancel()
+		srv.Shutdown(shutdownCtx)
+	}()
+
+	log.Printf("uvgo-server listening on %s", *addr)
+	if err := srv.ListenAndServe(); !errors.Is(err, http.ErrServerClosed) {
+		log.Fatal(err)
+	}
+}
+
+// bearerAuth accepts requests carrying token in the Authorization header
+func bearerAuth(token string) func(r *http.Request) error {
+	return func(r *http.Request) error {
+		got, ok := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer ")
+		if !ok || subtle.ConstantTimeCompare([]byte(got), []byte(token)) != 1 {
+			return errors.New("invalid or missing bearer token")
+		}
+		return nil
+	}
+}
+
+// isLoopback reports whether addr only listens on the loopback interface
+func isLoopback(addr string) bool {
+	host, _, err := net.SplitHostPort(addr)
+	if err != nil {
+		return false
+	}
+	if host == "localhost" {
+		return true
+	}
+	ip := net.ParseIP(host)
+	return ip != nil && ip.IsLoopback()
+}
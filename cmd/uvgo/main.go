@@ -0,0 +1,63 @@
+// Command uvgo runs Python scripts through the uvgo package.
+//
+//	uvgo run [flags] <script.py | -> [args...]
+//	uvgo doctor
+//
+// The runner is configured from UVGO_* environment variables as described by uvgo.NewFromEnv, with flags
+// taking precedence.
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/signal"
+	"strings"
+	"syscall"
+)
+
+const usage = `usage:
+  uvgo run [flags] <script.py | -> [args...]   run a script, reading it from stdin when given "-"
+  uvgo doctor                                  check the uv environment
+
+Run "uvgo run -h" for the run flags.
+`
+
+func main() {
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	code := run(ctx, os.Args[1:])
+	stop()
+	os.Exit(code)
+}
+
+func run(ctx context.Context, args []string) int {
+	if len(args) == 0 {
+		fmt.Fprint(os.Stderr, usage)
+		return 2
+	}
+
+	switch args[0] {
+	case "run":
+		return runCommand(ctx, args[1:])
+	case "doctor":
+		return doctorCommand(ctx, args[1:])
+	case "help", "-h", "-help", "--help":
+		fmt.Fprint(os.Stdout, usage)
+		return 0
+	default:
+		fmt.Fprintf(os.Stderr, "uvgo: unknown command %q\n\n%s", args[0], usage)
+		return 2
+	}
+}
+
+// listFlag collects the values of a repeatable flag
+type listFlag []string
+
+func (l *listFlag) String() string {
+	return strings.Join(*l, ",")
+}
+
+func (l *listFlag) Set(value string) error {
+	*l = append(*l, value)
+	return nil
+}
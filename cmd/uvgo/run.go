@@ -0,0 +1,237 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"time"
+
+	"github.com/joeychilson/uvgo"
+)
+
+// runOutput is the result emitted by "uvgo run -json"
+type runOutput struct {
+	Stdout     string          `json:"stdout"`
+	Stderr     string          `json:"stderr"`
+	ExitCode   int             `json:"exit_code"`
+	DurationMS int64           `json:"duration_ms"`
+	Data       json.RawMessage `json:"data,omitempty"`
+	Artifacts  []string        `json:"artifacts,omitempty"`
+	Error      string          `json:"error,omitempty"`
+}
+
+func runCommand(ctx context.Context, args []string) int {
+	fs := flag.NewFlagSet("run", flag.ContinueOnError)
+	fs.Usage = func() {
+		fmt.Fprintln(fs.Output(), "usage: uvgo run [flags] <script.py | -> [args...]")
+		fs.PrintDefaults()
+	}
+
+	var (
+		python      = fs.String("python", "", "python version to run with")
+		timeout     = fs.Duration("timeout", 0, "execution timeout, such as 30s (0 keeps the configured timeout)")
+		workDir     = fs.String("workdir", "", "working directory of the script")
+		input       = fs.String("input", "", "JSON passed to the script in UVGO_INPUT, or @file to read it from a file")
+		jsonOut     = fs.Bool("json", false, "emit the result as a JSON object instead of the script output")
+		expectJSON  = fs.Bool("expect-json", false, "fail unless the script prints a single JSON value")
+		schemaPath  = fs.String("schema", "", "JSON Schema file the script output must satisfy, implies -expect-json")
+		offline     = fs.Bool("offline", false, "disable network access for package resolution")
+		isolated    = fs.Bool("isolated", false, "ignore the surrounding project and run in an isolated environment")
+		maxArtifact = fs.Int64("artifacts", 0, "collect files written to UVGO_OUTPUT_DIR up to this size in bytes")
+		deps        listFlag
+		env         listFlag
+	)
+	fs.Var(&deps, "with", "additional dependency, may be repeated")
+	fs.Var(&env, "env", "KEY=VALUE environment variable, may be repeated")
+
+	if err := fs.Parse(args); err != nil {
+		if errors.Is(err, flag.ErrHelp) {
+			return 0
+		}
+		return 2
+	}
+	if fs.NArg() == 0 {
+		fs.Usage()
+		return 2
+	}
+
+	var schema map[string]any
+	if *schemaPath != "" {
+		data, err := os.ReadFile(*schemaPath)
+		if err != nil {
+			return fail(fmt.Errorf("failed to read schema: %w", err))
+		}
+		if err := json.Unmarshal(data, &schema); err != nil {
+			return fail(fmt.Errorf("failed to parse schema: %w", err))
+		}
+		*expectJSON = true
+	}
+
+	var opts []uvgo.Option
+	if len(deps) > 0 {
+		opts = append(opts, uvgo.WithDependencies(deps...))
+	}
+	if len(env) > 0 {
+		opts = append(opts, uvgo.WithEnv(env...))
+	}
+	if *python != "" {
+		opts = append(opts, uvgo.WithPython(*python))
+	}
+	if *timeout > 0 {
+		opts = append(opts, uvgo.WithTimeout(*timeout))
+	}
+	if *workDir != "" {
+		opts = append(opts, uvgo.WithWorkDir(*workDir))
+	}
+	if *maxArtifact > 0 {
+		opts = append(opts, uvgo.WithArtifacts(*maxArtifact))
+	}
+	if *input != "" {
+		data, err := readInput(*input)
+		if err != nil {
+			return fail(err)
+		}
+		opts = append(opts, uvgo.WithInput(data))
+	}
+	// plain runs stream the script output as it is written, validated runs only print it once checked
+	if !*jsonOut && !*expectJSON {
+		opts = append(opts, uvgo.WithOutputSinks(os.Stdout, os.Stderr))
+	}
+
+	runner, err := uvgo.NewFromEnv(opts...)
+	if err != nil {
+		return fail(err)
+	}
+
+	// -offline and -isolated add to the configured flags rather than replacing them
+	var extraFlags []string
+	if *offline {
+		extraFlags = append(extraFlags, "--offline")
+	}
+	if *isolated {
+		extraFlags = append(extraFlags, "--isolated")
+	}
+	if len(extraFlags) > 0 {
+		runner = runner.With(uvgo.WithExtraFlags(append(runner.ExtraFlags(), extraFlags...)...))
+	}
+
+	script, scriptArgs := fs.Arg(0), fs.Args()[1:]
+	var result *uvgo.Result
+	if script == "-" {
+		content, readErr := io.ReadAll(os.Stdin)
+		if readErr != nil {
+			return fail(fmt.Errorf("failed to read script from stdin: %w", readErr))
+		}
+		result, err = runner.RunFromString(ctx, string(content), scriptArgs...)
+	} else {
+		result, err = runner.Run(ctx, script, scriptArgs...)
+	}
+
+	var data json.RawMessage
+	if err == nil && *expectJSON {
+		data, err = validateOutput(result.Stdout, schema)
+	}
+
+	code := exitCode(result, err)
+	if *jsonOut {
+		out := runOutput{Data: data}
+		if result != nil {
+			out.Stdout = result.Stdout
+			out.Stderr = result.Stderr
+			out.ExitCode = result.ExitCode
+			out.DurationMS = result.WallTime.Milliseconds()
+			for _, a := range result.Artifacts {
+				out.Artifacts = append(out.Artifacts, a.Name)
+			}
+		}
+		if err != nil {
+			out.Error = err.Error()
+		}
+		enc := json.NewEncoder(os.Stdout)
+		enc.SetIndent("", "  ")
+		enc.Encode(out)
+		return code
+	}
+
+	if *expectJSON && result != nil {
+		fmt.Fprint(os.Stdout, result.Stdout)
+		fmt.Fprint(os.Stderr, result.Stderr)
+	}
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "uvgo:", err)
+	}
+	return code
+}
+
+// readInput returns the JSON given to -input, reading it from a file when prefixed with @
+func readInput(input string) ([]byte, error) {
+	data := []byte(input)
+	if input[0] == '@' {
+		var err error
+		if data, err = os.ReadFile(input[1:]); err != nil {
+			return nil, fmt.Errorf("failed to read input: %w", err)
+		}
+	}
+	if !json.Valid(data) {
+		return nil, errors.New("input is not valid JSON")
+	}
+	return data, nil
+}
+
+// validateOutput checks that stdout holds a single JSON value satisfying schema, when given
+func validateOutput(stdout string, schema map[string]any) (json.RawMessage, error) {
+	var value any
+	if err := json.Unmarshal([]byte(stdout), &value); err != nil {
+		return nil, fmt.Errorf("script output is not valid JSON: %w", err)
+	}
+	if schema != nil {
+		if err := validateSchema(schema, value, "$"); err != nil {
+			return nil, fmt.Errorf("script output does not match the schema: %w", err)
+		}
+	}
+	return json.RawMessage(stdout), nil
+}
+
+// exitCode returns the script exit code when it failed, and 1 for any other failure
+func exitCode(result *uvgo.Result, err error) int {
+	if err == nil {
+		return 0
+	}
+	if result != nil && result.ExitCode > 0 {
+		return result.ExitCode
+	}
+	return 1
+}
+
+func fail(err error) int {
+	fmt.Fprintln(os.Stderr, "uvgo:", err)
+	return 1
+}
+
+func doctorCommand(ctx context.Context, args []string) int {
+	if len(args) > 0 {
+		fmt.Fprintln(os.Stderr, "usage: uvgo doctor")
+		return 2
+	}
+
+	runner, err := uvgo.NewFromEnv()
+	if err != nil {
+		return fail(err)
+	}
+	ctx, cancel := context.WithTimeout(ctx, time.Minute)
+	defer cancel()
+
+	report := runner.Doctor(ctx)
+	fmt.Printf("uv:     %s\npython: %s\ncache:  %s\n\n", report.UVVersion, report.Python, report.CacheDir)
+	for _, c := range report.Checks {
+		fmt.Printf("%-8s %s: %s\n", c.Status, c.Name, c.Detail)
+	}
+	if !report.OK() {
+		return 1
+	}
+	return 0
+}
@@ -0,0 +1,136 @@
+package main
+
+import (
+	"fmt"
+	"math"
+	"reflect"
+	"sort"
+)
+
+// validateSchema checks value against the subset of JSON Schema produced by tools.Schema: type, properties,
+// required, additionalProperties, items, enum and const. Unknown keywords are ignored.
+func validateSchema(schema map[string]any, value any, path string) error {
+	if t, ok := schema["type"]; ok {
+		if err := checkType(t, value, path); err != nil {
+			return err
+		}
+	}
+
+	if enum, ok := schema["enum"].([]any); ok {
+		found := false
+		for _, v := range enum {
+			if reflect.DeepEqual(v, value) {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return fmt.Errorf("%s: value is not one of %v", path, enum)
+		}
+	}
+	if c, ok := schema["const"]; ok && !reflect.DeepEqual(c, value) {
+		return fmt.Errorf("%s: value must be %v", path, c)
+	}
+
+	switch v := value.(type) {
+	case map[string]any:
+		properties, _ := schema["properties"].(map[string]any)
+		if required, ok := schema["required"].([]any); ok {
+			for _, name := range required {
+				if name, ok := name.(string); ok {
+					if _, ok := v[name]; !ok {
+						return fmt.Errorf("%s: missing required property %q", path, name)
+					}
+				}
+			}
+		}
+
+		names := make([]string, 0, len(v))
+		for name := range v {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+
+		for _, name := range names {
+			sub, ok := properties[name].(map[string]any)
+			if !ok {
+				if additional, ok := schema["additionalProperties"]; ok {
+					switch additional := additional.(type) {
+					case bool:
+						if !additional {
+							return fmt.Errorf("%s: unexpected property %q", path, name)
+						}
+					case map[string]any:
+						sub = additional
+					}
+				}
+			}
+			if sub != nil {
+				if err := validateSchema(sub, v[name], path+"."+name); err != nil {
+					return err
+				}
+			}
+		}
+	case []any:
+		if items, ok := schema["items"].(map[string]any); ok {
+			for i, item := range v {
+				if err := validateSchema(items, item, fmt.Sprintf("%s[%d]", path, i)); err != nil {
+					return err
+				}
+			}
+		}
+	}
+	return nil
+}
+
+// checkType reports whether value has the schema type t, which may be a single type or a list of types
+func checkType(t any, value any, path string) error {
+	var types []string
+	switch t := t.(type) {
+	case string:
+		types = []string{t}
+	case []any:
+		for _, name := range t {
+			if name, ok := name.(string); ok {
+				types = append(types, name)
+			}
+		}
+	}
+
+	for _, name := range types {
+		if hasType(name, value) {
+			return nil
+		}
+	}
+	return fmt.Errorf("%s: expected %v, got %s", path, t, jsonType(value))
+}
+
+func hasType(name string, value any) bool {
+	switch name {
+	case "integer":
+		n, ok := value.(float64)
+		return ok && n == math.Trunc(n)
+	case "number":
+		_, ok := value.(float64)
+		return ok
+	default:
+		return name == jsonType(value)
+	}
+}
+
+func jsonType(value any) string {
+	switch value.(type) {
+	case nil:
+		return "null"
+	case bool:
+		return "boolean"
+	case float64:
+		return "number"
+	case string:
+		return "string"
+	case []any:
+		return "array"
+	default:
+		return "object"
+	}
+}
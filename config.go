@@ -0,0 +1,147 @@
+package uvgo
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/BurntSushi/toml"
+	"gopkg.in/yaml.v3"
+)
+
+// Config holds runner settings loaded from a configuration file
+type Config struct {
+	Python         string            `toml:"python" yaml:"python" json:"python"`
+	Dependencies   []string          `toml:"dependencies" yaml:"dependencies" json:"dependencies"`
+	IndexURL       string            `toml:"index_url" yaml:"index_url" json:"index_url"`
+	ExtraIndexURLs []string          `toml:"extra_index_urls" yaml:"extra_index_urls" json:"extra_index_urls"`
+	ExtraFlags     []string          `toml:"extra_flags" yaml:"extra_flags" json:"extra_flags"`
+	WorkDir        string            `toml:"work_dir" yaml:"work_dir" json:"work_dir"`
+	Env            map[string]string `toml:"env" yaml:"env" json:"env"`
+	Limits         LimitsConfig      `toml:"limits" yaml:"limits" json:"limits"`
+}
+
+// LimitsConfig holds the resource limits of a Config. An unset Timeout keeps the 30 second default, while
+// an explicit zero disables it. MaxUVProcesses is applied through SetUVProcessLimit and so bounds uv
+// processes across every runner in the program.
+type LimitsConfig struct {
+	Timeout             *Duration `toml:"timeout" yaml:"timeout" json:"timeout"`
+	MaxArtifactSize     int64     `toml:"max_artifact_size" yaml:"max_artifact_size" json:"max_artifact_size"`
+	MaxRunning          int       `toml:"max_running" yaml:"max_running" json:"max_running"`
+	MaxPending          int       `toml:"max_pending" yaml:"max_pending" json:"max_pending"`
+	RatePerSecond       float64   `toml:"rate_per_second" yaml:"rate_per_second" json:"rate_per_second"`
+	RateBurst           int       `toml:"rate_burst" yaml:"rate_burst" json:"rate_burst"`
+	MaxUVProcesses      int       `toml:"max_uv_processes" yaml:"max_uv_processes" json:"max_uv_processes"`
+	ConcurrentDownloads int       `toml:"concurrent_downloads" yaml:"concurrent_downloads" json:"concurrent_downloads"`
+	ConcurrentBuilds    int       `toml:"concurrent_builds" yaml:"concurrent_builds" json:"concurrent_builds"`
+	ConcurrentInstalls  int       `toml:"concurrent_installs" yaml:"concurrent_installs" json:"concurrent_installs"`
+}
+
+// Duration is a time.Duration read from strings such as "30s" or "5m"
+type Duration time.Duration
+
+// UnmarshalText implements encoding.TextUnmarshaler
+func (d *Duration) UnmarshalText(text []byte) error {
+	v, err := time.ParseDuration(string(text))
+	if err != nil {
+		return err
+	}
+	*d = Duration(v)
+	return nil
+}
+
+// MarshalText implements encoding.TextMarshaler
+func (d Duration) MarshalText() ([]byte, error) {
+	return []byte(time.Duration(d).String()), nil
+}
+
+// LoadConfig builds runner options from a TOML, YAML or JSON file, chosen by its extension
+func LoadConfig(path string) ([]Option, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read config file: %w", err)
+	}
+
+	var cfg Config
+	switch ext := strings.ToLower(filepath.Ext(path)); ext {
+	case ".toml":
+		err = toml.Unmarshal(data, &cfg)
+	case ".yaml", ".yml":
+		err = yaml.Unmarshal(data, &cfg)
+	case ".json":
+		err = json.Unmarshal(data, &cfg)
+	default:
+		return nil, fmt.Errorf("unsupported config file format: %q", ext)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse config file: %w", err)
+	}
+	return cfg.Options(), nil
+}
+
+// Options converts the configuration into runner options, leaving unset fields at their defaults
+func (c *Config) Options() []Option {
+	var opts []Option
+
+	if c.Python != "" {
+		opts = append(opts, WithPython(c.Python))
+	}
+	if len(c.Dependencies) > 0 {
+		opts = append(opts, WithDependencies(c.Dependencies...))
+	}
+	if c.IndexURL != "" {
+		opts = append(opts, WithIndexURL(c.IndexURL))
+	}
+	if len(c.ExtraIndexURLs) > 0 {
+		opts = append(opts, WithExtraIndexURLs(c.ExtraIndexURLs...))
+	}
+	if len(c.ExtraFlags) > 0 {
+		opts = append(opts, WithExtraFlags(c.ExtraFlags...))
+	}
+	if c.WorkDir != "" {
+		opts = append(opts, WithWorkDir(c.WorkDir))
+	}
+	if len(c.Env) > 0 {
+		keys := make([]string, 0, len(c.Env))
+		for k := range c.Env {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+
+		env := make([]string, 0, len(keys))
+		for _, k := range keys {
+			env = append(env, k+"="+c.Env[k])
+		}
+		opts = append(opts, WithEnv(env...))
+	}
+	if c.Limits.Timeout != nil {
+		opts = append(opts, WithTimeout(time.Duration(*c.Limits.Timeout)))
+	}
+	if c.Limits.MaxArtifactSize > 0 {
+		opts = append(opts, WithArtifacts(c.Limits.MaxArtifactSize))
+	}
+	if c.Limits.MaxRunning > 0 {
+		opts = append(opts, WithConcurrencyLimit(c.Limits.MaxRunning, c.Limits.MaxPending))
+	}
+	if c.Limits.RatePerSecond > 0 {
+		opts = append(opts, WithRateLimit(c.Limits.RatePerSecond, c.Limits.RateBurst))
+	}
+	if n := c.Limits.MaxUVProcesses; n > 0 {
+		// the uv process limit is global, so it is set when the option is applied rather than per runner
+		opts = append(opts, func(*Runner) { SetUVProcessLimit(n) })
+	}
+	if c.Limits.ConcurrentDownloads > 0 {
+		opts = append(opts, WithConcurrentDownloads(c.Limits.ConcurrentDownloads))
+	}
+	if c.Limits.ConcurrentBuilds > 0 {
+		opts = append(opts, WithConcurrentBuilds(c.Limits.ConcurrentBuilds))
+	}
+	if c.Limits.ConcurrentInstalls > 0 {
+		opts = append(opts, WithConcurrentInstalls(c.Limits.ConcurrentInstalls))
+	}
+	return opts
+}
@@ -0,0 +1,128 @@
+package uvgo
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"sort"
+)
+
+// Coverage represents the line coverage collected by coverage.py
+type Coverage struct {
+	CoveredLines   int
+	Statements     int
+	MissingLines   int
+	ExcludedLines  int
+	PercentCovered float64
+	Files          []FileCoverage
+}
+
+// FileCoverage represents the line coverage of a single source file
+type FileCoverage struct {
+	Path           string
+	ExecutedLines  []int
+	MissingLines   []int
+	ExcludedLines  []int
+	CoveredLines   int
+	Statements     int
+	PercentCovered float64
+}
+
+type coverageSummary struct {
+	CoveredLines   int     `json:"covered_lines"`
+	Statements     int     `json:"num_statements"`
+	MissingLines   int     `json:"missing_lines"`
+	ExcludedLines  int     `json:"excluded_lines"`
+	PercentCovered float64 `json:"percent_covered"`
+}
+
+type coverageReport struct {
+	Files map[string]struct {
+		ExecutedLines []int           `json:"executed_lines"`
+		MissingLines  []int           `json:"missing_lines"`
+		ExcludedLines []int           `json:"excluded_lines"`
+		Summary       coverageSummary `json:"summary"`
+	} `json:"files"`
+	Totals coverageSummary `json:"totals"`
+}
+
+const coverageSetup = `
+import coverage as _uvgo_coverage
+_uvgo_cov = _uvgo_coverage.Coverage(data_file=os.environ["UVGO_COVERAGE_DATA"], omit=[__file__])
+_uvgo_cov.start()
+`
+
+const coverageTeardown = `
+_uvgo_cov.stop()
+_uvgo_cov.save()
+try:
+    _uvgo_cov.json_report(outfile=os.environ["UVGO_COVERAGE_REPORT"])
+except _uvgo_coverage.CoverageException:
+    pass
+`
+
+// withCoverage runs the script under coverage.py and attaches the parsed report to the result
+func (x *execution) withCoverage() error {
+	dataPath, err := x.tempPath(".coverage")
+	if err != nil {
+		return err
+	}
+	reportPath, err := x.tempPath("coverage.json")
+	if err != nil {
+		return err
+	}
+
+	x.with = append(x.with, "coverage")
+	x.env = append(x.env, "UVGO_COVERAGE_DATA="+dataPath, "UVGO_COVERAGE_REPORT="+reportPath)
+	x.setup = append(x.setup, coverageSetup)
+	x.teardown = append(x.teardown, coverageTeardown)
+	x.collect = append(x.collect, func(result *Result) error {
+		coverage, err := readCoverage(reportPath)
+		if err != nil {
+			return err
+		}
+		result.Coverage = coverage
+		return nil
+	})
+	return nil
+}
+
+func readCoverage(path string) (*Coverage, error) {
+	data, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return &Coverage{}, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read coverage report: %w", err)
+	}
+
+	var report coverageReport
+	if err := json.Unmarshal(data, &report); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal coverage report: %w", err)
+	}
+
+	coverage := &Coverage{
+		CoveredLines:   report.Totals.CoveredLines,
+		Statements:     report.Totals.Statements,
+		MissingLines:   report.Totals.MissingLines,
+		ExcludedLines:  report.Totals.ExcludedLines,
+		PercentCovered: report.Totals.PercentCovered,
+	}
+
+	for path, file := range report.Files {
+		coverage.Files = append(coverage.Files, FileCoverage{
+			Path:           path,
+			ExecutedLines:  file.ExecutedLines,
+			MissingLines:   file.MissingLines,
+			ExcludedLines:  file.ExcludedLines,
+			CoveredLines:   file.Summary.CoveredLines,
+			Statements:     file.Summary.Statements,
+			PercentCovered: file.Summary.PercentCovered,
+		})
+	}
+
+	sort.Slice(coverage.Files, func(i, j int) bool {
+		return coverage.Files[i].Path < coverage.Files[j].Path
+	})
+	return coverage, nil
+}
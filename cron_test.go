@@ -0,0 +1,84 @@
+package uvgo
+
+import (
+	"testing"
+	"time"
+)
+
+func TestCronScheduleNext(t *testing.T) {
+	date := func(year int, month time.Month, day, hour, minute, sec int) time.Time {
+		return time.Date(year, month, day, hour, minute, sec, 0, time.UTC)
+	}
+
+	tests := []struct {
+		name string
+		spec string
+		from time.Time
+		want time.Time
+	}{
+		{"every minute truncates seconds", "* * * * *", date(2024, 1, 1, 10, 0, 30), date(2024, 1, 1, 10, 1, 0)},
+		{"step", "*/15 * * * *", date(2024, 1, 1, 10, 7, 0), date(2024, 1, 1, 10, 15, 0)},
+		{"stepped range", "5-10/2 * * * *", date(2024, 1, 1, 10, 6, 0), date(2024, 1, 1, 10, 7, 0)},
+		{"list", "0 8,20 * * *", date(2024, 1, 1, 9, 0, 0), date(2024, 1, 1, 20, 0, 0)},
+		{"hourly descriptor", "@hourly", date(2024, 1, 1, 10, 0, 0), date(2024, 1, 1, 11, 0, 0)},
+		{"weekly descriptor", "@weekly", date(2024, 1, 1, 0, 0, 0), date(2024, 1, 7, 0, 0, 0)},
+		{"day of week or day of month", "0 0 13 * 5", date(2024, 1, 1, 0, 0, 0), date(2024, 1, 5, 0, 0, 0)},
+		{"day of month or day of week", "0 0 13 * 5", date(2024, 1, 12, 0, 0, 0), date(2024, 1, 13, 0, 0, 0)},
+		{"restricted day of month only", "0 0 13 * *", date(2024, 1, 1, 0, 0, 0), date(2024, 1, 13, 0, 0, 0)},
+		{"restricted day of week only", "0 0 * * 5", date(2024, 1, 6, 0, 0, 0), date(2024, 1, 12, 0, 0, 0)},
+		{"seven is sunday", "0 9 * * 7", date(2024, 1, 1, 0, 0, 0), date(2024, 1, 7, 9, 0, 0)},
+		{"named sunday", "0 9 * * sun", date(2024, 1, 1, 0, 0, 0), date(2024, 1, 7, 9, 0, 0)},
+		{"range ending in seven", "0 9 * * 6-7", date(2024, 1, 1, 0, 0, 0), date(2024, 1, 6, 9, 0, 0)},
+		{"month rollover", "0 0 1 * *", date(2024, 1, 15, 12, 0, 0), date(2024, 2, 1, 0, 0, 0)},
+		{"year rollover", "0 0 1 * *", date(2024, 12, 15, 0, 0, 0), date(2025, 1, 1, 0, 0, 0)},
+		{"skips short months", "30 23 31 * *", date(2024, 1, 31, 23, 30, 0), date(2024, 3, 31, 23, 30, 0)},
+		{"leap day", "0 0 29 2 *", date(2024, 3, 1, 0, 0, 0), date(2028, 2, 29, 0, 0, 0)},
+		{"named months and days", "0 12 * jan-mar mon-fri", date(2024, 3, 29, 12, 0, 0), date(2025, 1, 1, 12, 0, 0)},
+		{"impossible date", "0 0 30 2 *", date(2024, 1, 1, 0, 0, 0), time.Time{}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s, err := ParseSchedule(tt.spec)
+			if err != nil {
+				t.Fatalf("ParseSchedule(%q): %v", tt.spec, err)
+			}
+			if got := s.Next(tt.from); !got.Equal(tt.want) {
+				t.Errorf("Next(%v) = %v, want %v", tt.from, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseScheduleEvery(t *testing.T) {
+	s, err := ParseSchedule("@every 90s")
+	if err != nil {
+		t.Fatal(err)
+	}
+	from := time.Date(2024, 1, 1, 10, 0, 0, 0, time.UTC)
+	if got, want := s.Next(from), from.Add(90*time.Second); !got.Equal(want) {
+		t.Errorf("Next(%v) = %v, want %v", from, got, want)
+	}
+}
+
+func TestParseScheduleInvalid(t *testing.T) {
+	for _, spec := range []string{
+		"",
+		"* * * *",
+		"* * * * * *",
+		"60 * * * *",
+		"* 24 * * *",
+		"* * 0 * *",
+		"* * * 13 *",
+		"* * * * 8",
+		"*/0 * * * *",
+		"5-1 * * * *",
+		"* * * foo *",
+		"@every x",
+		"@every -1s",
+	} {
+		if _, err := ParseSchedule(spec); err == nil {
+			t.Errorf("ParseSchedule(%q) succeeded, want an error", spec)
+		}
+	}
+}
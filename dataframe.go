@@ -0,0 +1,392 @@
+package uvgo
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"iter"
+	"strconv"
+	"time"
+)
+
+// frameChannel is the channel a script returns its data frame on
+const frameChannel = "frame"
+
+// ColumnKind is how the values of a data frame column are decoded in Go
+type ColumnKind string
+
+const (
+	// ColumnInt columns hold int64 values
+	ColumnInt ColumnKind = "int"
+	// ColumnFloat columns hold float64 values, with NaN and infinities returned as nil
+	ColumnFloat ColumnKind = "float"
+	// ColumnBool columns hold bool values
+	ColumnBool ColumnKind = "bool"
+	// ColumnString columns hold string values
+	ColumnString ColumnKind = "string"
+	// ColumnDatetime columns hold time.Time values
+	ColumnDatetime ColumnKind = "datetime"
+	// ColumnOther columns hold values decoded from JSON like json.Unmarshal into an any
+	ColumnOther ColumnKind = "other"
+)
+
+// Column describes a column of a data frame
+type Column struct {
+	Name string `json:"name"`
+	// DType is the name of the column's pandas or polars data type
+	DType string     `json:"dtype"`
+	Kind  ColumnKind `json:"kind"`
+}
+
+// DataFrame is a table returned by a script, with one value per column in every row and nil for missing values
+type DataFrame struct {
+	Columns []Column
+	Rows    [][]any
+}
+
+// Index returns the position of the column with the given name, or -1 if there is none
+func (f *DataFrame) Index(name string) int {
+	for i, column := range f.Columns {
+		if column.Name == name {
+			return i
+		}
+	}
+	return -1
+}
+
+// DataFrameSnippet is Python source defining a return_frame function that sends a pandas or polars DataFrame
+// to the Go runner over the channel DataFrameOutput and DataFrameRows pass the script. Rows are sent as JSON
+// lines in batches rather than as one document, and the index of a pandas DataFrame is dropped, so call
+// reset_index first to keep it.
+const DataFrameSnippet = `import json
+import os
+
+
+def _uvgo_frame_kind(dtype, series, polars):
+    if polars:
+        import polars as pl
+
+        if dtype == pl.Boolean:
+            return "bool"
+        if dtype.is_integer():
+            return "int"
+        if dtype.is_float():
+            return "float"
+        if dtype in (pl.Utf8, pl.Categorical):
+            return "string"
+        if dtype in (pl.Date, pl.Datetime):
+            return "datetime"
+        return "other"
+
+    from pandas.api import types
+
+    if types.is_bool_dtype(dtype):
+        return "bool"
+    if types.is_integer_dtype(dtype):
+        return "int"
+    if types.is_float_dtype(dtype):
+        return "float"
+    if types.is_datetime64_any_dtype(dtype):
+        return "datetime"
+    if types.infer_dtype(series, skipna=True) in ("string", "empty"):
+        return "string"
+    return "other"
+
+
+def _uvgo_frame_default(value):
+    if hasattr(value, "isoformat"):
+        return value.isoformat()
+    return str(value)
+
+
+def return_frame(df, batch_size=10000):
+    """Return a pandas or polars DataFrame to the Go runner, once per script."""
+    fd = os.environ.get("UVGO_FD_FRAME")
+    if fd is None:
+        raise RuntimeError("no frame channel, run the script with DataFrameOutput or DataFrameRows")
+
+    polars = type(df).__module__.split(".")[0] == "polars"
+    columns = []
+    for i, (name, dtype) in enumerate(zip(df.columns, df.dtypes)):
+        series = df.to_series(i) if polars else df.iloc[:, i]
+        columns.append({"name": str(name), "dtype": str(dtype), "kind": _uvgo_frame_kind(dtype, series, polars)})
+
+    with os.fdopen(int(fd), "w") as f:
+        f.write(json.dumps({"columns": columns}) + "\n")
+        for start in range(0, len(df), batch_size):
+            if polars:
+                import polars as pl
+
+                batch = df.slice(start, batch_size)
+                floats = [name for name, dtype in zip(batch.columns, batch.dtypes) if dtype.is_float()]
+                if floats:
+                    batch = batch.with_columns([pl.when(pl.col(name).is_finite()).then(pl.col(name)).alias(name) for name in floats])
+                f.write(json.dumps(batch.rows(), default=_uvgo_frame_default) + "\n")
+            else:
+                batch = df.iloc[start : start + batch_size]
+                f.write(batch.to_json(orient="values", date_format="iso", date_unit="us", default_handler=str) + "\n")
+`
+
+// DataFrameOutput runs a script that returns a pandas or polars DataFrame with the return_frame function of
+// DataFrameSnippet and decodes it into a DataFrame while it is being sent. The frame is sent over a channel,
+// which needs an executor running uv as a local subprocess, leaving stdout free.
+func DataFrameOutput(ctx context.Context, r *Runner, scriptPath string, args ...string) (*StructuredResult[DataFrame], error) {
+	pr, pw := io.Pipe()
+	var frame DataFrame
+	decoded := make(chan error, 1)
+	go func() {
+		decoded <- decodeFrame(pr, &frame)
+	}()
+
+	result, err := r.With(WithChannel(frameChannel, pw)).Run(ctx, scriptPath, args...)
+	pw.Close()
+	decodeErr := <-decoded
+	if err != nil {
+		return &StructuredResult[DataFrame]{Result: result}, err
+	}
+	if decodeErr != nil {
+		return &StructuredResult[DataFrame]{Result: result}, &PhaseError{
+			Phase: PhaseOutput,
+			Err:   fmt.Errorf("failed to decode data frame: %w", decodeErr),
+		}
+	}
+	return &StructuredResult[DataFrame]{Result: result, Data: frame}, nil
+}
+
+// DataFrameRows runs a script that returns a DataFrame like DataFrameOutput and yields each row as soon as its
+// batch arrives, decoded into the specified type as if the row were a JSON object keyed by column name, so
+// struct fields are matched to columns by their json tags. Breaking out of the loop terminates the script, and
+// a run failure or a row that does not decode is yielded as the final error.
+func DataFrameRows[T any](ctx context.Context, r *Runner, scriptPath string, args ...string) iter.Seq2[T, error] {
+	return func(yield func(T, error) bool) {
+		ctx, cancel := context.WithCancel(ctx)
+		defer cancel()
+
+		pr, pw := io.Pipe()
+		done := make(chan error, 1)
+		go func() {
+			_, err := r.With(WithChannel(frameChannel, pw)).Run(ctx, scriptPath, args...)
+			pw.Close()
+			done <- err
+		}()
+
+		// stop terminates the script and unblocks its output so the run can finish
+		stop := func() {
+			cancel()
+			pr.Close()
+			<-done
+		}
+		fail := func(err error) {
+			stop()
+			var zero T
+			yield(zero, &PhaseError{Phase: PhaseOutput, Err: fmt.Errorf("failed to decode data frame: %w", err)})
+		}
+
+		fr, err := newFrameReader(pr)
+		if errors.Is(err, io.EOF) {
+			// the run failed before the script returned a frame, or it never returned one
+			if err := <-done; err != nil {
+				var zero T
+				yield(zero, err)
+				return
+			}
+			var zero T
+			yield(zero, &PhaseError{Phase: PhaseOutput, Err: errors.New("script did not return a data frame")})
+			return
+		}
+		if err != nil {
+			fail(err)
+			return
+		}
+
+		keys := make([][]byte, len(fr.columns))
+		for i, column := range fr.columns {
+			keys[i], _ = json.Marshal(column.Name)
+		}
+
+		var object bytes.Buffer
+		for {
+			batch, err := fr.next()
+			if errors.Is(err, io.EOF) {
+				break
+			}
+			if err != nil {
+				fail(err)
+				return
+			}
+
+			for _, row := range batch {
+				object.Reset()
+				object.WriteByte('{')
+				for i, value := range row {
+					if i > 0 {
+						object.WriteByte(',')
+					}
+					object.Write(keys[i])
+					object.WriteByte(':')
+					object.Write(value)
+				}
+				object.WriteByte('}')
+
+				var item T
+				if err := json.Unmarshal(object.Bytes(), &item); err != nil {
+					fail(fmt.Errorf("failed to unmarshal row: %w", err))
+					return
+				}
+				if !yield(item, nil) {
+					stop()
+					return
+				}
+			}
+		}
+
+		if err := <-done; err != nil {
+			var zero T
+			yield(zero, err)
+		}
+	}
+}
+
+// decodeFrame decodes a data frame sent by return_frame from r into frame. It always consumes r to the end so
+// the writer never blocks.
+func decodeFrame(r io.Reader, frame *DataFrame) error {
+	defer io.Copy(io.Discard, r)
+
+	fr, err := newFrameReader(r)
+	if errors.Is(err, io.EOF) {
+		return errors.New("script did not return a data frame")
+	}
+	if err != nil {
+		return err
+	}
+	frame.Columns = fr.columns
+
+	for {
+		batch, err := fr.next()
+		if errors.Is(err, io.EOF) {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		for _, raw := range batch {
+			row := make([]any, len(raw))
+			for i, value := range raw {
+				if row[i], err = decodeFrameValue(fr.columns[i], value); err != nil {
+					return err
+				}
+			}
+			frame.Rows = append(frame.Rows, row)
+		}
+	}
+}
+
+// frameReader reads the header and row batches of a data frame sent by return_frame
+type frameReader struct {
+	scanner *bufio.Scanner
+	columns []Column
+	line    int
+}
+
+// newFrameReader reads the header line of a data frame, returning io.EOF if nothing was sent
+func newFrameReader(r io.Reader) (*frameReader, error) {
+	fr := &frameReader{scanner: bufio.NewScanner(r)}
+	fr.scanner.Buffer(make([]byte, 64*1024), 1024*1024*1024)
+
+	data, err := fr.scan()
+	if err != nil {
+		return nil, err
+	}
+	var header struct {
+		Columns []Column `json:"columns"`
+	}
+	if err := json.Unmarshal(data, &header); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal columns: %w", err)
+	}
+	fr.columns = header.Columns
+	return fr, nil
+}
+
+// next returns the next batch of rows, or io.EOF once every batch has been read
+func (fr *frameReader) next() ([][]json.RawMessage, error) {
+	data, err := fr.scan()
+	if err != nil {
+		return nil, err
+	}
+	var batch [][]json.RawMessage
+	if err := json.Unmarshal(data, &batch); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal rows on line %d: %w", fr.line, err)
+	}
+	for _, row := range batch {
+		if len(row) != len(fr.columns) {
+			return nil, fmt.Errorf("row on line %d has %d values for %d columns", fr.line, len(row), len(fr.columns))
+		}
+	}
+	return batch, nil
+}
+
+// scan returns the next non-empty line
+func (fr *frameReader) scan() ([]byte, error) {
+	for fr.scanner.Scan() {
+		fr.line++
+		if data := bytes.TrimSpace(fr.scanner.Bytes()); len(data) > 0 {
+			return data, nil
+		}
+	}
+	if err := fr.scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read data frame: %w", err)
+	}
+	return nil, io.EOF
+}
+
+// frameTimeLayouts are the layouts pandas and polars format dates and datetimes with
+var frameTimeLayouts = []string{time.RFC3339Nano, "2006-01-02T15:04:05.999999999", "2006-01-02"}
+
+// decodeFrameValue decodes a value of the given column into its Go type
+func decodeFrameValue(column Column, raw json.RawMessage) (any, error) {
+	if string(raw) == "null" {
+		return nil, nil
+	}
+
+	switch column.Kind {
+	case ColumnInt:
+		if n, err := strconv.ParseInt(string(raw), 10, 64); err == nil {
+			return n, nil
+		}
+	case ColumnFloat:
+		var f float64
+		if err := json.Unmarshal(raw, &f); err == nil {
+			return f, nil
+		}
+	case ColumnBool:
+		var b bool
+		if err := json.Unmarshal(raw, &b); err == nil {
+			return b, nil
+		}
+	case ColumnString:
+		var s string
+		if err := json.Unmarshal(raw, &s); err == nil {
+			return s, nil
+		}
+	case ColumnDatetime:
+		var s string
+		if err := json.Unmarshal(raw, &s); err == nil {
+			for _, layout := range frameTimeLayouts {
+				if t, err := time.Parse(layout, s); err == nil {
+					return t, nil
+				}
+			}
+		}
+	}
+
+	// values that do not match their column's kind, such as integers too large for an int64, are kept as JSON
+	var value any
+	if err := json.Unmarshal(raw, &value); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal value of column %s: %w", column.Name, err)
+	}
+	return value, nil
+}
@@ -0,0 +1,229 @@
+package uvgo
+
+import (
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"maps"
+	"mime"
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+// contentTypeDirective prefixes the stderr line a script uses to declare the content type of its output
+const contentTypeDirective = "@uvgo:content-type"
+
+// Decoder parses the output of a script into v, which is a non-nil pointer
+type Decoder func(data []byte, v any) error
+
+// defaultDecoders are the decoders available on every runner
+var defaultDecoders = map[string]Decoder{
+	"application/json": decodeJSON,
+	"text/csv":         decodeCSV,
+	"text/plain":       decodeText,
+}
+
+// WithDecoder registers the decoder StructuredOutput uses for script output of a content type, such as
+// "application/msgpack", replacing any previous decoder for it. JSON, CSV and plain text decoders are built in.
+func WithDecoder(contentType string, decoder Decoder) Option {
+	return func(r *Runner) {
+		decoders := maps.Clone(r.decoders)
+		if decoders == nil {
+			decoders = make(map[string]Decoder)
+		}
+		decoders[normalizeContentType(contentType)] = decoder
+		r.decoders = decoders
+	}
+}
+
+// WithContentType sets the content type StructuredOutput decodes script output as when the script does not
+// declare one itself by writing a line such as "@uvgo:content-type text/csv" to stderr. It defaults to
+// "application/json".
+func WithContentType(contentType string) Option {
+	return func(r *Runner) { r.contentType = normalizeContentType(contentType) }
+}
+
+// withContentTypeHint makes runs record the content type declared by the script
+func withContentTypeHint() Option {
+	return func(r *Runner) { r.contentTypeHint = true }
+}
+
+// normalizeContentType lowercases a media type and drops its parameters
+func normalizeContentType(contentType string) string {
+	if mediaType, _, err := mime.ParseMediaType(contentType); err == nil {
+		return mediaType
+	}
+	return strings.ToLower(strings.TrimSpace(contentType))
+}
+
+// outputContentType returns the content type of a run's output
+func (r *Runner) outputContentType(result *Result) string {
+	switch {
+	case result.ContentType != "":
+		return result.ContentType
+	case r.contentType != "":
+		return r.contentType
+	default:
+		return "application/json"
+	}
+}
+
+// decodeOutput parses the output of a run into v with the decoder registered for its content type
+func (r *Runner) decodeOutput(result *Result, v any) error {
+	contentType := r.outputContentType(result)
+	decoder, ok := r.decoders[contentType]
+	if !ok {
+		decoder, ok = defaultDecoders[contentType]
+	}
+	if !ok {
+		return &PhaseError{Phase: PhaseOutput, Err: fmt.Errorf("no decoder registered for content type %q", contentType)}
+	}
+	if err := decoder([]byte(result.Stdout), v); err != nil {
+		return &PhaseError{Phase: PhaseOutput, Err: fmt.Errorf("failed to unmarshal script output: %w", err)}
+	}
+	return nil
+}
+
+func decodeJSON(data []byte, v any) error {
+	return json.Unmarshal(data, v)
+}
+
+// decodeText stores the output in a string or byte slice, without a trailing newline
+func decodeText(data []byte, v any) error {
+	data = bytes.TrimSuffix(bytes.TrimSuffix(data, []byte("\n")), []byte("\r"))
+	switch v := v.(type) {
+	case *string:
+		*v = string(data)
+	case *[]byte:
+		*v = bytes.Clone(data)
+	default:
+		return fmt.Errorf("cannot decode text into %T", v)
+	}
+	return nil
+}
+
+// decodeCSV decodes CSV with a header row into a slice of structs, matched by csv or json tag or field name,
+// or into a slice of map[string]string. A [][]string receives every row including the header.
+func decodeCSV(data []byte, v any) error {
+	records, err := csv.NewReader(bytes.NewReader(data)).ReadAll()
+	if err != nil {
+		return err
+	}
+	if rows, ok := v.(*[][]string); ok {
+		*rows = records
+		return nil
+	}
+
+	ptr := reflect.ValueOf(v)
+	if ptr.Kind() != reflect.Pointer || ptr.IsNil() || ptr.Elem().Kind() != reflect.Slice {
+		return fmt.Errorf("cannot decode CSV into %T", v)
+	}
+	slice := ptr.Elem()
+	elem := slice.Type().Elem()
+	if len(records) == 0 {
+		slice.Set(reflect.MakeSlice(slice.Type(), 0, 0))
+		return nil
+	}
+	header, rows := records[0], records[1:]
+
+	out := reflect.MakeSlice(slice.Type(), len(rows), len(rows))
+	for i, row := range rows {
+		item := out.Index(i)
+		switch {
+		case elem.Kind() == reflect.Map && elem.Key().Kind() == reflect.String && elem.Elem().Kind() == reflect.String:
+			m := reflect.MakeMapWithSize(elem, len(header))
+			for j, name := range header {
+				if j < len(row) {
+					m.SetMapIndex(reflect.ValueOf(name).Convert(elem.Key()), reflect.ValueOf(row[j]).Convert(elem.Elem()))
+				}
+			}
+			item.Set(m)
+		case elem.Kind() == reflect.Struct:
+			for j, name := range header {
+				field, ok := csvField(item, name)
+				if !ok || j >= len(row) {
+					continue
+				}
+				if err := setCSVField(field, row[j]); err != nil {
+					return fmt.Errorf("row %d, column %q: %w", i+1, name, err)
+				}
+			}
+		default:
+			return fmt.Errorf("cannot decode CSV into %T", v)
+		}
+	}
+	slice.Set(out)
+	return nil
+}
+
+// csvField returns the field of a struct matching a CSV column by its csv or json tag, or by name when untagged
+func csvField(s reflect.Value, column string) (reflect.Value, bool) {
+	t := s.Type()
+	for i := range t.NumField() {
+		f := t.Field(i)
+		if !f.IsExported() {
+			continue
+		}
+		name := f.Tag.Get("csv")
+		if name == "" {
+			name = f.Tag.Get("json")
+		}
+		if name, _, _ = strings.Cut(name, ","); name == "" {
+			if strings.EqualFold(f.Name, column) {
+				return s.Field(i), true
+			}
+		} else if name == column {
+			return s.Field(i), true
+		}
+	}
+	return reflect.Value{}, false
+}
+
+// setCSVField parses a CSV value into a struct field of a basic kind
+func setCSVField(field reflect.Value, value string) error {
+	if field.Kind() == reflect.Pointer {
+		if value == "" {
+			return nil
+		}
+		field.Set(reflect.New(field.Type().Elem()))
+		field = field.Elem()
+	}
+	if value == "" && field.Kind() != reflect.String {
+		return nil
+	}
+
+	switch field.Kind() {
+	case reflect.String:
+		field.SetString(value)
+	case reflect.Bool:
+		b, err := strconv.ParseBool(value)
+		if err != nil {
+			return err
+		}
+		field.SetBool(b)
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		n, err := strconv.ParseInt(value, 10, field.Type().Bits())
+		if err != nil {
+			return err
+		}
+		field.SetInt(n)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		n, err := strconv.ParseUint(value, 10, field.Type().Bits())
+		if err != nil {
+			return err
+		}
+		field.SetUint(n)
+	case reflect.Float32, reflect.Float64:
+		n, err := strconv.ParseFloat(value, field.Type().Bits())
+		if err != nil {
+			return err
+		}
+		field.SetFloat(n)
+	default:
+		return errors.New("unsupported field type " + field.Type().String())
+	}
+	return nil
+}
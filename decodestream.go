@@ -0,0 +1,72 @@
+package uvgo
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+)
+
+// DecodeOutput runs a script and decodes the JSON value it writes to stdout into the specified type while it
+// is being written, instead of buffering the whole output first as StructuredOutput does, which roughly halves
+// peak memory for very large results. Result.Stdout then only holds the last 64 KiB of output.
+func DecodeOutput[T any](ctx context.Context, r *Runner, scriptPath string, args ...string) (*StructuredResult[T], error) {
+	pr, pw := io.Pipe()
+	sink := io.Writer(pw)
+	if r.stdoutSink != nil {
+		sink = io.MultiWriter(pw, r.stdoutSink)
+	}
+
+	var output T
+	decoded := make(chan error, 1)
+	go func() {
+		decoded <- decodeJSONStream(pr, &output)
+	}()
+
+	result, err := r.With(WithOutputSinks(sink, r.stderrSink)).Run(ctx, scriptPath, args...)
+	pw.Close()
+	decodeErr := <-decoded
+	if err != nil {
+		return &StructuredResult[T]{Result: result}, err
+	}
+	if decodeErr != nil {
+		return &StructuredResult[T]{Result: result}, &PhaseError{
+			Phase: PhaseOutput,
+			Err:   fmt.Errorf("failed to unmarshal script output: %w", decodeErr),
+		}
+	}
+	return &StructuredResult[T]{Result: result, Data: output}, nil
+}
+
+// decodeJSONStream decodes a single JSON value from r into v and checks that only whitespace follows it. It
+// always consumes r to the end so the writer never blocks.
+func decodeJSONStream(r io.Reader, v any) error {
+	dec := json.NewDecoder(r)
+	err := dec.Decode(v)
+	trailing := &nonSpaceDetector{}
+	io.Copy(trailing, io.MultiReader(dec.Buffered(), r))
+	if errors.Is(err, io.EOF) {
+		return io.ErrUnexpectedEOF
+	}
+	if err != nil {
+		return err
+	}
+	if trailing.found {
+		return errors.New("invalid character after top-level value")
+	}
+	return nil
+}
+
+// nonSpaceDetector records whether anything but JSON whitespace was written to it
+type nonSpaceDetector struct {
+	found bool
+}
+
+func (d *nonSpaceDetector) Write(p []byte) (int, error) {
+	if len(bytes.TrimLeft(p, " \t\r\n")) > 0 {
+		d.found = true
+	}
+	return len(p), nil
+}
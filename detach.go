@@ -0,0 +1,138 @@
+package uvgo
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"time"
+)
+
+// DetachOptions configures a detached run started with Detach
+type DetachOptions struct {
+	// Dir is the directory holding the handle file and output logs of the run, defaulting to a new directory
+	// under the system temporary directory. It is kept after the run ends, and reusing it replaces the files
+	// of the previous run.
+	Dir string
+	// Args are passed to the script
+	Args []string
+}
+
+// Handle records a detached run so it can be found again, possibly by another process
+type Handle struct {
+	ID      string    `json:"id"`
+	PID     int       `json:"pid"`
+	Script  string    `json:"script"`
+	Args    []string  `json:"args,omitempty"`
+	Dir     string    `json:"dir"`
+	Stdout  string    `json:"stdout"`
+	Stderr  string    `json:"stderr"`
+	Started time.Time `json:"started"`
+	// ExitFile receives the exit code of the script once it finishes
+	ExitFile string `json:"exit_file"`
+}
+
+// handleFileName is the name of the handle file inside the directory of a detached run
+const handleFileName = "handle.json"
+
+// Path returns the path of the handle file
+func (h *Handle) Path() string {
+	return filepath.Join(h.Dir, handleFileName)
+}
+
+// Detach starts a script that keeps running after the Go process exits. It runs in its own session with stdin
+// closed and its output written to log files, and the returned handle is also saved as handle.json in the run
+// directory. Runner timeouts, middleware and instrumentation do not apply, and only the LocalExecutor on Unix
+// systems supports detached runs.
+func (r *Runner) Detach(ctx context.Context, scriptPath string, opts DetachOptions) (*Handle, error) {
+	local, ok := localExecutor(r.executor)
+	if !ok {
+		return nil, errors.New("detached runs require the local executor")
+	}
+	scriptPath, err := filepath.Abs(scriptPath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to resolve script path: %w", err)
+	}
+	if _, err := os.Stat(scriptPath); err != nil {
+		return nil, fmt.Errorf("script file does not exist: %w", err)
+	}
+
+	if err := r.syncEnv(ctx); err != nil {
+		return nil, &PhaseError{Phase: PhaseResolution, Err: err}
+	}
+
+	h := &Handle{ID: newID(), Script: scriptPath, Args: opts.Args, Dir: opts.Dir}
+	if h.Dir == "" {
+		if h.Dir, err = os.MkdirTemp("", "uvgo-detached-*"); err != nil {
+			return nil, fmt.Errorf("failed to create run directory: %w", err)
+		}
+	} else if err := os.MkdirAll(h.Dir, 0o755); err != nil {
+		return nil, fmt.Errorf("failed to create run directory: %w", err)
+	}
+	h.Stdout = filepath.Join(h.Dir, "stdout.log")
+	h.Stderr = filepath.Join(h.Dir, "stderr.log")
+	h.ExitFile = filepath.Join(h.Dir, "exit")
+
+	// a reused directory still holds how the previous run ended, which would report this run as finished
+	for _, name := range []string{h.ExitFile, h.ExitFile + ".tmp", filepath.Join(h.Dir, signalFileName)} {
+		if err := os.Remove(name); err != nil && !errors.Is(err, os.ErrNotExist) {
+			return nil, fmt.Errorf("failed to clear run directory: %w", err)
+		}
+	}
+
+	stdout, err := os.Create(h.Stdout)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create output log: %w", err)
+	}
+	defer stdout.Close()
+	stderr, err := os.Create(h.Stderr)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create output log: %w", err)
+	}
+	defer stderr.Close()
+
+	uvPath := local.Path
+	if uvPath == "" {
+		uvPath = "uv"
+	}
+	args := append(r.runArgs(), scriptPath)
+	if len(opts.Args) > 0 {
+		args = append(args, opts.Args...)
+	} else {
+		args = append(args, r.scriptArgs...)
+	}
+
+	// a shell waits for uv to record its exit code, since no Go process is left to collect it. Signals sent to
+	// the process group are trapped so the shell outlives the script and records how it ended.
+	cmd := exec.Command("/bin/sh", append([]string{"-c", `trap : HUP INT TERM; "$@"; echo $? > "$UVGO_EXIT_FILE.tmp"; mv "$UVGO_EXIT_FILE.tmp" "$UVGO_EXIT_FILE"`, "sh", uvPath}, args...)...)
+	cmd.Dir = r.workDir
+	cmd.Env = append(os.Environ(), r.commandEnv(ctx, &execution{})...)
+	cmd.Env = append(cmd.Env, "UVGO_EXIT_FILE="+h.ExitFile)
+	cmd.Stdout = stdout
+	cmd.Stderr = stderr
+	if cmd.SysProcAttr, err = detachedProcAttr(); err != nil {
+		return nil, err
+	}
+
+	r.logCommand(ctx, h.ID, &Invocation{Args: args, Env: r.commandEnv(ctx, &execution{}), Dir: r.workDir})
+
+	if err := cmd.Start(); err != nil {
+		return nil, fmt.Errorf("failed to start detached run: %w", err)
+	}
+	h.PID = cmd.Process.Pid
+	h.Started = time.Now()
+	// reap the process should it exit while the Go process is still running
+	go cmd.Wait()
+
+	data, err := json.MarshalIndent(h, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+	if err := os.WriteFile(h.Path(), data, 0o644); err != nil {
+		return h, fmt.Errorf("failed to write handle file: %w", err)
+	}
+	return h, nil
+}
@@ -0,0 +1,12 @@
+//go:build !unix
+
+package uvgo
+
+import (
+	"errors"
+	"syscall"
+)
+
+func detachedProcAttr() (*syscall.SysProcAttr, error) {
+	return nil, errors.New("detached runs are not supported on this platform")
+}
@@ -0,0 +1,11 @@
+//go:build unix
+
+package uvgo
+
+import "syscall"
+
+// detachedProcAttr starts a process in a new session, detached from the terminal and process group of the
+// Go process
+func detachedProcAttr() (*syscall.SysProcAttr, error) {
+	return &syscall.SysProcAttr{Setsid: true}, nil
+}
@@ -0,0 +1,137 @@
+package uvgo
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"sort"
+	"time"
+)
+
+// DeterminismOptions configures CheckDeterminism
+type DeterminismOptions struct {
+	// Runs is the number of times the script runs, defaulting to 2
+	Runs int
+	// HashSeed, when set, fixes PYTHONHASHSEED so string hashing and set ordering are stable across runs
+	HashSeed string
+	// ExcludeNewer, when set, resolves dependencies as of that time so every run uses the same versions
+	ExcludeNewer time.Time
+	// Args are passed to the script
+	Args []string
+}
+
+// DeterminismReport is the outcome of CheckDeterminism
+type DeterminismReport struct {
+	// Deterministic reports whether every run produced the same structured output
+	Deterministic bool
+	// Diff describes the first difference found, such as `$.items[2].score: 0.5 != 0.75`
+	Diff    string
+	Results []*Result
+}
+
+// CheckDeterminism runs a script several times and reports whether its JSON outputs match, comparing decoded
+// values so formatting and object key order do not matter. It returns an error when a run fails or prints
+// something other than JSON.
+func (r *Runner) CheckDeterminism(ctx context.Context, scriptPath string, opts DeterminismOptions) (*DeterminismReport, error) {
+	if opts.Runs < 2 {
+		opts.Runs = 2
+	}
+
+	var options []Option
+	if opts.HashSeed != "" {
+		options = append(options, WithEnv("PYTHONHASHSEED="+opts.HashSeed))
+	}
+	if !opts.ExcludeNewer.IsZero() {
+		options = append(options, WithExtraFlags("--exclude-newer", opts.ExcludeNewer.UTC().Format(time.RFC3339)))
+	}
+	runner := r.With(options...)
+
+	report := &DeterminismReport{Deterministic: true}
+	var first any
+	for i := 0; i < opts.Runs; i++ {
+		result, err := runner.Run(ctx, scriptPath, opts.Args...)
+		report.Results = append(report.Results, result)
+		if err != nil {
+			return report, fmt.Errorf("run %d: %w", i, err)
+		}
+
+		var output any
+		if err := json.Unmarshal([]byte(result.Stdout), &output); err != nil {
+			return report, &PhaseError{Phase: PhaseOutput, Err: fmt.Errorf("run %d: failed to unmarshal script output: %w", i, err)}
+		}
+
+		if i == 0 {
+			first = output
+			continue
+		}
+		if diff := jsonDiff("$", first, output); diff != "" && report.Deterministic {
+			report.Deterministic = false
+			report.Diff = fmt.Sprintf("run %d: %s", i, diff)
+		}
+	}
+	return report, nil
+}
+
+// jsonDiff describes the first difference between two decoded JSON values, or returns an empty string
+func jsonDiff(path string, a, b any) string {
+	switch a := a.(type) {
+	case map[string]any:
+		b, ok := b.(map[string]any)
+		if !ok {
+			break
+		}
+		keys := make([]string, 0, len(a)+len(b))
+		for k := range a {
+			keys = append(keys, k)
+		}
+		for k := range b {
+			if _, ok := a[k]; !ok {
+				keys = append(keys, k)
+			}
+		}
+		sort.Strings(keys)
+
+		for _, k := range keys {
+			av, aok := a[k]
+			bv, bok := b[k]
+			switch {
+			case !aok:
+				return fmt.Sprintf("%s.%s: missing in the first run", path, k)
+			case !bok:
+				return fmt.Sprintf("%s.%s: missing", path, k)
+			}
+			if diff := jsonDiff(path+"."+k, av, bv); diff != "" {
+				return diff
+			}
+		}
+		return ""
+	case []any:
+		b, ok := b.([]any)
+		if !ok {
+			break
+		}
+		if len(a) != len(b) {
+			return fmt.Sprintf("%s: length %d != %d", path, len(a), len(b))
+		}
+		for i := range a {
+			if diff := jsonDiff(fmt.Sprintf("%s[%d]", path, i), a[i], b[i]); diff != "" {
+				return diff
+			}
+		}
+		return ""
+	}
+
+	if reflect.DeepEqual(a, b) {
+		return ""
+	}
+	return fmt.Sprintf("%s: %s != %s", path, compactJSON(a), compactJSON(b))
+}
+
+func compactJSON(v any) string {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return fmt.Sprint(v)
+	}
+	return truncate(string(data), 80)
+}
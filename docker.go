@@ -0,0 +1,143 @@
+package uvgo
+
+import (
+	"context"
+	"errors"
+	"os/exec"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// DockerExecutor runs uv inside a fresh container for every run through the docker command, isolating
+// untrusted scripts from the host far more strongly than a subprocess. The script, the run's temporary
+// directory and the working directory are bind-mounted at their host paths, so the image only needs uv.
+// Features relying on a local connection to the script, such as WithBridge, are not available.
+type DockerExecutor struct {
+	// Image is the container image, which must provide uv, such as "ghcr.io/astral-sh/uv:python3.12-bookworm-slim"
+	Image string
+	// UVPath is the uv executable inside the container, defaulting to "uv"
+	UVPath string
+	// Memory limits the container memory, such as "512m"
+	Memory string
+	// CPUs limits the number of CPUs the container may use, such as 1.5
+	CPUs float64
+	// PidsLimit limits the number of processes in the container
+	PidsLimit int
+	// Network is the container network mode, such as "none" to deny network access once dependencies are
+	// cached. It defaults to the docker default network.
+	Network string
+	// User runs the container as the given "uid:gid"
+	User string
+	// ReadOnly mounts the container root filesystem read-only with a tmpfs at /tmp. uv still needs a writable
+	// cache directory, such as a volume from Volumes.
+	ReadOnly bool
+	// Volumes are extra volume mounts in docker "-v" syntax, such as "uvgo-cache:/root/.cache/uv" to share
+	// the uv cache between runs
+	Volumes []string
+	// Args are extra arguments passed to docker run
+	Args []string
+	// DockerPath is the docker executable, defaulting to "docker" looked up in PATH
+	DockerPath string
+}
+
+// Execute implements Executor
+func (e DockerExecutor) Execute(ctx context.Context, inv *Invocation) (*ExitStatus, error) {
+	name := "uvgo-" + newID()
+
+	cmd := exec.CommandContext(ctx, e.dockerPath(), e.runArgs(name, inv)...)
+	cmd.Stdin = inv.Stdin
+	cmd.Stdout = inv.Stdout
+	cmd.Stderr = inv.Stderr
+	// killing the docker client leaves the container running, so cancellation kills the container first
+	cmd.Cancel = func() error {
+		killCtx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+		defer cancel()
+		exec.CommandContext(killCtx, e.dockerPath(), "kill", name).Run()
+		return cmd.Process.Kill()
+	}
+	cmd.WaitDelay = 10 * time.Second
+
+	if err := cmd.Start(); err != nil {
+		return nil, err
+	}
+	if inv.Started != nil {
+		inv.Started(cmd.Process.Pid)
+	}
+
+	err := cmd.Wait()
+	status := &ExitStatus{ExitCode: -1}
+	if cmd.ProcessState != nil {
+		status.ExitCode = cmd.ProcessState.ExitCode()
+	}
+
+	var exitError *exec.ExitError
+	if errors.As(err, &exitError) {
+		if ctx.Err() != nil {
+			status.ExitCode = -1
+		}
+		return status, &ExitError{Code: status.ExitCode, Err: err}
+	}
+	return status, err
+}
+
+// runArgs returns the docker arguments running an invocation in a container with the given name
+func (e DockerExecutor) runArgs(name string, inv *Invocation) []string {
+	args := []string{"run", "--rm", "--init", "--name", name}
+	if inv.Stdin != nil {
+		args = append(args, "-i")
+	}
+
+	if e.Memory != "" {
+		args = append(args, "--memory", e.Memory)
+	}
+	if e.CPUs > 0 {
+		args = append(args, "--cpus", strconv.FormatFloat(e.CPUs, 'f', -1, 64))
+	}
+	if e.PidsLimit > 0 {
+		args = append(args, "--pids-limit", strconv.Itoa(e.PidsLimit))
+	}
+	if e.Network != "" {
+		args = append(args, "--network", e.Network)
+	}
+	if e.User != "" {
+		args = append(args, "--user", e.User)
+	}
+	if e.ReadOnly {
+		args = append(args, "--read-only", "--tmpfs", "/tmp")
+	}
+
+	if inv.TempDir != "" {
+		args = append(args, "-v", inv.TempDir+":"+inv.TempDir)
+	}
+	if inv.Script != "" {
+		args = append(args, "-v", inv.Script+":"+inv.Script+":ro")
+	}
+	if inv.Dir != "" {
+		if inv.TempDir == "" || !strings.HasPrefix(inv.Dir, inv.TempDir) {
+			args = append(args, "-v", inv.Dir+":"+inv.Dir)
+		}
+		args = append(args, "-w", inv.Dir)
+	}
+	for _, volume := range e.Volumes {
+		args = append(args, "-v", volume)
+	}
+	for _, kv := range inv.Env {
+		args = append(args, "-e", kv)
+	}
+	args = append(args, e.Args...)
+
+	uvPath := e.UVPath
+	if uvPath == "" {
+		uvPath = "uv"
+	}
+	args = append(args, e.Image, uvPath)
+	return append(args, inv.Args...)
+}
+
+func (e DockerExecutor) dockerPath() string {
+	if e.DockerPath != "" {
+		return e.DockerPath
+	}
+	return "docker"
+}
@@ -0,0 +1,202 @@
+package uvgo
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"strings"
+)
+
+// CheckStatus represents the outcome of a single environment check
+type CheckStatus string
+
+const (
+	CheckOK      CheckStatus = "ok"
+	CheckFailed  CheckStatus = "failed"
+	CheckSkipped CheckStatus = "skipped"
+)
+
+// Check represents a single environment check performed by Doctor
+type Check struct {
+	Name   string
+	Status CheckStatus
+	Detail string
+}
+
+// DoctorReport represents the result of validating the runner's environment
+type DoctorReport struct {
+	UVVersion string
+	Python    string
+	CacheDir  string
+	Checks    []Check
+}
+
+// OK reports whether no check failed
+func (d *DoctorReport) OK() bool {
+	return d.Err() == nil
+}
+
+// Err returns an error describing every failed check, or nil
+func (d *DoctorReport) Err() error {
+	var errs []error
+	for _, c := range d.Checks {
+		if c.Status == CheckFailed {
+			errs = append(errs, fmt.Errorf("%s: %s", c.Name, c.Detail))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+func (d *DoctorReport) add(name string, err error, detail string) {
+	if err != nil {
+		d.Checks = append(d.Checks, Check{Name: name, Status: CheckFailed, Detail: err.Error()})
+		return
+	}
+	d.Checks = append(d.Checks, Check{Name: name, Status: CheckOK, Detail: detail})
+}
+
+func (d *DoctorReport) skip(name, detail string) {
+	d.Checks = append(d.Checks, Check{Name: name, Status: CheckSkipped, Detail: detail})
+}
+
+// Validate checks the runner's environment and returns an error describing every failed check
+func (r *Runner) Validate(ctx context.Context) error {
+	return r.Doctor(ctx).Err()
+}
+
+// Doctor checks that uv works, the requested Python is available, the package indexes are reachable, or the
+// wheel directory holds wheels in air-gapped mode, the uv cache is writable and the dependencies resolve, so
+// services can fail fast at startup
+func (r *Runner) Doctor(ctx context.Context) *DoctorReport {
+	report := &DoctorReport{}
+
+	version, err := r.uvOutput(ctx, nil, "--version")
+	if err != nil {
+		report.add("uv", err, "")
+		return report
+	}
+	report.UVVersion = strings.TrimPrefix(version, "uv ")
+	report.add("uv", nil, version)
+
+	findArgs := []string{"python", "find"}
+	if r.pythonVersion != "" {
+		findArgs = append(findArgs, r.pythonVersion)
+	}
+	report.Python, err = r.uvOutput(ctx, nil, findArgs...)
+	report.add("python", err, report.Python)
+
+	if r.airGapped != "" {
+		detail, err := checkWheelDir(r.airGapped)
+		report.add("wheels", err, detail)
+	}
+
+	client, clientErr := r.httpClient()
+	for _, index := range r.indexes() {
+		if r.airGapped != "" {
+			report.skip("index "+redactURL(index), "air-gapped mode installs from the wheel directory only")
+			continue
+		}
+		err := clientErr
+		if err == nil {
+			err = checkIndex(ctx, client, index)
+		}
+		report.add("index "+redactURL(index), err, "reachable")
+	}
+
+	report.CacheDir, err = r.uvOutput(ctx, nil, "cache", "dir")
+	switch {
+	case err != nil:
+		report.add("cache", err, "")
+	case !r.local():
+		report.skip("cache", "cache writability can only be checked for local execution")
+	default:
+		report.add("cache", checkWritable(report.CacheDir), report.CacheDir)
+	}
+
+	if len(r.dependencies) == 0 {
+		report.skip("dependencies", "no dependencies configured")
+	} else {
+		compileArgs := []string{"pip", "compile", "-", "--quiet"}
+		if r.pythonVersion != "" {
+			compileArgs = append(compileArgs, "--python-version", r.pythonVersion)
+		}
+		compileArgs = append(compileArgs, r.indexArgs()...)
+
+		requirements := strings.NewReader(strings.Join(r.dependencies, "\n"))
+		_, err := r.uvOutput(ctx, requirements, compileArgs...)
+		report.add("dependencies", err, strings.Join(r.dependencies, ", "))
+	}
+
+	return report
+}
+
+// uvOutput runs a uv command and returns its trimmed standard output
+func (r *Runner) uvOutput(ctx context.Context, stdin io.Reader, uvArgs ...string) (string, error) {
+	ctx, cancel := r.withTimeout(ctx)
+	defer cancel()
+
+	result, err := r.runUV(ctx, &execution{}, uvArgs, stdin)
+	if err != nil {
+		if stderr := strings.TrimSpace(result.Stderr); stderr != "" {
+			return "", errors.New(stderr)
+		}
+		return "", err
+	}
+	return strings.TrimSpace(result.Stdout), nil
+}
+
+// defaultIndexURL is the index uv resolves from unless the runner sets another
+const defaultIndexURL = "https://pypi.org/simple/"
+
+// indexes returns the package indexes the runner resolves from
+func (r *Runner) indexes() []string {
+	index := r.indexURL
+	if index == "" {
+		index = defaultIndexURL
+	}
+	indexes := append([]string{index}, r.extraIndexes...)
+	if url := r.torchBackend.IndexURL(); url != "" {
+		indexes = append(indexes, url)
+	}
+	return indexes
+}
+
+// local reports whether uv runs on this machine
+func (r *Runner) local() bool {
+	_, ok := localExecutor(r.executor)
+	return ok
+}
+
+func checkIndex(ctx context.Context, client *http.Client, index string) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodHead, index, nil)
+	if err != nil {
+		return err
+	}
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return err
+	}
+	resp.Body.Close()
+
+	if resp.StatusCode >= 400 {
+		return fmt.Errorf("unexpected status %s", resp.Status)
+	}
+	return nil
+}
+
+func checkWritable(dir string) error {
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return err
+	}
+
+	f, err := os.CreateTemp(dir, ".uvgo-doctor-*")
+	if err != nil {
+		return err
+	}
+	f.Close()
+	return os.Remove(f.Name())
+}
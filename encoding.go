@@ -0,0 +1,24 @@
+package uvgo
+
+import "strings"
+
+// WithUTF8 forces Python into UTF-8 mode for its standard streams and files through PYTHONUTF8 and
+// PYTHONIOENCODING, and replaces invalid UTF-8 in captured output and handler lines with U+FFFD, so results
+// are not garbled on Windows or under legacy locales
+func WithUTF8() Option {
+	return func(r *Runner) {
+		r.utf8 = true
+		r.env = append(r.env, "PYTHONUTF8=1", "PYTHONIOENCODING=utf-8")
+	}
+}
+
+// cleanOutput applies the runner's output processing to captured output
+func (r *Runner) cleanOutput(s string) string {
+	if r.utf8 {
+		s = strings.ToValidUTF8(s, "\uFFFD")
+	}
+	if r.stripANSI {
+		s = StripANSI(s)
+	}
+	return s
+}
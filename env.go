@@ -0,0 +1,104 @@
+package uvgo
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// NewFromEnv creates a new UV runner configured from UVGO_* environment variables, applying the provided
+// options afterwards so they take precedence. The recognized variables are:
+//
+//	UVGO_CONFIG            path of a config file loaded with LoadConfig before the other variables
+//	UVGO_UV_PATH           path of the uv executable
+//	UVGO_PYTHON            python version
+//	UVGO_TIMEOUT           execution timeout, such as "30s"
+//	UVGO_DEPS              comma-separated Python dependencies
+//	UVGO_INDEX_URL         default package index
+//	UVGO_EXTRA_INDEX_URLS  comma-separated additional package indexes
+//	UVGO_EXTRA_FLAGS       space-separated extra flags passed to uv
+//	UVGO_WORK_DIR          working directory
+//	UVGO_MAX_ARTIFACT_SIZE enables artifact collection with the given per-file size limit in bytes
+func NewFromEnv(options ...Option) (*Runner, error) {
+	envOptions, err := envConfig()
+	if err != nil {
+		return nil, err
+	}
+	return New(append(envOptions, options...)...)
+}
+
+func envConfig() ([]Option, error) {
+	var opts []Option
+
+	if path := os.Getenv("UVGO_CONFIG"); path != "" {
+		cfgOptions, err := LoadConfig(path)
+		if err != nil {
+			return nil, err
+		}
+		opts = append(opts, cfgOptions...)
+	}
+
+	if path := os.Getenv("UVGO_UV_PATH"); path != "" {
+		resolved, err := exec.LookPath(path)
+		if err != nil {
+			return nil, fmt.Errorf("invalid UVGO_UV_PATH: %w", err)
+		}
+		opts = append(opts, WithExecutor(LocalExecutor{Path: resolved}))
+	}
+
+	if v := os.Getenv("UVGO_PYTHON"); v != "" {
+		opts = append(opts, WithPython(v))
+	}
+
+	if v := os.Getenv("UVGO_TIMEOUT"); v != "" {
+		timeout, err := time.ParseDuration(v)
+		if err != nil {
+			return nil, fmt.Errorf("invalid UVGO_TIMEOUT: %w", err)
+		}
+		opts = append(opts, WithTimeout(timeout))
+	}
+
+	if deps := splitList(os.Getenv("UVGO_DEPS")); len(deps) > 0 {
+		opts = append(opts, WithDependencies(deps...))
+	}
+
+	if v := os.Getenv("UVGO_INDEX_URL"); v != "" {
+		opts = append(opts, WithIndexURL(v))
+	}
+
+	if urls := splitList(os.Getenv("UVGO_EXTRA_INDEX_URLS")); len(urls) > 0 {
+		opts = append(opts, WithExtraIndexURLs(urls...))
+	}
+
+	if flags := strings.Fields(os.Getenv("UVGO_EXTRA_FLAGS")); len(flags) > 0 {
+		opts = append(opts, WithExtraFlags(flags...))
+	}
+
+	if v := os.Getenv("UVGO_WORK_DIR"); v != "" {
+		opts = append(opts, WithWorkDir(v))
+	}
+
+	if v := os.Getenv("UVGO_MAX_ARTIFACT_SIZE"); v != "" {
+		size, err := strconv.ParseInt(v, 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("invalid UVGO_MAX_ARTIFACT_SIZE: %w", err)
+		}
+		opts = append(opts, WithArtifacts(size))
+	}
+
+	return opts, nil
+}
+
+// splitList splits a comma-separated list, dropping empty entries
+func splitList(s string) []string {
+	var out []string
+	for _, item := range strings.Split(s, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			out = append(out, item)
+		}
+	}
+	return out
+}
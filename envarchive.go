@@ -0,0 +1,149 @@
+package uvgo
+
+import (
+	"archive/tar"
+	"compress/gzip"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"slices"
+	"time"
+)
+
+// envArchiveCache is the directory of an environment archive holding the uv cache of its packages
+const envArchiveCache = "cache"
+
+// ExportEnv resolves the runner's dependencies and writes a gzipped tar archive to w holding their lock and a
+// uv cache with every package they pin, so ImportEnv can install the same environment on another machine
+// without network access. The interpreter itself is not included, so the importing machine must provide a
+// matching Python version.
+func (r *Runner) ExportEnv(ctx context.Context, w io.Writer) error {
+	if !r.local() {
+		return errors.New("environments can only be exported with the local executor")
+	}
+
+	dir, err := os.MkdirTemp("", "uvgo-export-*")
+	if err != nil {
+		return fmt.Errorf("failed to create export directory: %w", err)
+	}
+	defer os.RemoveAll(dir)
+
+	lock := &namedEnvLock{
+		Python:       r.pythonVersion,
+		Dependencies: slices.Sorted(slices.Values(r.dependencies)),
+		Indexes:      r.indexes(),
+	}
+
+	// installing into a scratch environment with a fresh cache leaves exactly the packages the lock needs in it
+	venv := filepath.Join(dir, "venv")
+	cache := filepath.Join(dir, envArchiveCache)
+	if err := r.createVenv(ctx, venv, lock.Python); err != nil {
+		return fmt.Errorf("failed to create environment: %w", err)
+	}
+	if len(lock.Dependencies) > 0 {
+		if lock.Requirements, err = r.pipCompile(ctx, lock.Dependencies, "--no-annotate", "--python", venvPython(venv)); err != nil {
+			return fmt.Errorf("failed to lock environment: %w", err)
+		}
+	}
+	if err := r.syncVenv(ctx, venv, lock.Requirements, "--cache-dir", cache); err != nil {
+		return fmt.Errorf("failed to download environment: %w", err)
+	}
+
+	data, err := json.MarshalIndent(lock, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode environment lock: %w", err)
+	}
+
+	gz := gzip.NewWriter(w)
+	tw := tar.NewWriter(gz)
+	header := &tar.Header{Typeflag: tar.TypeReg, Name: namedEnvFile, Mode: 0o644, Size: int64(len(data)), ModTime: time.Now()}
+	if err := tw.WriteHeader(header); err != nil {
+		return fmt.Errorf("failed to write environment archive: %w", err)
+	}
+	if _, err := tw.Write(data); err != nil {
+		return fmt.Errorf("failed to write environment archive: %w", err)
+	}
+	if fileExists(cache) {
+		if err := tarDir(tw, cache, envArchiveCache); err != nil {
+			return fmt.Errorf("failed to write environment archive: %w", err)
+		}
+	}
+	if err := tw.Close(); err != nil {
+		return fmt.Errorf("failed to write environment archive: %w", err)
+	}
+	if err := gz.Close(); err != nil {
+		return fmt.Errorf("failed to write environment archive: %w", err)
+	}
+	return nil
+}
+
+// ImportEnv installs an environment archive written by ExportEnv as the named environment with the given name,
+// offline, replacing any environment of that name. Runners with WithNamedEnv(name) and the Python version,
+// dependencies and indexes the archive was exported with then reuse it without resolving or downloading anything.
+func (r *Runner) ImportEnv(ctx context.Context, rd io.Reader, name string) error {
+	if !r.local() {
+		return errors.New("environments can only be imported with the local executor")
+	}
+
+	dir, err := NamedEnvDir(name)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(dir), 0o755); err != nil {
+		return fmt.Errorf("failed to create environment directory: %w", err)
+	}
+
+	unlock, err := lockPath(dir + ".lock")
+	if err != nil {
+		return fmt.Errorf("failed to lock environment %s: %w", name, err)
+	}
+	defer unlock()
+
+	staging, err := os.MkdirTemp("", "uvgo-import-*")
+	if err != nil {
+		return fmt.Errorf("failed to create import directory: %w", err)
+	}
+	defer os.RemoveAll(staging)
+
+	gz, err := gzip.NewReader(rd)
+	if err != nil {
+		return fmt.Errorf("failed to read environment archive: %w", err)
+	}
+	if err := untar(gz, staging); err != nil {
+		return err
+	}
+
+	data, err := os.ReadFile(filepath.Join(staging, namedEnvFile))
+	if err != nil {
+		return fmt.Errorf("failed to read environment lock: %w", err)
+	}
+	var lock namedEnvLock
+	if err := json.Unmarshal(data, &lock); err != nil {
+		return fmt.Errorf("failed to parse environment lock: %w", err)
+	}
+
+	// uv keys cached packages by index, so the sync must name the indexes the archive was exported with
+	importer := r.With(func(c *Runner) {
+		c.indexURL, c.extraIndexes = "", nil
+		if len(lock.Indexes) > 0 {
+			if lock.Indexes[0] != defaultIndexURL {
+				c.indexURL = lock.Indexes[0]
+			}
+			c.extraIndexes = lock.Indexes[1:]
+		}
+	})
+
+	if err := importer.createVenv(ctx, dir, lock.Python); err != nil {
+		return fmt.Errorf("failed to create environment %s: %w", name, err)
+	}
+	// copying rather than linking keeps the environment intact once the staged cache is removed
+	cache := filepath.Join(staging, envArchiveCache)
+	if err := importer.syncVenv(ctx, dir, lock.Requirements, "--offline", "--cache-dir", cache, "--link-mode", "copy"); err != nil {
+		return fmt.Errorf("failed to install environment %s: %w", name, err)
+	}
+	return writeNamedEnvLock(dir, &lock)
+}
@@ -0,0 +1,157 @@
+package uvgo
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"runtime"
+	"slices"
+	"strings"
+	"time"
+)
+
+// WithEnvCache runs scripts in persistent environments managed by the package, keyed by a digest of the
+// Python version, dependencies, indexes and platform, so runners with the same dependency set share one
+// environment across runs and process restarts. Whenever a new environment is synced, the least recently used
+// ones are removed until the cache fits in maxSize bytes; a maxSize of zero or less leaves the cache unbounded.
+// WithNamedEnv takes precedence over the cache.
+func WithEnvCache(maxSize int64) Option {
+	return func(r *Runner) {
+		r.envCache = true
+		r.envCacheSize = maxSize
+	}
+}
+
+// envCacheDir returns the directory holding the environments of the environment cache
+func envCacheDir() (string, error) {
+	cacheDir, err := os.UserCacheDir()
+	if err != nil {
+		return "", fmt.Errorf("failed to locate cache directory: %w", err)
+	}
+	return filepath.Join(cacheDir, "uvgo", "env-cache"), nil
+}
+
+// envDigest returns the key of the runner's environment in the environment cache
+func (r *Runner) envDigest() string {
+	h := sha256.New()
+	fmt.Fprintf(h, "python=%s\nplatform=%s/%s\n", r.pythonVersion, runtime.GOOS, runtime.GOARCH)
+	for _, dep := range slices.Sorted(slices.Values(r.dependencies)) {
+		fmt.Fprintf(h, "dependency=%s\n", strings.TrimSpace(dep))
+	}
+	for _, index := range r.indexes() {
+		fmt.Fprintf(h, "index=%s\n", index)
+	}
+	return hex.EncodeToString(h.Sum(nil))[:32]
+}
+
+// cachedEnv is an environment of the environment cache
+type cachedEnv struct {
+	name     string
+	size     int64
+	lastUsed time.Time
+}
+
+// pruneEnvCache removes the least recently used environments below root, other than keep, until their total
+// size fits in maxSize
+func pruneEnvCache(root string, maxSize int64, keep string) error {
+	if maxSize <= 0 {
+		return nil
+	}
+
+	entries, err := os.ReadDir(root)
+	if err != nil {
+		return fmt.Errorf("failed to read environment cache: %w", err)
+	}
+
+	var envs []cachedEnv
+	var total int64
+	for _, entry := range entries {
+		if !entry.IsDir() {
+			continue
+		}
+		env, ok := readCachedEnv(root, entry.Name())
+		if !ok {
+			continue
+		}
+		total += env.size
+		if env.name != keep {
+			envs = append(envs, env)
+		}
+	}
+
+	slices.SortFunc(envs, func(a, b cachedEnv) int { return a.lastUsed.Compare(b.lastUsed) })
+	for _, env := range envs {
+		if total <= maxSize {
+			break
+		}
+		removed, err := removeCachedEnv(root, env)
+		if err != nil {
+			return err
+		}
+		if removed {
+			total -= env.size
+		}
+	}
+	return nil
+}
+
+// readCachedEnv reads the size and last use of an environment from its lock
+func readCachedEnv(root, name string) (cachedEnv, bool) {
+	lockFile := filepath.Join(root, name, namedEnvFile)
+	info, err := os.Stat(lockFile)
+	if err != nil {
+		return cachedEnv{}, false
+	}
+	data, err := os.ReadFile(lockFile)
+	if err != nil {
+		return cachedEnv{}, false
+	}
+	var lock namedEnvLock
+	if err := json.Unmarshal(data, &lock); err != nil {
+		return cachedEnv{}, false
+	}
+	return cachedEnv{name: name, size: lock.Size, lastUsed: info.ModTime()}, true
+}
+
+// removeCachedEnv removes an environment under its lock unless it was used since it was chosen for removal
+func removeCachedEnv(root string, env cachedEnv) (bool, error) {
+	dir := filepath.Join(root, env.name)
+	unlock, err := lockPath(dir + ".lock")
+	if err != nil {
+		return false, fmt.Errorf("failed to lock environment %s: %w", env.name, err)
+	}
+	defer unlock()
+
+	if current, ok := readCachedEnv(root, env.name); ok && current.lastUsed.After(env.lastUsed) {
+		return false, nil
+	}
+	// the lock file is kept, since removing it would let a process waiting on it and a new one lock the
+	// environment at the same time
+	if err := os.RemoveAll(dir); err != nil {
+		return false, fmt.Errorf("failed to remove environment %s: %w", env.name, err)
+	}
+	return true, nil
+}
+
+// dirSize returns the total size of the regular files below dir
+func dirSize(dir string) (int64, error) {
+	var size int64
+	err := filepath.WalkDir(dir, func(_ string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if d.Type().IsRegular() {
+			info, err := d.Info()
+			if err != nil {
+				return err
+			}
+			size += info.Size()
+		}
+		return nil
+	})
+	return size, err
+}
@@ -0,0 +1,133 @@
+package uvgo
+
+import (
+	"context"
+	"io"
+	"sync"
+	"time"
+)
+
+// EventType identifies the kind of a run lifecycle event
+type EventType string
+
+const (
+	// EventQueued is emitted when a run enters the runner
+	EventQueued EventType = "queued"
+	// EventStarted is emitted once the uv process has started
+	EventStarted EventType = "started"
+	// EventOutput is emitted for every chunk written to stdout or stderr
+	EventOutput EventType = "output"
+	// EventFinished is emitted when a run completes, successfully or not
+	EventFinished EventType = "finished"
+	// EventKilled is emitted instead of EventFinished when a run was killed by cancellation or timeout
+	EventKilled EventType = "killed"
+)
+
+// Event represents a single run lifecycle event
+type Event struct {
+	Type  EventType
+	RunID string
+	Time  time.Time
+	// PID is set for EventStarted to the ID of the local process running the script, or 0 when it runs remotely
+	PID int
+	// Stream and Data are set for EventOutput
+	Stream string
+	Data   []byte
+	// Result and Err are set for EventFinished and EventKilled
+	Result *Result
+	Err    error
+}
+
+// EventBus delivers run lifecycle events to subscribers
+type EventBus struct {
+	mu   sync.RWMutex
+	next int
+	subs map[int]func(Event)
+}
+
+// NewEventBus creates an empty event bus
+func NewEventBus() *EventBus {
+	return &EventBus{subs: make(map[int]func(Event))}
+}
+
+// WithEventBus publishes the lifecycle events of every script run to bus
+func WithEventBus(bus *EventBus) Option {
+	return func(r *Runner) { r.events = bus }
+}
+
+// Subscribe registers fn to receive every event and returns a function removing it.
+// Subscribers are called synchronously and may be called concurrently, so they must not block. A subscriber
+// removed while an event is being delivered may still receive that event.
+func (b *EventBus) Subscribe(fn func(Event)) (unsubscribe func()) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	id := b.next
+	b.next++
+	b.subs[id] = fn
+
+	return func() {
+		b.mu.Lock()
+		defer b.mu.Unlock()
+		delete(b.subs, id)
+	}
+}
+
+func (b *EventBus) publish(e Event) {
+	if b == nil || e.RunID == "" {
+		return
+	}
+	if e.Time.IsZero() {
+		e.Time = time.Now()
+	}
+
+	// subscribers are called without the lock held, so they can subscribe and unsubscribe themselves
+	b.mu.RLock()
+	subs := make([]func(Event), 0, len(b.subs))
+	for _, fn := range b.subs {
+		subs = append(subs, fn)
+	}
+	b.mu.RUnlock()
+
+	for _, fn := range subs {
+		fn(e)
+	}
+}
+
+// finished publishes the terminal event of a run
+func (b *EventBus) finished(ctx context.Context, runID string, result *Result, err error) {
+	e := Event{Type: EventFinished, RunID: runID, Result: result, Err: err}
+	if err != nil && ctx.Err() != nil {
+		e.Type = EventKilled
+	}
+	if err != nil && result != nil && result.ExitCode < 0 {
+		e.Type = EventKilled
+	}
+	b.publish(e)
+}
+
+// output returns a writer copying everything written to w into output events
+func (b *EventBus) output(runID, stream string, w io.Writer) io.Writer {
+	if b == nil || runID == "" {
+		return w
+	}
+	return &eventWriter{bus: b, runID: runID, stream: stream, w: w}
+}
+
+type eventWriter struct {
+	bus    *EventBus
+	runID  string
+	stream string
+	w      io.Writer
+}
+
+func (e *eventWriter) Write(p []byte) (int, error) {
+	n, err := e.w.Write(p)
+	e.bus.publish(Event{
+		Type:   EventOutput,
+		RunID:  e.runID,
+		Stream: e.stream,
+		Data:   append([]byte(nil), p[:n]...),
+	})
+	return n, err
+}
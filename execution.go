@@ -0,0 +1,230 @@
+package uvgo
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// execution holds the per-run state used to instrument a script invocation
+type execution struct {
+	runID    string
+	executor Executor
+	dir      string
+	workDir  string
+	script   string
+	with     []string
+	env      []string
+	setup    []string
+	teardown []string
+	collect  []func(*Result) error
+	release  []func()
+	blobRefs []blobRef
+	blobErr  error
+	// scriptRun reports whether the execution runs the script itself, which alone calls back into the runner
+	// and gets its channels, rather than a tool such as pytest
+	scriptRun bool
+}
+
+// tempDir returns the execution's temporary directory, creating it on first use
+func (x *execution) tempDir() (string, error) {
+	if x.dir != "" {
+		return x.dir, nil
+	}
+
+	dir, err := os.MkdirTemp("", "uvgo-run-*")
+	if err != nil {
+		return "", fmt.Errorf("failed to create execution directory: %w", err)
+	}
+	x.dir = dir
+	return dir, nil
+}
+
+// tempPath returns the path of a file inside the execution's temporary directory
+func (x *execution) tempPath(name string) (string, error) {
+	dir, err := x.tempDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, name), nil
+}
+
+// writeFile writes a file inside the execution's temporary directory and returns its path
+func (x *execution) writeFile(name, content string) (string, error) {
+	path, err := x.tempPath(name)
+	if err != nil {
+		return "", err
+	}
+	if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+		return "", fmt.Errorf("failed to write %s: %w", name, err)
+	}
+	return path, nil
+}
+
+// instrumented reports whether the script must be launched through the bootstrap
+func (x *execution) instrumented() bool {
+	return len(x.setup) > 0 || len(x.teardown) > 0
+}
+
+// command returns the arguments following the uv run flags for a script path or a "-m module" target
+func (x *execution) command(target ...string) ([]string, error) {
+	if !x.instrumented() {
+		return target, nil
+	}
+
+	bootstrapPath, err := x.writeFile("uvgo_bootstrap.py", x.bootstrap())
+	if err != nil {
+		return nil, err
+	}
+	return append([]string{"python", bootstrapPath}, target...), nil
+}
+
+// bootstrap renders the Python wrapper that runs the instrumentation around the target script
+func (x *execution) bootstrap() string {
+	var b strings.Builder
+	b.WriteString(bootstrapHeader)
+
+	for _, s := range x.setup {
+		b.WriteString(strings.TrimSpace(s))
+		b.WriteString("\n\n")
+	}
+
+	b.WriteString(bootstrapRun)
+
+	if len(x.teardown) == 0 {
+		b.WriteString("    pass\n")
+	}
+	for _, s := range x.teardown {
+		for _, line := range strings.Split(strings.TrimSpace(s), "\n") {
+			b.WriteString("    " + line + "\n")
+		}
+	}
+	return b.String()
+}
+
+// result runs the collectors registered by the instrumentation against a finished result
+func (x *execution) result(result *Result) error {
+	var errs []error
+	for _, collect := range x.collect {
+		errs = append(errs, collect(result))
+	}
+	return errors.Join(errs...)
+}
+
+// cleanup releases the resources held by the instrumentation and removes the execution's temporary directory
+func (x *execution) cleanup() {
+	for _, release := range x.release {
+		release()
+	}
+	if x.dir != "" {
+		os.RemoveAll(x.dir)
+	}
+}
+
+const bootstrapHeader = `import os
+import runpy
+import sys
+
+_uvgo_target = sys.argv[1]
+if _uvgo_target == "-m":
+    sys.argv = sys.argv[2:]
+    sys.path[0] = os.getcwd()
+else:
+    sys.argv = sys.argv[1:]
+    sys.path[0] = os.path.dirname(os.path.abspath(_uvgo_target))
+
+`
+
+const bootstrapRun = `try:
+    if _uvgo_target == "-m":
+        _uvgo_globals = runpy.run_module(sys.argv[0], run_name="__main__", alter_sys=True)
+    else:
+        _uvgo_globals = runpy.run_path(_uvgo_target, run_name="__main__")
+finally:
+`
+
+// instrument applies the runner's instrumentation options to an execution
+func (r *Runner) instrument(x *execution) error {
+	if r.coverage {
+		if err := x.withCoverage(); err != nil {
+			return err
+		}
+	}
+	if r.profileTop > 0 {
+		if err := x.withProfile(r.profileTop); err != nil {
+			return err
+		}
+	}
+	if r.memoryTop > 0 {
+		if err := x.withMemoryProfile(r.memoryTop); err != nil {
+			return err
+		}
+	}
+	if len(r.inputFiles) > 0 || len(r.inputFS) > 0 {
+		if err := x.withInputs(r.inputFiles, r.inputFS); err != nil {
+			return err
+		}
+	}
+	if r.artifacts {
+		if err := x.withArtifacts(r.maxArtifact); err != nil {
+			return err
+		}
+	}
+	if len(r.plotFormats) > 0 {
+		if err := x.withPlots(r.plotFormats); err != nil {
+			return err
+		}
+	}
+	if r.bridge {
+		if err := x.withBridge(r.onMessage); err != nil {
+			return err
+		}
+	}
+	if r.envSnapshot {
+		if err := x.withEnvSnapshot(); err != nil {
+			return err
+		}
+	}
+	if r.pythonLogger != nil {
+		x.withPythonLogging(r.pythonLogger)
+	}
+	if r.warnings {
+		x.withWarnings(r.warningFilters)
+	}
+	if r.blobs {
+		x.withBlobs()
+	}
+	if r.input != nil {
+		if err := x.withInput(r.input); err != nil {
+			return err
+		}
+	}
+	if r.sharedInputs != nil {
+		if err := x.withSharedMemory(r.sharedInputs); err != nil {
+			return err
+		}
+	}
+	if len(r.callbacks) > 0 && x.scriptRun {
+		if err := x.withCallbacks(); err != nil {
+			return err
+		}
+	}
+	if len(r.helperModules) > 0 || len(r.helperFS) > 0 {
+		if err := x.withHelpers(r.helperModules, r.helperFS); err != nil {
+			return err
+		}
+	}
+	if r.licenses {
+		if err := x.withLicenses(r.allowedLicenses); err != nil {
+			return err
+		}
+	}
+	if r.timings {
+		if err := x.withTimings(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,156 @@
+package uvgo
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"time"
+)
+
+// Invocation describes a single uv process to be executed by an Executor
+type Invocation struct {
+	// Args are the arguments passed to uv, not including the executable itself
+	Args []string
+	// Env holds the variables added to the executor's base environment
+	Env []string
+	// Dir is the working directory, empty meaning the executor's default
+	Dir string
+	// Script is the local path of the script file referenced by Args, empty when the script is read from Stdin
+	Script string
+	// TempDir is the run's local temporary directory, empty when the run needs none. Args, Env and Dir may
+	// reference files inside it, and files written to it while the process runs are collected afterwards, so
+	// executors running uv on another machine must copy it there and back.
+	TempDir string
+	Stdin   io.Reader
+	Stdout  io.Writer
+	Stderr  io.Writer
+	// InteractiveStdin reports that Stdin carries replies to the script's requests, such as those of
+	// WithCallback, so it only ends with the run and must be streamed to the process rather than read up front
+	InteractiveStdin bool
+	// TTY requests stdout be attached to a pseudo-terminal, which executors unable to provide one ignore
+	TTY bool
+	// ExtraFiles are passed to the process as file descriptors 3 and up, which only executors running uv as a
+	// local subprocess support
+	ExtraFiles []*os.File
+	// Started, when set, is called once the process is running with the ID of the local process standing for
+	// it, which the runner signals to stop or forward signals to, or with 0 when no local process does, such
+	// as when the process runs on a remote server
+	Started func(pid int)
+}
+
+// ExitStatus describes a finished uv process
+type ExitStatus struct {
+	ExitCode   int
+	SystemTime time.Duration
+	UserTime   time.Duration
+}
+
+// ExitError reports a uv process that exited unsuccessfully
+type ExitError struct {
+	// Code is the exit code, or -1 when the process was terminated by a signal
+	Code int
+	Err  error
+}
+
+func (e *ExitError) Error() string {
+	if e.Err != nil {
+		return e.Err.Error()
+	}
+	return fmt.Sprintf("exit status %d", e.Code)
+}
+
+func (e *ExitError) Unwrap() error {
+	return e.Err
+}
+
+// Executor runs uv processes on behalf of a Runner. Execute must return an *ExitError when the process ran
+// but exited unsuccessfully, and may return a nil status only when the process could not be started.
+//
+// Wherever the process runs, Execute must write its output to Stdout and Stderr as it is produced rather than
+// once it exits, so streaming options such as WithLineHandler behave the same on every executor, and must stop
+// the process when ctx is cancelled, reporting it as killed with exit code -1.
+type Executor interface {
+	Execute(ctx context.Context, inv *Invocation) (*ExitStatus, error)
+}
+
+// WithExecutor sets the executor used to run uv processes, replacing local execution
+func WithExecutor(executor Executor) Option {
+	return func(r *Runner) { r.executor = executor }
+}
+
+// LocalExecutor runs uv as a subprocess of the current process
+type LocalExecutor struct {
+	// Path is the uv executable, defaulting to "uv" looked up in PATH
+	Path string
+}
+
+// Execute implements Executor
+func (e LocalExecutor) Execute(ctx context.Context, inv *Invocation) (*ExitStatus, error) {
+	path := e.Path
+	if path == "" {
+		path = "uv"
+	}
+
+	cmd := exec.CommandContext(ctx, path, inv.Args...)
+	cmd.Dir = inv.Dir
+	if len(inv.Env) > 0 {
+		cmd.Env = append(os.Environ(), inv.Env...)
+	}
+	cmd.Stdin = inv.Stdin
+	cmd.Stdout = inv.Stdout
+	cmd.Stderr = inv.Stderr
+	cmd.ExtraFiles = inv.ExtraFiles
+
+	var pty *ptyOutput
+	if inv.TTY {
+		var err error
+		if pty, err = attachPTY(cmd, inv.Stdout); err != nil {
+			return nil, err
+		}
+		defer pty.close()
+	}
+
+	if err := cmd.Start(); err != nil {
+		return nil, err
+	}
+	if pty != nil {
+		pty.started()
+	}
+	if inv.Started != nil {
+		inv.Started(cmd.Process.Pid)
+	}
+
+	err := cmd.Wait()
+	if pty != nil {
+		pty.close()
+	}
+	if cmd.ProcessState == nil {
+		return &ExitStatus{ExitCode: -1}, err
+	}
+
+	status := &ExitStatus{
+		ExitCode:   cmd.ProcessState.ExitCode(),
+		SystemTime: cmd.ProcessState.SystemTime(),
+		UserTime:   cmd.ProcessState.UserTime(),
+	}
+
+	var exitError *exec.ExitError
+	if errors.As(err, &exitError) {
+		return status, &ExitError{Code: exitError.ExitCode(), Err: err}
+	}
+	return status, err
+}
+
+// localExecutor returns the LocalExecutor an executor is, whether held as a value or a pointer
+func localExecutor(e Executor) (LocalExecutor, bool) {
+	switch local := e.(type) {
+	case LocalExecutor:
+		return local, true
+	case *LocalExecutor:
+		return *local, local != nil
+	}
+	return LocalExecutor{}, false
+}
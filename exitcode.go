@@ -0,0 +1,95 @@
+package uvgo
+
+import "syscall"
+
+// ExitCategory is the meaning of a process exit code under the conventions of uv, Python and the shell
+type ExitCategory int
+
+const (
+	// ExitSuccess is exit code 0
+	ExitSuccess ExitCategory = iota
+	// ExitFailure is a failure code chosen by the script, such as sys.exit(3)
+	ExitFailure
+	// ExitException is exit code 1, used by Python for uncaught exceptions
+	ExitException
+	// ExitUsage is exit code 2, used by Python and argparse for invalid command lines
+	ExitUsage
+	// ExitUVError is exit code 2 when uv itself failed, which only a Result's stderr can tell from ExitUsage
+	ExitUVError
+	// ExitFlushError is exit code 120, used by Python when flushing its output failed at shutdown
+	ExitFlushError
+	// ExitNotExecutable is exit code 126, used by shells when a command cannot be executed
+	ExitNotExecutable
+	// ExitNotFound is exit code 127, used by shells when a command does not exist
+	ExitNotFound
+	// ExitSignal means the process was ended by a signal, reported as -1 or as 128 plus the signal number
+	ExitSignal
+)
+
+func (c ExitCategory) String() string {
+	switch c {
+	case ExitSuccess:
+		return "success"
+	case ExitException:
+		return "uncaught exception"
+	case ExitUsage:
+		return "usage error"
+	case ExitUVError:
+		return "uv error"
+	case ExitFlushError:
+		return "output flush error"
+	case ExitNotExecutable:
+		return "not executable"
+	case ExitNotFound:
+		return "command not found"
+	case ExitSignal:
+		return "killed by signal"
+	default:
+		return "failure"
+	}
+}
+
+// ClassifyExit returns the category of an exit code
+func ClassifyExit(code int) ExitCategory {
+	switch {
+	case code == 0:
+		return ExitSuccess
+	case code == 1:
+		return ExitException
+	case code == 2:
+		return ExitUsage
+	case code == 120:
+		return ExitFlushError
+	case code == 126:
+		return ExitNotExecutable
+	case code == 127:
+		return ExitNotFound
+	case code < 0:
+		return ExitSignal
+	}
+	if _, ok := ExitCodeSignal(code); ok {
+		return ExitSignal
+	}
+	return ExitFailure
+}
+
+// ExitCodeSignal returns the signal a shell-style exit code of 128 plus the signal number reports, such as
+// SIGKILL for 137, which container runtimes also report for processes killed when out of memory
+func ExitCodeSignal(code int) (syscall.Signal, bool) {
+	if code > 128 && code <= 128+64 {
+		return syscall.Signal(code - 128), true
+	}
+	return 0, false
+}
+
+// ExitCategory returns the category of the run's exit code, telling uv failures from usage errors by stderr
+func (r *Result) ExitCategory() ExitCategory {
+	category := ClassifyExit(r.ExitCode)
+	if category == ExitUsage && ParseUVError(r.Stderr) != nil {
+		return ExitUVError
+	}
+	if r.Signal != nil {
+		return ExitSignal
+	}
+	return category
+}
@@ -0,0 +1,199 @@
+package uvgo
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"os"
+	"path/filepath"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// GRPCOptions configures a Python gRPC server launched with ServeGRPC
+type GRPCOptions struct {
+	// Network is "tcp" to listen on an allocated localhost port, or "unix" to listen on a unix socket.
+	// It defaults to "tcp".
+	Network string
+	// ReadyTimeout bounds how long to wait for the server to accept connections, defaulting to 30 seconds
+	ReadyTimeout time.Duration
+	// RestartDelay is the pause before restarting a server that exited, defaulting to 1 second
+	RestartDelay time.Duration
+	// Args are passed to the script
+	Args []string
+}
+
+// GRPCSidecar is a Python gRPC server kept running by the runner, restarted at the same address whenever it
+// exits. Connect to it with grpc.NewClient(sidecar.Target, ...), or pass grpc.WithContextDialer(sidecar.Dial).
+type GRPCSidecar struct {
+	// Addr is the host:port or socket path the server listens on
+	Addr string
+	// Target is the gRPC dial target, such as "127.0.0.1:50051" or "unix:///tmp/server.sock"
+	Target string
+
+	network string
+	dir     string
+	cancel  context.CancelFunc
+	done    chan struct{}
+
+	mu       sync.Mutex
+	server   *Server
+	restarts int
+	lastErr  error
+}
+
+// ServeGRPC launches a Python gRPC server script and waits until it accepts connections. The script must
+// listen on the address in the UVGO_GRPC_ADDRESS environment variable, which can be passed directly to
+// server.add_insecure_port. The server is restarted after it exits until Close is called or ctx is cancelled.
+func (r *Runner) ServeGRPC(ctx context.Context, scriptPath string, opts GRPCOptions) (*GRPCSidecar, error) {
+	if _, err := os.Stat(scriptPath); os.IsNotExist(err) {
+		return nil, fmt.Errorf("script file does not exist: %w", err)
+	}
+	if opts.RestartDelay <= 0 {
+		opts.RestartDelay = time.Second
+	}
+
+	g := &GRPCSidecar{done: make(chan struct{})}
+
+	var grpcAddr string
+	switch opts.Network {
+	case "", "tcp":
+		port, err := freePort()
+		if err != nil {
+			return nil, err
+		}
+		g.network = "tcp"
+		g.Addr = net.JoinHostPort("127.0.0.1", strconv.Itoa(port))
+		g.Target = g.Addr
+		grpcAddr = g.Addr
+	case "unix":
+		dir, err := os.MkdirTemp("", "uvgo-grpc-*")
+		if err != nil {
+			return nil, fmt.Errorf("failed to create socket directory: %w", err)
+		}
+		g.network = "unix"
+		g.dir = dir
+		g.Addr = filepath.Join(dir, "server.sock")
+		g.Target = "unix://" + g.Addr
+		grpcAddr = "unix:" + g.Addr
+	default:
+		return nil, fmt.Errorf("unsupported network: %q", opts.Network)
+	}
+
+	serveOpts := ServeOptions{Network: g.network, ReadyTimeout: opts.ReadyTimeout}
+	spec := serveSpec{
+		command: func(*Server) []string { return append([]string{scriptPath}, opts.Args...) },
+		addr:    g.Addr,
+		env:     []string{"UVGO_GRPC_ADDRESS=" + grpcAddr},
+		probe: func(ctx context.Context, _ *Server) bool {
+			conn, err := g.Dial(ctx, "")
+			if err != nil {
+				return false
+			}
+			conn.Close()
+			return true
+		},
+	}
+
+	ctx, cancel := context.WithCancel(ctx)
+	g.cancel = cancel
+
+	server, err := r.serve(ctx, serveOpts, spec)
+	if err != nil {
+		cancel()
+		g.removeDir()
+		return nil, err
+	}
+	g.server = server
+
+	go g.supervise(ctx, opts.RestartDelay, func() (*Server, error) {
+		return r.serve(ctx, serveOpts, spec)
+	})
+	return g, nil
+}
+
+// supervise restarts the server whenever it exits until ctx is cancelled
+func (g *GRPCSidecar) supervise(ctx context.Context, delay time.Duration, start func() (*Server, error)) {
+	defer close(g.done)
+
+	for {
+		g.mu.Lock()
+		server := g.server
+		g.mu.Unlock()
+
+		if server != nil {
+			select {
+			case <-server.Done():
+			case <-ctx.Done():
+			}
+			server.Close()
+
+			g.mu.Lock()
+			g.server = nil
+			g.lastErr = server.Err()
+			g.mu.Unlock()
+		}
+
+		select {
+		case <-time.After(delay):
+		case <-ctx.Done():
+			return
+		}
+
+		server, err := start()
+
+		g.mu.Lock()
+		if err != nil {
+			g.lastErr = err
+		} else {
+			g.server = server
+			g.restarts++
+		}
+		g.mu.Unlock()
+	}
+}
+
+// Dial connects to the server, matching the signature expected by grpc.WithContextDialer
+func (g *GRPCSidecar) Dial(ctx context.Context, _ string) (net.Conn, error) {
+	var d net.Dialer
+	return d.DialContext(ctx, g.network, g.Addr)
+}
+
+// Restarts returns the number of times the server was restarted
+func (g *GRPCSidecar) Restarts() int {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	return g.restarts
+}
+
+// Err returns the error the server last exited or failed to restart with
+func (g *GRPCSidecar) Err() error {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	return g.lastErr
+}
+
+// Stderr returns the most recent standard error of the running server
+func (g *GRPCSidecar) Stderr() string {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	if g.server == nil {
+		return ""
+	}
+	return g.server.Stderr()
+}
+
+// Close stops the server and waits for it to exit
+func (g *GRPCSidecar) Close() error {
+	g.cancel()
+	<-g.done
+	g.removeDir()
+	return nil
+}
+
+func (g *GRPCSidecar) removeDir() {
+	if g.dir != "" {
+		os.RemoveAll(g.dir)
+	}
+}
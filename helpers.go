@@ -0,0 +1,90 @@
+package uvgo
+
+import (
+	"fmt"
+	"io/fs"
+	"maps"
+	"os"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"strings"
+)
+
+// ProtocolModule is Python source combining the helpers of ProgressSnippet, BlobSnippet, ChannelSnippet,
+// SchemaSnippet and ProtoSnippet, for registering once as a helper module instead of prepending snippets to
+// every script:
+//
+//	uvgo.WithHelperModule("uvgo_protocol", uvgo.ProtocolModule)
+const ProtocolModule = ProgressSnippet + "\n\n" + BlobSnippet + "\n\n" + ChannelSnippet + "\n\n" + SchemaSnippet + "\n\n" + ProtoSnippet
+
+// WithHelperModule makes a Python module with the given source importable by every script under name, such as
+// "common_io" or the dotted name of a module in a package, such as "helpers.io", replacing any helper module
+// registered under the same name
+func WithHelperModule(name, source string) Option {
+	return func(r *Runner) {
+		modules := maps.Clone(r.helperModules)
+		if modules == nil {
+			modules = make(map[string]string)
+		}
+		modules[name] = source
+		r.helperModules = modules
+	}
+}
+
+// WithHelperFS makes the Python modules and packages at the root of fsys, such as an embed.FS of shared
+// utilities, importable by every script
+func WithHelperFS(fsys fs.FS) Option {
+	return func(r *Runner) { r.helperFS = append(r.helperFS, fsys) }
+}
+
+// moduleNamePattern matches a dotted Python module name
+var moduleNamePattern = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*(\.[A-Za-z_][A-Za-z0-9_]*)*$`)
+
+const helpersSetup = `
+sys.path.append(os.environ["UVGO_HELPER_LIB"])
+`
+
+// withHelpers stages the helper modules into a directory added to the script's module search path
+func (x *execution) withHelpers(modules map[string]string, fsyss []fs.FS) error {
+	lib, err := x.tempPath("helpers")
+	if err != nil {
+		return err
+	}
+	if err := os.Mkdir(lib, 0o755); err != nil {
+		return fmt.Errorf("failed to create helper directory: %w", err)
+	}
+
+	for _, fsys := range fsyss {
+		if err := copyFS(lib, fsys); err != nil {
+			return fmt.Errorf("failed to stage helper modules: %w", err)
+		}
+	}
+
+	for _, name := range slices.Sorted(maps.Keys(modules)) {
+		if !moduleNamePattern.MatchString(name) {
+			return fmt.Errorf("invalid helper module name %q", name)
+		}
+		parts := strings.Split(name, ".")
+		// the parent packages of a dotted module need an __init__.py unless the staged files provide one
+		for i := 1; i < len(parts); i++ {
+			pkg := filepath.Join(append([]string{lib}, parts[:i]...)...)
+			if err := os.MkdirAll(pkg, 0o755); err != nil {
+				return fmt.Errorf("failed to stage helper module %s: %w", name, err)
+			}
+			if init := filepath.Join(pkg, "__init__.py"); !fileExists(init) {
+				if err := os.WriteFile(init, nil, 0o644); err != nil {
+					return fmt.Errorf("failed to stage helper module %s: %w", name, err)
+				}
+			}
+		}
+		path := filepath.Join(append([]string{lib}, parts...)...) + ".py"
+		if err := os.WriteFile(path, []byte(modules[name]), 0o644); err != nil {
+			return fmt.Errorf("failed to stage helper module %s: %w", name, err)
+		}
+	}
+
+	x.env = append(x.env, "UVGO_HELPER_LIB="+lib)
+	x.setup = append(x.setup, helpersSetup)
+	return nil
+}
@@ -0,0 +1,209 @@
+package uvgo
+
+import (
+	"bufio"
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"os"
+	"sync"
+	"time"
+)
+
+// historyOutputLimit is the number of bytes of each output stream kept in a run record
+const historyOutputLimit = 4096
+
+// RunRecord represents a single script run kept in the history
+type RunRecord struct {
+	// ID is the ID of the run's RunRequest, shared with its events
+	ID            string        `json:"id"`
+	Time          time.Time     `json:"time"`
+	ScriptPath    string        `json:"script_path,omitempty"`
+	ScriptHash    string        `json:"script_hash"`
+	Args          []string      `json:"args,omitempty"`
+	Dependencies  []string      `json:"dependencies,omitempty"`
+	PythonVersion string        `json:"python_version,omitempty"`
+	Duration      time.Duration `json:"duration"`
+	ExitCode      int           `json:"exit_code"`
+	Error         string        `json:"error,omitempty"`
+	Stdout        string        `json:"stdout,omitempty"`
+	Stderr        string        `json:"stderr,omitempty"`
+}
+
+// HistoryQuery filters the records returned from a history store
+type HistoryQuery struct {
+	ScriptHash string
+	Since      time.Time
+	Until      time.Time
+	FailedOnly bool
+	// Limit caps the number of records returned, keeping the most recent ones
+	Limit int
+}
+
+// Match reports whether a record satisfies the query filters
+func (q HistoryQuery) Match(record RunRecord) bool {
+	if q.ScriptHash != "" && record.ScriptHash != q.ScriptHash {
+		return false
+	}
+	if !q.Since.IsZero() && record.Time.Before(q.Since) {
+		return false
+	}
+	if !q.Until.IsZero() && record.Time.After(q.Until) {
+		return false
+	}
+	if q.FailedOnly && record.Error == "" {
+		return false
+	}
+	return true
+}
+
+// HistoryStore persists run records, such as FileHistory or the bbolt database of the bolthistory package
+type HistoryStore interface {
+	Append(ctx context.Context, record RunRecord) error
+	Query(ctx context.Context, q HistoryQuery) ([]RunRecord, error)
+}
+
+// FileHistory is a HistoryStore that appends records to a JSON lines file. Every query reads the whole file, so
+// it suits small histories; the bolthistory package provides a store indexing its records for large ones.
+type FileHistory struct {
+	mu   sync.Mutex
+	path string
+}
+
+// NewFileHistory creates a history store backed by the file at path, creating it if needed
+func NewFileHistory(path string) (*FileHistory, error) {
+	f, err := os.OpenFile(path, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0o600)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open history file: %w", err)
+	}
+	if err := f.Close(); err != nil {
+		return nil, fmt.Errorf("failed to open history file: %w", err)
+	}
+	return &FileHistory{path: path}, nil
+}
+
+// Append adds a record to the end of the history file
+func (h *FileHistory) Append(ctx context.Context, record RunRecord) error {
+	data, err := json.Marshal(record)
+	if err != nil {
+		return fmt.Errorf("failed to marshal run record: %w", err)
+	}
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	f, err := os.OpenFile(h.path, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0o600)
+	if err != nil {
+		return fmt.Errorf("failed to open history file: %w", err)
+	}
+
+	if _, err := f.Write(append(data, '\n')); err != nil {
+		f.Close()
+		return fmt.Errorf("failed to write run record: %w", err)
+	}
+	return f.Close()
+}
+
+// Query returns the records matching q in the order they were recorded
+func (h *FileHistory) Query(ctx context.Context, q HistoryQuery) ([]RunRecord, error) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	f, err := os.Open(h.path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open history file: %w", err)
+	}
+	defer f.Close()
+
+	var records []RunRecord
+
+	scanner := bufio.NewScanner(f)
+	scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+	for scanner.Scan() {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+
+		var record RunRecord
+		if err := json.Unmarshal(scanner.Bytes(), &record); err != nil {
+			return nil, fmt.Errorf("failed to unmarshal run record: %w", err)
+		}
+		if q.Match(record) {
+			records = append(records, record)
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read history file: %w", err)
+	}
+
+	if q.Limit > 0 && len(records) > q.Limit {
+		records = records[len(records)-q.Limit:]
+	}
+	return records, nil
+}
+
+// record stores a finished run in the runner's history under the ID of its request, which its events, logs and
+// traces also carry
+func (r *Runner) record(ctx context.Context, req *RunRequest, result *Result, runErr error) error {
+	scriptPath, args := req.ScriptPath, req.Args
+	hash, err := scriptHash(scriptPath, req.Script)
+	if err != nil {
+		return fmt.Errorf("failed to record run history: %w", err)
+	}
+	if scriptPath == "-" {
+		scriptPath = ""
+	}
+
+	if len(args) == 0 {
+		args = r.scriptArgs
+	}
+
+	record := RunRecord{
+		ID:            req.ID,
+		Time:          time.Now(),
+		ScriptPath:    scriptPath,
+		ScriptHash:    hash,
+		Args:          args,
+		Dependencies:  r.dependencies,
+		PythonVersion: r.pythonVersion,
+	}
+
+	if result != nil {
+		record.Duration = result.WallTime
+		record.ExitCode = result.ExitCode
+		record.Stdout = truncate(result.Stdout, historyOutputLimit)
+		record.Stderr = truncate(result.Stderr, historyOutputLimit)
+	}
+	if runErr != nil {
+		record.Error = runErr.Error()
+	}
+
+	if err := r.history.Append(ctx, record); err != nil {
+		return fmt.Errorf("failed to record run history: %w", err)
+	}
+	return nil
+}
+
+// hashScript returns the hex encoded SHA-256 digest of a script
+func hashScript(script string) string {
+	sum := sha256.Sum256([]byte(script))
+	return hex.EncodeToString(sum[:])
+}
+
+// newID returns a random hex encoded identifier
+func newID() string {
+	b := make([]byte, 16)
+	rand.Read(b)
+	return hex.EncodeToString(b)
+}
+
+// truncate cuts s to at most n bytes
+func truncate(s string, n int) string {
+	if len(s) <= n {
+		return s
+	}
+	return s[:n]
+}
@@ -0,0 +1,139 @@
+package uvgo
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"image"
+	_ "image/gif"
+	_ "image/jpeg"
+	_ "image/png"
+	"net/http"
+)
+
+// Image is an encoded image a script produced
+type Image struct {
+	Name string
+	// Format is the format detected from the data: "png", "jpeg", "gif", "webp", "bmp" or "svg"
+	Format string
+	Data   []byte
+}
+
+// Decode decodes the image, which must be a PNG, JPEG or GIF image
+func (i *Image) Decode() (image.Image, error) {
+	img, _, err := image.Decode(bytes.NewReader(i.Data))
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode %s image %s: %w", i.Format, i.Name, err)
+	}
+	return img, nil
+}
+
+// Images returns the images among the script's artifacts, blobs and rendered figures, in that order. Artifacts
+// cut off at the size limit are skipped, and figures are named "figure-<number>.<format>".
+func (r *Result) Images() []Image {
+	var images []Image
+	for _, artifact := range r.Artifacts {
+		if format := imageFormat(artifact.Data); format != "" && !artifact.Truncated {
+			images = append(images, Image{Name: artifact.Name, Format: format, Data: artifact.Data})
+		}
+	}
+	for _, blob := range r.Blobs {
+		if format := imageFormat(blob.Data); format != "" {
+			images = append(images, Image{Name: blob.Name, Format: format, Data: blob.Data})
+		}
+	}
+	for _, figure := range r.Figures {
+		if format := imageFormat(figure.Data); format != "" {
+			name := fmt.Sprintf("figure-%d.%s", figure.Number, figure.Format)
+			images = append(images, Image{Name: name, Format: format, Data: figure.Data})
+		}
+	}
+	return images
+}
+
+// Image returns the image with the given name
+func (r *Result) Image(name string) (*Image, bool) {
+	for _, img := range r.Images() {
+		if img.Name == name {
+			return &img, true
+		}
+	}
+	return nil, false
+}
+
+// imageFormat detects the format of encoded image data, returning "" for anything else
+func imageFormat(data []byte) string {
+	switch http.DetectContentType(data) {
+	case "image/png":
+		return "png"
+	case "image/jpeg":
+		return "jpeg"
+	case "image/gif":
+		return "gif"
+	case "image/webp":
+		return "webp"
+	case "image/bmp":
+		return "bmp"
+	}
+	head := data[:min(len(data), 1024)]
+	if bytes.Contains(head, []byte("<svg")) {
+		return "svg"
+	}
+	return ""
+}
+
+// ImageSnippet is Python source defining a save_image function that returns a PIL image, a matplotlib figure or
+// encoded image bytes to the Go runner, saved into the output directory of WithArtifacts when it is set and
+// sent as a blob otherwise
+const ImageSnippet = `import base64
+import io
+import json
+import os
+import sys
+
+
+def save_image(name, image, format="png"):
+    """Return a PIL image, matplotlib figure or encoded image bytes to the Go runner under name."""
+    if isinstance(image, (bytes, bytearray, memoryview)):
+        data = bytes(image)
+    else:
+        buf = io.BytesIO()
+        if hasattr(image, "savefig"):
+            image.savefig(buf, format=format)
+        else:
+            image.save(buf, format="JPEG" if format.lower() == "jpg" else format.upper())
+        data = buf.getvalue()
+
+    if not os.path.splitext(name)[1]:
+        name += "." + format.lower()
+    output_dir = os.environ.get("UVGO_OUTPUT_DIR")
+    if output_dir is not None:
+        path = os.path.join(output_dir, name)
+        os.makedirs(os.path.dirname(path), exist_ok=True)
+        with open(path, "wb") as f:
+            f.write(data)
+        return path
+
+    entry = {"name": name, "data": base64.b64encode(data).decode()}
+    sys.stderr.write("@uvgo:blob " + json.dumps(entry) + "\n")
+    sys.stderr.flush()
+    return None
+`
+
+// ImageOutput runs a script with artifacts and blobs enabled and returns the images it produced, such as those
+// returned with the save_image function of ImageSnippet, as listed by Result.Images
+func ImageOutput(ctx context.Context, r *Runner, scriptPath string, args ...string) (*StructuredResult[[]Image], error) {
+	var options []Option
+	if !r.artifacts {
+		options = append(options, WithArtifacts(0))
+	}
+	if !r.blobs {
+		options = append(options, WithBlobs())
+	}
+
+	result, err := r.With(options...).Run(ctx, scriptPath, args...)
+	if err != nil {
+		return &StructuredResult[[]Image]{Result: result}, err
+	}
+	return &StructuredResult[[]Image]{Result: result, Data: result.Images()}, nil
+}
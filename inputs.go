@@ -0,0 +1,83 @@
+package uvgo
+
+import (
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+)
+
+// withInputs stages input files into a temporary working directory used as the script's working directory
+func (x *execution) withInputs(files []string, fsyss []fs.FS) error {
+	workDir, err := x.tempPath("work")
+	if err != nil {
+		return err
+	}
+	if err := os.Mkdir(workDir, 0o755); err != nil {
+		return fmt.Errorf("failed to create working directory: %w", err)
+	}
+
+	for _, src := range files {
+		if err := linkOrCopy(src, filepath.Join(workDir, filepath.Base(src))); err != nil {
+			return fmt.Errorf("failed to stage input file %s: %w", src, err)
+		}
+	}
+
+	for _, fsys := range fsyss {
+		if err := copyFS(workDir, fsys); err != nil {
+			return fmt.Errorf("failed to stage input files: %w", err)
+		}
+	}
+
+	x.workDir = workDir
+	return nil
+}
+
+func linkOrCopy(src, dst string) error {
+	if err := os.Link(src, dst); err == nil {
+		return nil
+	}
+
+	in, err := os.Open(src)
+	if err != nil {
+		return err
+	}
+	defer in.Close()
+
+	return writeFrom(dst, in)
+}
+
+func copyFS(dir string, fsys fs.FS) error {
+	return fs.WalkDir(fsys, ".", func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+
+		dst := filepath.Join(dir, filepath.FromSlash(path))
+		if d.IsDir() {
+			return os.MkdirAll(dst, 0o755)
+		}
+
+		in, err := fsys.Open(path)
+		if err != nil {
+			return err
+		}
+		defer in.Close()
+
+		return writeFrom(dst, in)
+	})
+}
+
+func writeFrom(dst string, r io.Reader) error {
+	out, err := os.OpenFile(dst, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, 0o644)
+	if err != nil {
+		return err
+	}
+
+	if _, err := io.Copy(out, r); err != nil {
+		out.Close()
+		return err
+	}
+	return out.Close()
+}
@@ -0,0 +1,74 @@
+package uvgo
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"iter"
+)
+
+// RunIter runs a generator-style script that writes one JSON value per line to stdout and yields each value as
+// soon as its line is written. Output is pulled as the loop consumes it, so a slow consumer slows the script
+// down rather than buffering, and breaking out of the loop terminates the script. A run failure or a line
+// that does not decode is yielded as the final error.
+func RunIter[T any](ctx context.Context, r *Runner, scriptPath string, args ...string) iter.Seq2[T, error] {
+	return func(yield func(T, error) bool) {
+		ctx, cancel := context.WithCancel(ctx)
+		defer cancel()
+
+		pr, pw := io.Pipe()
+		sink := io.Writer(pw)
+		if r.stdoutSink != nil {
+			sink = io.MultiWriter(pw, r.stdoutSink)
+		}
+
+		done := make(chan error, 1)
+		go func() {
+			_, err := r.With(WithOutputSinks(sink, r.stderrSink), WithUnbuffered()).Run(ctx, scriptPath, args...)
+			pw.Close()
+			done <- err
+		}()
+
+		// stop terminates the script and unblocks its output so the run can finish
+		stop := func() {
+			cancel()
+			pr.Close()
+			<-done
+		}
+
+		scanner := bufio.NewScanner(pr)
+		scanner.Buffer(make([]byte, 64*1024), 64*1024*1024)
+		for line := 1; scanner.Scan(); line++ {
+			data := bytes.TrimSpace(scanner.Bytes())
+			if len(data) == 0 {
+				continue
+			}
+
+			var item T
+			if err := json.Unmarshal(data, &item); err != nil {
+				stop()
+				var zero T
+				yield(zero, &PhaseError{Phase: PhaseOutput, Err: fmt.Errorf("failed to unmarshal line %d: %w", line, err)})
+				return
+			}
+			if !yield(item, nil) {
+				stop()
+				return
+			}
+		}
+		if err := scanner.Err(); err != nil {
+			stop()
+			var zero T
+			yield(zero, &PhaseError{Phase: PhaseOutput, Err: fmt.Errorf("failed to read script output: %w", err)})
+			return
+		}
+
+		if err := <-done; err != nil {
+			var zero T
+			yield(zero, err)
+		}
+	}
+}
@@ -0,0 +1,394 @@
+package uvgo
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"os/exec"
+	"path"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+// stderrMarker prefixes the stderr lines of a Kubernetes run, since container logs merge both streams
+const stderrMarker = "\x1e"
+
+// podRoot is the writable directory holding the staged files of a Kubernetes run
+const podRoot = "/uvgo/work"
+
+// KubernetesExecutor runs uv in a Kubernetes Job for every run through the kubectl command. The script, its
+// standard input and the run's temporary directory are delivered in a ConfigMap, so they are limited to about
+// 1 MiB, and the Job is followed through its logs until it completes. Files written to the temporary directory
+// are not copied back, so artifacts and instrumentation results are not collected, and the image must provide
+// a POSIX shell besides uv.
+type KubernetesExecutor struct {
+	// Namespace is the namespace of the Job, defaulting to the kubectl context namespace
+	Namespace string
+	// Image is the container image, which must provide uv
+	Image string
+	// UVPath is the uv executable inside the container, defaulting to "uv"
+	UVPath string
+	// PodTemplate is a pod template in YAML or JSON, with metadata and spec, the Job pods are based on. The
+	// container named "uvgo", or the first container, runs the script and is added when missing.
+	PodTemplate string
+	// Requests and Limits set the container resources, such as {"cpu": "500m", "memory": "1Gi"}
+	Requests map[string]string
+	Limits   map[string]string
+	// ServiceAccount is the service account the pods run as
+	ServiceAccount string
+	// StartTimeout bounds how long to wait for the pod to start running, defaulting to 5 minutes
+	StartTimeout time.Duration
+	// Kubeconfig and Context select the cluster, defaulting to the kubectl configuration
+	Kubeconfig string
+	Context    string
+	// KubectlPath is the kubectl executable, defaulting to "kubectl" looked up in PATH
+	KubectlPath string
+}
+
+// Execute implements Executor
+func (e KubernetesExecutor) Execute(ctx context.Context, inv *Invocation) (*ExitStatus, error) {
+	name := "uvgo-" + newID()[:16]
+
+	manifest, err := e.manifest(name, inv)
+	if err != nil {
+		return nil, err
+	}
+	if err := e.kubectl(ctx, bytes.NewReader(manifest), nil, "apply", "-f", "-"); err != nil {
+		return nil, fmt.Errorf("failed to create job: %w", err)
+	}
+	defer e.delete(name)
+
+	startTimeout := e.StartTimeout
+	if startTimeout <= 0 {
+		startTimeout = 5 * time.Minute
+	}
+
+	out := &demuxWriter{stdout: inv.Stdout, stderr: inv.Stderr}
+	logs := e.command(ctx, "logs", "--follow", "job/"+name, "--pod-running-timeout="+startTimeout.String())
+	logs.Stdout = out
+	var logErr bytes.Buffer
+	logs.Stderr = &logErr
+
+	if err := logs.Start(); err != nil {
+		return nil, err
+	}
+	if inv.Started != nil {
+		inv.Started(logs.Process.Pid)
+	}
+	waitErr := logs.Wait()
+	out.flush()
+
+	if ctx.Err() != nil {
+		return &ExitStatus{ExitCode: -1}, &ExitError{Code: -1, Err: ctx.Err()}
+	}
+	if waitErr != nil {
+		return nil, fmt.Errorf("failed to follow job logs: %w: %s", waitErr, strings.TrimSpace(logErr.String()))
+	}
+
+	code, err := e.exitCode(ctx, name)
+	if err != nil {
+		return nil, err
+	}
+	status := &ExitStatus{ExitCode: code}
+	if code != 0 {
+		return status, &ExitError{Code: code}
+	}
+	return status, nil
+}
+
+// manifest renders the ConfigMap and Job of a run
+func (e KubernetesExecutor) manifest(name string, inv *Invocation) ([]byte, error) {
+	files := map[string][]byte{}
+	// ConfigMaps only hold files, so the directories of the temporary directory are recreated by the script
+	var dirs []string
+	if inv.TempDir != "" {
+		err := filepath.WalkDir(inv.TempDir, func(p string, d fs.DirEntry, err error) error {
+			if err != nil {
+				return err
+			}
+			rel, err := filepath.Rel(inv.TempDir, p)
+			if err != nil {
+				return err
+			}
+			name := path.Join("run", filepath.ToSlash(rel))
+			switch {
+			case d.IsDir():
+				dirs = append(dirs, name)
+			case d.Type().IsRegular():
+				files[name], err = os.ReadFile(p)
+			}
+			return err
+		})
+		if err != nil {
+			return nil, fmt.Errorf("failed to read execution directory: %w", err)
+		}
+	}
+	scriptPath := path.Join("script", filepath.Base(inv.Script))
+	if inv.Script != "" {
+		content, err := os.ReadFile(inv.Script)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read script file: %w", err)
+		}
+		files[scriptPath] = content
+	}
+	if inv.Stdin != nil {
+		stdin, err := io.ReadAll(inv.Stdin)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read stdin: %w", err)
+		}
+		files["stdin"] = stdin
+	}
+
+	data := map[string][]byte{}
+	var items []map[string]any
+	for rel, content := range files {
+		key := fmt.Sprintf("f%d", len(items))
+		data[key] = content
+		items = append(items, map[string]any{"key": key, "path": rel})
+	}
+
+	template := map[string]any{}
+	if e.PodTemplate != "" {
+		if err := yaml.Unmarshal([]byte(e.PodTemplate), &template); err != nil {
+			return nil, fmt.Errorf("failed to parse pod template: %w", err)
+		}
+	}
+	spec := childMap(template, "spec")
+	spec["restartPolicy"] = "Never"
+	if e.ServiceAccount != "" {
+		spec["serviceAccountName"] = e.ServiceAccount
+	}
+	spec["volumes"] = append(childList(spec, "volumes"),
+		map[string]any{"name": "uvgo-files", "configMap": map[string]any{"name": name, "items": items}},
+		map[string]any{"name": "uvgo-work", "emptyDir": map[string]any{}},
+	)
+
+	container := podContainer(spec)
+	if e.Image != "" {
+		container["image"] = e.Image
+	}
+	container["command"] = []string{"sh", "-c", e.script(inv, scriptPath, dirs)}
+	container["volumeMounts"] = append(childList(container, "volumeMounts"),
+		map[string]any{"name": "uvgo-files", "mountPath": "/uvgo/files", "readOnly": true},
+		map[string]any{"name": "uvgo-work", "mountPath": podRoot},
+	)
+	if len(e.Requests) > 0 || len(e.Limits) > 0 {
+		resources := childMap(container, "resources")
+		if len(e.Requests) > 0 {
+			resources["requests"] = e.Requests
+		}
+		if len(e.Limits) > 0 {
+			resources["limits"] = e.Limits
+		}
+	}
+
+	list := map[string]any{
+		"apiVersion": "v1",
+		"kind":       "List",
+		"items": []any{
+			map[string]any{
+				"apiVersion": "v1",
+				"kind":       "ConfigMap",
+				"metadata":   e.metadata(name),
+				"binaryData": data,
+			},
+			map[string]any{
+				"apiVersion": "batch/v1",
+				"kind":       "Job",
+				"metadata":   e.metadata(name),
+				"spec": map[string]any{
+					"backoffLimit": 0,
+					"template":     template,
+				},
+			},
+		},
+	}
+	return json.Marshal(list)
+}
+
+// script returns the shell command the container runs, tagging stderr lines and preserving the uv exit code
+func (e KubernetesExecutor) script(inv *Invocation, scriptPath string, dirs []string) string {
+	local := stagedPaths(inv, path.Join(podRoot, "run"), path.Join(podRoot, scriptPath))
+
+	uvPath := e.UVPath
+	if uvPath == "" {
+		uvPath = "uv"
+	}
+	var uv strings.Builder
+	uv.WriteString("env")
+	for _, kv := range inv.Env {
+		uv.WriteString(" " + shellQuote(local(kv)))
+	}
+	uv.WriteString(" " + shellQuote(uvPath))
+	for _, arg := range inv.Args {
+		uv.WriteString(" " + shellQuote(local(arg)))
+	}
+	if inv.Stdin != nil {
+		uv.WriteString(" < " + podRoot + "/stdin")
+	}
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "cp -R /uvgo/files/. %s/ && mkdir -p %s/run", podRoot, podRoot)
+	for _, dir := range dirs {
+		b.WriteString(" " + shellQuote(path.Join(podRoot, dir)))
+	}
+	if inv.Dir != "" {
+		fmt.Fprintf(&b, " && cd %s", shellQuote(local(inv.Dir)))
+	}
+	// stderr lines are tagged by a shell loop rather than sed, which would buffer them until the run ends
+	fmt.Fprintf(&b, " || exit 125\nexec 3>&1\nstatus=$( { { %s; echo $? >&4; } 2>&1 1>&3 | "+
+		"while IFS= read -r line || [ -n \"$line\" ]; do printf '%s%%s\\n' \"$line\"; done >&3; } 4>&1 )\nexit $status\n",
+		uv.String(), stderrMarker)
+	return b.String()
+}
+
+func (e KubernetesExecutor) metadata(name string) map[string]any {
+	metadata := map[string]any{"name": name, "labels": map[string]string{"app.kubernetes.io/managed-by": "uvgo"}}
+	if e.Namespace != "" {
+		metadata["namespace"] = e.Namespace
+	}
+	return metadata
+}
+
+// exitCode waits for the pod of a job to terminate and returns the exit code of the script container
+func (e KubernetesExecutor) exitCode(ctx context.Context, name string) (int, error) {
+	ctx, cancel := context.WithTimeout(ctx, time.Minute)
+	defer cancel()
+
+	for {
+		var out bytes.Buffer
+		err := e.kubectl(ctx, nil, &out, "get", "pods", "-l", "job-name="+name, "-o",
+			`jsonpath={.items[0].status.containerStatuses[?(@.state.terminated)].state.terminated.exitCode}`)
+		if err == nil {
+			if code, err := strconv.Atoi(strings.TrimSpace(out.String())); err == nil {
+				return code, nil
+			}
+		}
+
+		select {
+		case <-ctx.Done():
+			return 0, fmt.Errorf("failed to read job exit code: %w", ctx.Err())
+		case <-time.After(500 * time.Millisecond):
+		}
+	}
+}
+
+// delete removes the job, its pods and the ConfigMap of a run
+func (e KubernetesExecutor) delete(name string) {
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+	e.kubectl(ctx, nil, nil, "delete", "job/"+name, "configmap/"+name, "--cascade=background", "--wait=false", "--ignore-not-found")
+}
+
+func (e KubernetesExecutor) kubectl(ctx context.Context, stdin io.Reader, stdout io.Writer, args ...string) error {
+	var stderr bytes.Buffer
+	cmd := e.command(ctx, args...)
+	cmd.Stdin = stdin
+	cmd.Stdout = stdout
+	cmd.Stderr = &stderr
+	if err := cmd.Run(); err != nil {
+		return fmt.Errorf("%w: %s", err, strings.TrimSpace(stderr.String()))
+	}
+	return nil
+}
+
+func (e KubernetesExecutor) command(ctx context.Context, args ...string) *exec.Cmd {
+	kubectlPath := e.KubectlPath
+	if kubectlPath == "" {
+		kubectlPath = "kubectl"
+	}
+
+	var global []string
+	if e.Kubeconfig != "" {
+		global = append(global, "--kubeconfig", e.Kubeconfig)
+	}
+	if e.Context != "" {
+		global = append(global, "--context", e.Context)
+	}
+	if e.Namespace != "" {
+		global = append(global, "--namespace", e.Namespace)
+	}
+	return exec.CommandContext(ctx, kubectlPath, append(global, args...)...)
+}
+
+// childMap returns the map stored under key in m, creating it when missing
+func childMap(m map[string]any, key string) map[string]any {
+	child, ok := m[key].(map[string]any)
+	if !ok {
+		child = map[string]any{}
+		m[key] = child
+	}
+	return child
+}
+
+// childList returns the list stored under key in m
+func childList(m map[string]any, key string) []any {
+	list, _ := m[key].([]any)
+	return list
+}
+
+// podContainer returns the container named "uvgo" of a pod spec, falling back to the first container and
+// adding one when the spec has none
+func podContainer(spec map[string]any) map[string]any {
+	containers := childList(spec, "containers")
+	for _, c := range containers {
+		if c, ok := c.(map[string]any); ok && c["name"] == "uvgo" {
+			return c
+		}
+	}
+	if len(containers) > 0 {
+		if c, ok := containers[0].(map[string]any); ok {
+			return c
+		}
+	}
+
+	container := map[string]any{"name": "uvgo"}
+	spec["containers"] = append(containers, container)
+	return container
+}
+
+// demuxWriter splits merged container logs into stdout and stderr by the stderr marker of each line
+type demuxWriter struct {
+	stdout io.Writer
+	stderr io.Writer
+	buf    []byte
+}
+
+func (d *demuxWriter) Write(p []byte) (int, error) {
+	d.buf = append(d.buf, p...)
+	for {
+		i := bytes.IndexByte(d.buf, '\n')
+		if i < 0 {
+			break
+		}
+		d.line(d.buf[:i+1])
+		d.buf = d.buf[i+1:]
+	}
+	return len(p), nil
+}
+
+func (d *demuxWriter) line(line []byte) {
+	w := d.stdout
+	if rest, ok := bytes.CutPrefix(line, []byte(stderrMarker)); ok {
+		w, line = d.stderr, rest
+	}
+	if w != nil {
+		w.Write(line)
+	}
+}
+
+// flush writes a final line not terminated by a newline
+func (d *demuxWriter) flush() {
+	if len(d.buf) > 0 {
+		d.line(d.buf)
+		d.buf = nil
+	}
+}
@@ -0,0 +1,74 @@
+package uvgo
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestDemuxWriter(t *testing.T) {
+	tests := []struct {
+		name   string
+		writes []string
+		stdout string
+		stderr string
+	}{
+		{
+			name:   "stdout only",
+			writes: []string{"one\ntwo\n"},
+			stdout: "one\ntwo\n",
+		},
+		{
+			name:   "interleaved",
+			writes: []string{"out\n" + stderrMarker + "err\nmore out\n"},
+			stdout: "out\nmore out\n",
+			stderr: "err\n",
+		},
+		{
+			name:   "lines split across writes",
+			writes: []string{"par", "tial\n" + stderrMarker, "warn", "ing\n"},
+			stdout: "partial\n",
+			stderr: "warning\n",
+		},
+		{
+			name:   "marker only at line start",
+			writes: []string{"a" + stderrMarker + "b\n"},
+			stdout: "a" + stderrMarker + "b\n",
+		},
+		{
+			name:   "unterminated final line",
+			writes: []string{"done\n" + stderrMarker + "last"},
+			stdout: "done\n",
+			stderr: "last",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var stdout, stderr strings.Builder
+			d := &demuxWriter{stdout: &stdout, stderr: &stderr}
+			for _, w := range tt.writes {
+				if n, err := d.Write([]byte(w)); n != len(w) || err != nil {
+					t.Fatalf("Write(%q) = %d, %v", w, n, err)
+				}
+			}
+			d.flush()
+
+			if stdout.String() != tt.stdout {
+				t.Errorf("stdout = %q, want %q", stdout.String(), tt.stdout)
+			}
+			if stderr.String() != tt.stderr {
+				t.Errorf("stderr = %q, want %q", stderr.String(), tt.stderr)
+			}
+		})
+	}
+}
+
+func TestDemuxWriterNilStream(t *testing.T) {
+	var stdout strings.Builder
+	d := &demuxWriter{stdout: &stdout}
+	d.Write([]byte("out\n" + stderrMarker + "dropped\n"))
+	d.flush()
+	if stdout.String() != "out\n" {
+		t.Errorf("stdout = %q, want %q", stdout.String(), "out\n")
+	}
+}
@@ -0,0 +1,205 @@
+package uvgo
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+)
+
+// PackageLicense is the license metadata of a distribution installed in the environment a script ran in
+type PackageLicense struct {
+	Name    string `json:"name"`
+	Version string `json:"version"`
+	// Expression is the SPDX license expression the package declares, or its License field when it is short
+	Expression string `json:"expression"`
+	// Licenses lists the licenses named by the expression, the License field or the trove classifiers, such as "MIT"
+	Licenses []string `json:"licenses"`
+}
+
+// Allows reports whether the package is available under one of the allowed licenses, compared case-insensitively.
+// A package that declares no license is never allowed.
+func (p PackageLicense) Allows(allowed []string) bool {
+	for _, license := range p.Licenses {
+		for _, a := range allowed {
+			if strings.EqualFold(license, a) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// LicenseReport lists the licenses of the packages in a script's environment
+type LicenseReport struct {
+	Packages []PackageLicense
+	// Violations lists the packages available under none of the allowed licenses, empty without a policy
+	Violations []PackageLicense
+}
+
+// OK reports whether every package satisfies the license policy
+func (l *LicenseReport) OK() bool {
+	return len(l.Violations) == 0
+}
+
+// LicenseError is returned when packages in a script's environment violate the allowed-license policy
+type LicenseError struct {
+	Violations []PackageLicense
+}
+
+func (e *LicenseError) Error() string {
+	packages := make([]string, len(e.Violations))
+	for i, p := range e.Violations {
+		license := p.Expression
+		if license == "" {
+			license = strings.Join(p.Licenses, ", ")
+		}
+		if license == "" {
+			license = "no license"
+		}
+		packages[i] = fmt.Sprintf("%s %s (%s)", p.Name, p.Version, license)
+	}
+	return "packages violate the license policy: " + strings.Join(packages, ", ")
+}
+
+// WithLicenses records the license of every package in the environment a script runs in, sorted by name, in
+// Result.Licenses. With allowed licenses, such as "MIT" or "Apache-2.0", the run fails with a *LicenseError
+// before the script starts when a package is available under none of them.
+func WithLicenses(allowed ...string) Option {
+	return func(r *Runner) {
+		r.licenses = true
+		r.allowedLicenses = allowed
+	}
+}
+
+// Licenses reports the licenses of the packages installed with the runner's dependencies, checked against the
+// licenses allowed by WithLicenses, without failing on violations
+func (r *Runner) Licenses(ctx context.Context) (*LicenseReport, error) {
+	allowed := r.allowedLicenses
+	result, err := r.With(func(c *Runner) {
+		c.licenses = true
+		c.allowedLicenses = nil
+	}).RunFromString(ctx, "pass")
+	if err != nil {
+		return nil, err
+	}
+
+	report := &LicenseReport{Packages: result.Licenses}
+	if len(allowed) > 0 {
+		report.Violations = licenseViolations(result.Licenses, allowed)
+	}
+	return report, nil
+}
+
+// licenseViolations returns the packages available under none of the allowed licenses
+func licenseViolations(packages []PackageLicense, allowed []string) []PackageLicense {
+	var violations []PackageLicense
+	for _, p := range packages {
+		if !p.Allows(allowed) {
+			violations = append(violations, p)
+		}
+	}
+	return violations
+}
+
+const licenseSetup = `
+import importlib.metadata as _uvgo_metadata
+import json as _uvgo_json
+import re as _uvgo_re
+
+_uvgo_classifier_licenses = {
+    "MIT License": "MIT",
+    "ISC License (ISCL)": "ISC",
+    "The Unlicense (Unlicense)": "Unlicense",
+    "Python Software Foundation License": "PSF-2.0",
+    "Mozilla Public License 2.0 (MPL 2.0)": "MPL-2.0",
+    "GNU General Public License v2 (GPLv2)": "GPL-2.0-only",
+    "GNU General Public License v3 (GPLv3)": "GPL-3.0-only",
+    "GNU Lesser General Public License v3 (LGPLv3)": "LGPL-3.0-only",
+    "GNU Affero General Public License v3": "AGPL-3.0-only",
+}
+
+
+def _uvgo_license(dist):
+    meta = dist.metadata
+    expression = (meta.get("License-Expression") or "").strip()
+    licenses = []
+    if expression:
+        exception = False
+        for token in _uvgo_re.split(r"[()\s]+", expression):
+            if token.upper() in ("AND", "OR") or not token:
+                continue
+            if token.upper() == "WITH":
+                exception = True
+            elif exception:
+                exception = False
+            else:
+                licenses.append(token)
+    else:
+        for classifier in meta.get_all("Classifier") or []:
+            parts = [p.strip() for p in classifier.split("::")]
+            if parts[0] == "License" and len(parts) > 1 and parts[-1] != "OSI Approved":
+                licenses.append(_uvgo_classifier_licenses.get(parts[-1], parts[-1]))
+        field = (meta.get("License") or "").strip()
+        if field and "\n" not in field and len(field) <= 100 and field.upper() != "UNKNOWN":
+            expression = field
+            if field not in licenses:
+                licenses.append(field)
+    return {"name": meta["Name"], "version": dist.version, "expression": expression, "licenses": licenses}
+
+
+_uvgo_licenses = {}
+for _uvgo_dist in _uvgo_metadata.distributions():
+    if _uvgo_dist.metadata["Name"]:
+        _uvgo_licenses.setdefault(_uvgo_dist.metadata["Name"].lower(), _uvgo_license(_uvgo_dist))
+_uvgo_licenses = [_uvgo_licenses[name] for name in sorted(_uvgo_licenses)]
+with open(os.environ["UVGO_LICENSES"], "w") as _uvgo_f:
+    _uvgo_json.dump(_uvgo_licenses, _uvgo_f)
+
+if os.environ.get("UVGO_ALLOWED_LICENSES"):
+    _uvgo_allowed = {l.lower() for l in _uvgo_json.loads(os.environ["UVGO_ALLOWED_LICENSES"])}
+    for _uvgo_entry in _uvgo_licenses:
+        if not any(l.lower() in _uvgo_allowed for l in _uvgo_entry["licenses"]):
+            # the runner reports the violations from the written licenses, so the script is skipped cleanly
+            raise SystemExit(0)
+`
+
+// withLicenses lists the licenses of the installed distributions before the script starts and attaches them
+// to the result, skipping the script when a package violates the allowed licenses
+func (x *execution) withLicenses(allowed []string) error {
+	licensesPath, err := x.tempPath("licenses.json")
+	if err != nil {
+		return err
+	}
+
+	x.env = append(x.env, "UVGO_LICENSES="+licensesPath)
+	if len(allowed) > 0 {
+		data, err := json.Marshal(allowed)
+		if err != nil {
+			return fmt.Errorf("failed to encode allowed licenses: %w", err)
+		}
+		x.env = append(x.env, "UVGO_ALLOWED_LICENSES="+string(data))
+	}
+	x.setup = append(x.setup, licenseSetup)
+	x.collect = append(x.collect, func(result *Result) error {
+		data, err := os.ReadFile(licensesPath)
+		if os.IsNotExist(err) {
+			return nil
+		}
+		if err != nil {
+			return fmt.Errorf("failed to read licenses: %w", err)
+		}
+		if err := json.Unmarshal(data, &result.Licenses); err != nil {
+			return fmt.Errorf("failed to parse licenses: %w", err)
+		}
+		if len(allowed) == 0 {
+			return nil
+		}
+		if violations := licenseViolations(result.Licenses, allowed); len(violations) > 0 {
+			return &LicenseError{Violations: violations}
+		}
+		return nil
+	})
+	return nil
+}
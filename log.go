@@ -0,0 +1,111 @@
+package uvgo
+
+import (
+	"context"
+	"log/slog"
+	"net/url"
+	"strings"
+)
+
+const redacted = "[REDACTED]"
+
+// secretMarkers are substrings of environment variable names whose values are never logged
+var secretMarkers = []string{"SECRET", "TOKEN", "PASSWORD", "PASSWD", "KEY", "CREDENTIAL", "AUTH"}
+
+func (r *Runner) logCommand(ctx context.Context, runID string, inv *Invocation) {
+	if r.logger == nil {
+		return
+	}
+	r.logger.LogAttrs(ctx, slog.LevelDebug, "uv command built", append(runAttrs(runID),
+		slog.Any("args", redactArgs(inv.Args)),
+		slog.String("dir", inv.Dir),
+		slog.Any("env", redactEnv(inv.Env)),
+	)...)
+}
+
+func (r *Runner) logStarted(ctx context.Context, runID string, pid int) {
+	if r.logger == nil {
+		return
+	}
+	r.logger.LogAttrs(ctx, slog.LevelDebug, "uv command started", append(runAttrs(runID), slog.Int("pid", pid))...)
+}
+
+func (r *Runner) logFinished(ctx context.Context, runID string, result *Result, err error) {
+	if r.logger == nil {
+		return
+	}
+
+	attrs := runAttrs(runID)
+	if result != nil {
+		attrs = append(attrs,
+			slog.Int("exit_code", result.ExitCode),
+			slog.Duration("duration", result.WallTime),
+			slog.Int("stdout_bytes", len(result.Stdout)),
+			slog.Int("stderr_bytes", len(result.Stderr)),
+		)
+	}
+
+	if err != nil {
+		attrs = append(attrs, slog.String("error", err.Error()))
+		r.logger.LogAttrs(ctx, slog.LevelWarn, "uv command failed", attrs...)
+		return
+	}
+	r.logger.LogAttrs(ctx, slog.LevelInfo, "uv command finished", attrs...)
+}
+
+// runAttrs returns the attributes identifying the run a uv command belongs to, matching the ID of its events
+// and history record, or none for commands outside a run
+func runAttrs(runID string) []slog.Attr {
+	if runID == "" {
+		return []slog.Attr{}
+	}
+	return []slog.Attr{slog.String("run_id", runID)}
+}
+
+// redactEnv hides the values of environment variables that look like secrets
+func redactEnv(env []string) []string {
+	out := make([]string, len(env))
+	for i, kv := range env {
+		key, value, _ := strings.Cut(kv, "=")
+		if isSecretKey(key) {
+			value = redacted
+		}
+		out[i] = key + "=" + redactURL(value)
+	}
+	return out
+}
+
+// redactArgs hides credentials embedded in URL arguments such as private index URLs
+func redactArgs(args []string) []string {
+	out := make([]string, len(args))
+	for i, arg := range args {
+		if key, value, ok := strings.Cut(arg, "="); ok && strings.HasPrefix(key, "--") {
+			out[i] = key + "=" + redactURL(value)
+			continue
+		}
+		out[i] = redactURL(arg)
+	}
+	return out
+}
+
+func redactURL(s string) string {
+	if !strings.Contains(s, "://") {
+		return s
+	}
+	u, err := url.Parse(s)
+	if err != nil || u.User == nil {
+		return s
+	}
+	u.User = url.User(redacted)
+	return u.String()
+}
+
+func isSecretKey(key string) bool {
+	upper := strings.ToUpper(key)
+	for _, marker := range secretMarkers {
+		if strings.Contains(upper, marker) {
+			return true
+		}
+	}
+	return false
+}
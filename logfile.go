@@ -0,0 +1,223 @@
+package uvgo
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+)
+
+// RotateOptions configures when a RotatingFile starts a new file
+type RotateOptions struct {
+	// MaxSize rotates the file before a write would grow it beyond this many bytes, when positive
+	MaxSize int64
+	// MaxAge rotates the file once it has been open for this long, when positive
+	MaxAge time.Duration
+	// MaxBackups is the number of rotated files kept, keeping all of them when zero
+	MaxBackups int
+}
+
+// RotatingFile is an io.WriteCloser appending to a log file that is rotated by size or age. Rotated files are
+// renamed with a timestamp inserted before the extension, such as "script-20240102T150405.000.log".
+type RotatingFile struct {
+	mu     sync.Mutex
+	path   string
+	opts   RotateOptions
+	file   *os.File
+	size   int64
+	opened time.Time
+}
+
+// NewRotatingFile opens the log file at path for appending, creating it and its directory if needed
+func NewRotatingFile(path string, opts RotateOptions) (*RotatingFile, error) {
+	f := &RotatingFile{path: path, opts: opts}
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return nil, fmt.Errorf("failed to create log directory: %w", err)
+	}
+	if err := f.open(); err != nil {
+		return nil, err
+	}
+	return f, nil
+}
+
+// Write implements io.Writer
+func (f *RotatingFile) Write(p []byte) (int, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if f.file == nil {
+		return 0, os.ErrClosed
+	}
+	if f.due(int64(len(p))) {
+		if err := f.rotate(); err != nil {
+			return 0, err
+		}
+	}
+
+	n, err := f.file.Write(p)
+	f.size += int64(n)
+	return n, err
+}
+
+// Rotate starts a new file immediately
+func (f *RotatingFile) Rotate() error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if f.file == nil {
+		return os.ErrClosed
+	}
+	return f.rotate()
+}
+
+// Close closes the current file
+func (f *RotatingFile) Close() error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if f.file == nil {
+		return nil
+	}
+	err := f.file.Close()
+	f.file = nil
+	return err
+}
+
+// due reports whether the file must be rotated before writing n more bytes
+func (f *RotatingFile) due(n int64) bool {
+	if f.size == 0 {
+		return false
+	}
+	if f.opts.MaxSize > 0 && f.size+n > f.opts.MaxSize {
+		return true
+	}
+	return f.opts.MaxAge > 0 && time.Since(f.opened) >= f.opts.MaxAge
+}
+
+func (f *RotatingFile) open() error {
+	file, err := os.OpenFile(f.path, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0o644)
+	if err != nil {
+		return fmt.Errorf("failed to open log file: %w", err)
+	}
+	info, err := file.Stat()
+	if err != nil {
+		file.Close()
+		return fmt.Errorf("failed to open log file: %w", err)
+	}
+
+	f.file = file
+	f.size = info.Size()
+	f.opened = time.Now()
+	return nil
+}
+
+func (f *RotatingFile) rotate() error {
+	if err := f.file.Close(); err != nil {
+		return fmt.Errorf("failed to close log file: %w", err)
+	}
+	f.file = nil
+
+	// two rotations within a millisecond would share a name, so the later one moves to the next free one
+	t := time.Now()
+	for fileExists(f.backupName(t)) {
+		t = t.Add(time.Millisecond)
+	}
+	if err := os.Rename(f.path, f.backupName(t)); err != nil {
+		return fmt.Errorf("failed to rotate log file: %w", err)
+	}
+	if err := f.open(); err != nil {
+		return err
+	}
+	return f.prune()
+}
+
+// backupLayout is the timestamp layout of rotated file names, which sorts lexically in time order
+const backupLayout = "20060102T150405.000"
+
+func (f *RotatingFile) backupName(t time.Time) string {
+	ext := filepath.Ext(f.path)
+	return strings.TrimSuffix(f.path, ext) + "-" + t.Format(backupLayout) + ext
+}
+
+// prune removes the oldest rotated files beyond MaxBackups
+func (f *RotatingFile) prune() error {
+	if f.opts.MaxBackups <= 0 {
+		return nil
+	}
+
+	ext := filepath.Ext(f.path)
+	prefix := strings.TrimSuffix(f.path, ext) + "-"
+	matches, err := filepath.Glob(prefix + "*" + ext)
+	if err != nil {
+		return fmt.Errorf("failed to list rotated log files: %w", err)
+	}
+
+	// the glob also matches other files sharing the prefix, such as job-err.log next to job.log
+	var backups []string
+	for _, match := range matches {
+		stamp := strings.TrimSuffix(strings.TrimPrefix(match, prefix), ext)
+		if _, err := time.Parse(backupLayout, stamp); err == nil {
+			backups = append(backups, match)
+		}
+	}
+	slices.Sort(backups)
+	for len(backups) > f.opts.MaxBackups {
+		if err := os.Remove(backups[0]); err != nil {
+			return fmt.Errorf("failed to remove rotated log file: %w", err)
+		}
+		backups = backups[1:]
+	}
+	return nil
+}
+
+// WithOutputSinks writes the output of every run to the given writers, such as a RotatingFile, instead of
+// holding it in memory. Result.Stdout and Result.Stderr then contain only the last 64 KiB of each stream.
+// A nil writer keeps capturing that stream in full.
+func WithOutputSinks(stdout, stderr io.Writer) Option {
+	return func(r *Runner) {
+		r.stdoutSink = stdout
+		r.stderrSink = stderr
+	}
+}
+
+// outputCapture collects a stream of a run for the Result
+type outputCapture interface {
+	io.Writer
+	String() string
+}
+
+// capture returns the capture for a stream, keeping only its tail in memory when it goes to a sink
+func capture(sink io.Writer) outputCapture {
+	if sink == nil {
+		return &bytes.Buffer{}
+	}
+	return &sinkCapture{sink: sink, tail: &tailBuffer{max: serveLogLimit}}
+}
+
+// tailCapture returns the capture for a stream of a long-running process, which only ever keeps its tail
+func tailCapture(sink io.Writer) outputCapture {
+	if sink == nil {
+		return &tailBuffer{max: serveLogLimit}
+	}
+	return &sinkCapture{sink: sink, tail: &tailBuffer{max: serveLogLimit}}
+}
+
+// sinkCapture copies a stream to a sink while keeping its tail
+type sinkCapture struct {
+	sink io.Writer
+	tail *tailBuffer
+}
+
+func (s *sinkCapture) Write(p []byte) (int, error) {
+	s.tail.Write(p)
+	return s.sink.Write(p)
+}
+
+func (s *sinkCapture) String() string {
+	return s.tail.String()
+}
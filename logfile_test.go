@@ -0,0 +1,172 @@
+package uvgo
+
+import (
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+)
+
+// rotatedFiles returns the contents of the rotated files next to path, oldest first
+func rotatedFiles(t *testing.T, path string) []string {
+	t.Helper()
+	ext := filepath.Ext(path)
+	matches, err := filepath.Glob(strings.TrimSuffix(path, ext) + "-*" + ext)
+	if err != nil {
+		t.Fatal(err)
+	}
+	slices.Sort(matches)
+
+	var contents []string
+	for _, match := range matches {
+		data, err := os.ReadFile(match)
+		if err != nil {
+			t.Fatal(err)
+		}
+		contents = append(contents, string(data))
+	}
+	return contents
+}
+
+func TestRotatingFile(t *testing.T) {
+	tests := []struct {
+		name    string
+		opts    RotateOptions
+		writes  []string
+		current string
+		rotated []string
+	}{
+		{
+			name:    "no limits",
+			writes:  []string{"aaaa\n", "bbbb\n", "cccc\n"},
+			current: "aaaa\nbbbb\ncccc\n",
+		},
+		{
+			name:    "rotates before exceeding max size",
+			opts:    RotateOptions{MaxSize: 10},
+			writes:  []string{"aaaa\n", "bbbb\n", "cccc\n"},
+			current: "cccc\n",
+			rotated: []string{"aaaa\nbbbb\n"},
+		},
+		{
+			name:    "oversized write lands in a fresh file",
+			opts:    RotateOptions{MaxSize: 4},
+			writes:  []string{"aaaaaaaa\n", "bbbbbbbb\n"},
+			current: "bbbbbbbb\n",
+			rotated: []string{"aaaaaaaa\n"},
+		},
+		{
+			name:    "prunes beyond max backups",
+			opts:    RotateOptions{MaxSize: 5, MaxBackups: 2},
+			writes:  []string{"aaaa\n", "bbbb\n", "cccc\n", "dddd\n"},
+			current: "dddd\n",
+			rotated: []string{"bbbb\n", "cccc\n"},
+		},
+		{
+			name:    "keeps every backup by default",
+			opts:    RotateOptions{MaxSize: 5},
+			writes:  []string{"aaaa\n", "bbbb\n", "cccc\n", "dddd\n"},
+			current: "dddd\n",
+			rotated: []string{"aaaa\n", "bbbb\n", "cccc\n"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "logs", "job.log")
+			f, err := NewRotatingFile(path, tt.opts)
+			if err != nil {
+				t.Fatal(err)
+			}
+			for _, w := range tt.writes {
+				if _, err := f.Write([]byte(w)); err != nil {
+					t.Fatal(err)
+				}
+			}
+			if err := f.Close(); err != nil {
+				t.Fatal(err)
+			}
+
+			data, err := os.ReadFile(path)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if string(data) != tt.current {
+				t.Errorf("current file = %q, want %q", data, tt.current)
+			}
+			if got := rotatedFiles(t, path); !slices.Equal(got, tt.rotated) {
+				t.Errorf("rotated files = %q, want %q", got, tt.rotated)
+			}
+		})
+	}
+}
+
+func TestRotatingFileAppendsToExisting(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "job.log")
+	if err := os.WriteFile(path, []byte("old\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	f, err := NewRotatingFile(path, RotateOptions{MaxSize: 8})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	if _, err := f.Write([]byte("new\n")); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := f.Write([]byte("next\n")); err != nil {
+		t.Fatal(err)
+	}
+
+	if got := rotatedFiles(t, path); !slices.Equal(got, []string{"old\nnew\n"}) {
+		t.Errorf("rotated files = %q, want the existing content with the first write", got)
+	}
+}
+
+func TestRotatingFilePruneIgnoresSiblings(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "job.log")
+	sibling := filepath.Join(dir, "job-err.log")
+	if err := os.WriteFile(sibling, []byte("keep\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	f, err := NewRotatingFile(path, RotateOptions{MaxBackups: 1})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	for range 3 {
+		if _, err := f.Write([]byte("line\n")); err != nil {
+			t.Fatal(err)
+		}
+		if err := f.Rotate(); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if _, err := os.Stat(sibling); err != nil {
+		t.Errorf("sibling log file was removed: %v", err)
+	}
+	if got := rotatedFiles(t, path); len(got) != 2 {
+		t.Errorf("found %d files matching the rotated pattern, want one backup and the sibling", len(got))
+	}
+}
+
+func TestRotatingFileClosed(t *testing.T) {
+	f, err := NewRotatingFile(filepath.Join(t.TempDir(), "job.log"), RotateOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := f.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := f.Write([]byte("x")); err != os.ErrClosed {
+		t.Errorf("Write after Close error = %v, want %v", err, os.ErrClosed)
+	}
+	if err := f.Rotate(); err != os.ErrClosed {
+		t.Errorf("Rotate after Close error = %v, want %v", err, os.ErrClosed)
+	}
+}
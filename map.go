@@ -0,0 +1,102 @@
+package uvgo
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"runtime"
+	"slices"
+	"strings"
+	"sync"
+)
+
+// ItemError reports the failure of a single input in RunMap or a single spec in a batch
+type ItemError struct {
+	Index int
+	Err   error
+}
+
+func (e ItemError) Error() string {
+	return fmt.Sprintf("run %d: %v", e.Index, e.Err)
+}
+
+func (e ItemError) Unwrap() error {
+	return e.Err
+}
+
+// MapError reports every input of RunMap, or spec of RunAllSettled, that failed, ordered by index
+type MapError struct {
+	Errors []ItemError
+}
+
+func (e *MapError) Error() string {
+	msgs := make([]string, len(e.Errors))
+	for i, err := range e.Errors {
+		msgs[i] = err.Error()
+	}
+	return fmt.Sprintf("%d runs failed: %s", len(e.Errors), strings.Join(msgs, "; "))
+}
+
+func (e *MapError) Unwrap() []error {
+	errs := make([]error, len(e.Errors))
+	for i, err := range e.Errors {
+		errs[i] = err
+	}
+	return errs
+}
+
+// RunMap runs a script once per input, at most GOMAXPROCS at a time, passing each input as JSON in the
+// UVGO_INPUT environment variable and parsing the script output like StructuredOutput. Outputs are returned
+// in input order. When some inputs fail, their outputs are left as zero values and the returned *MapError
+// lists each failure.
+func RunMap[T, R any](ctx context.Context, r *Runner, scriptPath string, inputs []T) ([]R, error) {
+	outputs := make([]R, len(inputs))
+
+	var (
+		mu   sync.Mutex
+		wg   sync.WaitGroup
+		errs []ItemError
+	)
+	fail := func(i int, err error) {
+		mu.Lock()
+		defer mu.Unlock()
+		errs = append(errs, ItemError{Index: i, Err: err})
+	}
+
+	sem := make(chan struct{}, runtime.GOMAXPROCS(0))
+	for i, input := range inputs {
+		data, err := json.Marshal(input)
+		if err != nil {
+			fail(i, fmt.Errorf("failed to marshal input: %w", err))
+			continue
+		}
+
+		select {
+		case sem <- struct{}{}:
+		case <-ctx.Done():
+			fail(i, ctx.Err())
+			continue
+		}
+
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			defer func() { <-sem }()
+
+			runner := r.With(WithInput(data))
+			result, err := StructuredOutput[R](ctx, runner, scriptPath)
+			if err != nil {
+				fail(i, err)
+				return
+			}
+			outputs[i] = result.Data
+		}()
+	}
+	wg.Wait()
+
+	if len(errs) > 0 {
+		slices.SortFunc(errs, func(a, b ItemError) int { return a.Index - b.Index })
+		return outputs, &MapError{Errors: errs}
+	}
+	return outputs, nil
+}
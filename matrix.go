@@ -0,0 +1,102 @@
+package uvgo
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// DefaultPythonVersions are the Python versions RunMatrix covers when none are given
+var DefaultPythonVersions = []string{"3.9", "3.10", "3.11", "3.12", "3.13"}
+
+// MatrixEntry is the outcome of a script under one Python version
+type MatrixEntry struct {
+	Python string
+	Result *Result
+	Err    error
+}
+
+// Passed reports whether the script succeeded under this version
+func (e MatrixEntry) Passed() bool {
+	return e.Err == nil
+}
+
+// Duration returns how long the run took
+func (e MatrixEntry) Duration() time.Duration {
+	if e.Result == nil {
+		return 0
+	}
+	return e.Result.WallTime
+}
+
+// MatrixReport holds the outcome of a script across Python versions, in the order the versions were given
+type MatrixReport struct {
+	Entries []MatrixEntry
+}
+
+// Passed reports whether the script succeeded under every version
+func (m *MatrixReport) Passed() bool {
+	for _, e := range m.Entries {
+		if !e.Passed() {
+			return false
+		}
+	}
+	return true
+}
+
+// Failed returns the entries of the versions the script failed under
+func (m *MatrixReport) Failed() []MatrixEntry {
+	var failed []MatrixEntry
+	for _, e := range m.Entries {
+		if !e.Passed() {
+			failed = append(failed, e)
+		}
+	}
+	return failed
+}
+
+// String summarizes the report with one line per version
+func (m *MatrixReport) String() string {
+	var b strings.Builder
+	for _, e := range m.Entries {
+		status := "ok"
+		if !e.Passed() {
+			status = "FAIL: " + firstLine(e.Err.Error())
+		}
+		fmt.Fprintf(&b, "python %-6s %8v  %s\n", e.Python, e.Duration().Round(time.Millisecond), status)
+	}
+	return b.String()
+}
+
+// RunMatrix runs a script under each Python version concurrently, defaulting to DefaultPythonVersions, and
+// reports the outcome, output and timing of every version. Failures under some versions are recorded in the
+// report rather than returned, so the error is only set when ctx ends before every run finished.
+func (r *Runner) RunMatrix(ctx context.Context, scriptPath string, versions []string, args ...string) (*MatrixReport, error) {
+	if len(versions) == 0 {
+		versions = DefaultPythonVersions
+	}
+
+	specs := make([]RunSpec, len(versions))
+	for i, version := range versions {
+		specs[i] = RunSpec{ScriptPath: scriptPath, Args: args, Options: []Option{WithPython(version)}}
+	}
+
+	report := &MatrixReport{Entries: make([]MatrixEntry, len(versions))}
+	for i, version := range versions {
+		report.Entries[i].Python = version
+	}
+	results := r.runBatch(ctx, specs, func(i int, err error) {
+		report.Entries[i].Err = err
+	})
+	for i, result := range results {
+		report.Entries[i].Result = result
+	}
+	return report, ctx.Err()
+}
+
+// firstLine returns the first line of s
+func firstLine(s string) string {
+	line, _, _ := strings.Cut(s, "\n")
+	return line
+}
@@ -0,0 +1,319 @@
+// Package mcp implements a Model Context Protocol server exposing a tools.Toolset, so AI clients can call
+// Python scripts hosted by a Go service. The server speaks JSON-RPC 2.0 over newline-delimited stdio with
+// Serve, or over HTTP with Handler.
+package mcp
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/joeychilson/uvgo"
+	"github.com/joeychilson/uvgo/tools"
+)
+
+// protocolVersions lists the MCP revisions the server supports, newest first
+var protocolVersions = []string{"2025-03-26", "2024-11-05"}
+
+// JSON-RPC error codes
+const (
+	codeParseError     = -32700
+	codeInvalidRequest = -32600
+	codeMethodNotFound = -32601
+	codeInvalidParams  = -32602
+)
+
+// Limits bound the resources tool calls may use
+type Limits struct {
+	// Timeout bounds each tool call, leaving the runner timeout in place when zero
+	Timeout time.Duration
+	// MaxConcurrent is the number of tool calls run at once, unlimited when zero. Calls beyond it are
+	// rejected as tool errors.
+	MaxConcurrent int
+	// MaxResultBytes rejects tool results larger than this many bytes, when positive
+	MaxResultBytes int
+}
+
+// Server is an MCP server exposing the tools of a toolset
+type Server struct {
+	name    string
+	version string
+	tools   *tools.Toolset
+	limits  Limits
+	slots   chan struct{}
+}
+
+// NewServer creates a server identifying itself with name and version to clients
+func NewServer(name, version string, toolset *tools.Toolset, limits Limits) *Server {
+	s := &Server{name: name, version: version, tools: toolset, limits: limits}
+	if limits.MaxConcurrent > 0 {
+		s.slots = make(chan struct{}, limits.MaxConcurrent)
+	}
+	return s
+}
+
+type request struct {
+	JSONRPC string          `json:"jsonrpc"`
+	ID      json.RawMessage `json:"id,omitempty"`
+	Method  string          `json:"method"`
+	Params  json.RawMessage `json:"params,omitempty"`
+}
+
+type response struct {
+	JSONRPC string          `json:"jsonrpc"`
+	ID      json.RawMessage `json:"id"`
+	Result  any             `json:"result,omitempty"`
+	Error   *rpcError       `json:"error,omitempty"`
+}
+
+type rpcError struct {
+	Code    int    `json:"code"`
+	Message string `json:"message"`
+}
+
+// Serve reads requests from r and writes responses to w, one JSON message per line, until r is exhausted or
+// ctx is cancelled. Requests are handled concurrently and can be cancelled with notifications/cancelled.
+func (s *Server) Serve(ctx context.Context, r io.Reader, w io.Writer) error {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	var (
+		writeMu  sync.Mutex
+		wg       sync.WaitGroup
+		cancelMu sync.Mutex
+		inflight = map[string]context.CancelFunc{}
+	)
+	defer wg.Wait()
+
+	send := func(resp *response) {
+		data, err := json.Marshal(resp)
+		if err != nil {
+			return
+		}
+		writeMu.Lock()
+		defer writeMu.Unlock()
+		w.Write(append(data, '\n'))
+	}
+
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 64*1024), 16*1024*1024)
+	for scanner.Scan() {
+		line := scanner.Bytes()
+		if len(line) == 0 {
+			continue
+		}
+
+		var req request
+		if err := json.Unmarshal(line, &req); err != nil {
+			send(&response{JSONRPC: "2.0", ID: json.RawMessage("null"), Error: &rpcError{codeParseError, err.Error()}})
+			continue
+		}
+
+		if req.Method == "notifications/cancelled" {
+			var params struct {
+				RequestID json.RawMessage `json:"requestId"`
+			}
+			if json.Unmarshal(req.Params, &params) == nil {
+				cancelMu.Lock()
+				if cancelReq, ok := inflight[string(params.RequestID)]; ok {
+					cancelReq()
+				}
+				cancelMu.Unlock()
+			}
+			continue
+		}
+
+		reqCtx, cancelReq := context.WithCancel(ctx)
+		key := string(req.ID)
+		cancelMu.Lock()
+		inflight[key] = cancelReq
+		cancelMu.Unlock()
+
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			defer func() {
+				cancelMu.Lock()
+				delete(inflight, key)
+				cancelMu.Unlock()
+				cancelReq()
+			}()
+
+			if resp := s.handle(reqCtx, &req); resp != nil {
+				send(resp)
+			}
+		}()
+	}
+	if err := scanner.Err(); err != nil {
+		return fmt.Errorf("failed to read request: %w", err)
+	}
+	return ctx.Err()
+}
+
+// Handler returns an http.Handler accepting one JSON-RPC message per POST request
+func (s *Server) Handler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost {
+			w.Header().Set("Allow", http.MethodPost)
+			http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+
+		var req request
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			writeJSON(w, &response{JSONRPC: "2.0", ID: json.RawMessage("null"), Error: &rpcError{codeParseError, err.Error()}})
+			return
+		}
+
+		resp := s.handle(r.Context(), &req)
+		if resp == nil {
+			w.WriteHeader(http.StatusAccepted)
+			return
+		}
+		writeJSON(w, resp)
+	})
+}
+
+func writeJSON(w http.ResponseWriter, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(v)
+}
+
+// handle dispatches a request, returning nil for notifications
+func (s *Server) handle(ctx context.Context, req *request) *response {
+	if req.JSONRPC != "2.0" {
+		return s.errorResponse(req, codeInvalidRequest, "jsonrpc must be 2.0")
+	}
+
+	var (
+		result any
+		err    *rpcError
+	)
+	switch req.Method {
+	case "initialize":
+		result, err = s.initialize(req.Params)
+	case "ping":
+		result = struct{}{}
+	case "tools/list":
+		result = s.listTools()
+	case "tools/call":
+		result, err = s.callTool(ctx, req.Params)
+	default:
+		err = &rpcError{codeMethodNotFound, "method not found: " + req.Method}
+	}
+
+	// notifications, such as notifications/initialized, never get a response
+	if len(req.ID) == 0 {
+		return nil
+	}
+	if err != nil {
+		return &response{JSONRPC: "2.0", ID: req.ID, Error: err}
+	}
+	return &response{JSONRPC: "2.0", ID: req.ID, Result: result}
+}
+
+func (s *Server) errorResponse(req *request, code int, message string) *response {
+	id := req.ID
+	if len(id) == 0 {
+		id = json.RawMessage("null")
+	}
+	return &response{JSONRPC: "2.0", ID: id, Error: &rpcError{code, message}}
+}
+
+func (s *Server) initialize(params json.RawMessage) (any, *rpcError) {
+	var p struct {
+		ProtocolVersion string `json:"protocolVersion"`
+	}
+	if err := json.Unmarshal(params, &p); err != nil {
+		return nil, &rpcError{codeInvalidParams, err.Error()}
+	}
+
+	version := protocolVersions[0]
+	if slices.Contains(protocolVersions, p.ProtocolVersion) {
+		version = p.ProtocolVersion
+	}
+
+	return map[string]any{
+		"protocolVersion": version,
+		"capabilities": map[string]any{
+			"tools": map[string]any{"listChanged": false},
+		},
+		"serverInfo": map[string]any{"name": s.name, "version": s.version},
+	}, nil
+}
+
+type toolInfo struct {
+	Name        string          `json:"name"`
+	Description string          `json:"description,omitempty"`
+	InputSchema json.RawMessage `json:"inputSchema"`
+}
+
+func (s *Server) listTools() any {
+	definitions := s.tools.Definitions()
+	list := make([]toolInfo, len(definitions))
+	for i, d := range definitions {
+		list[i] = toolInfo{Name: d.Name, Description: d.Description, InputSchema: d.InputSchema}
+	}
+	return map[string]any{"tools": list}
+}
+
+type content struct {
+	Type string `json:"type"`
+	Text string `json:"text"`
+}
+
+type callResult struct {
+	Content           []content       `json:"content"`
+	StructuredContent json.RawMessage `json:"structuredContent,omitempty"`
+	IsError           bool            `json:"isError,omitempty"`
+}
+
+// callTool runs a tool, reporting failures of the tool itself as error results the model can see
+func (s *Server) callTool(ctx context.Context, params json.RawMessage) (any, *rpcError) {
+	var p struct {
+		Name      string          `json:"name"`
+		Arguments json.RawMessage `json:"arguments"`
+	}
+	if err := json.Unmarshal(params, &p); err != nil {
+		return nil, &rpcError{codeInvalidParams, err.Error()}
+	}
+
+	if s.slots != nil {
+		select {
+		case s.slots <- struct{}{}:
+			defer func() { <-s.slots }()
+		default:
+			return toolError(uvgo.ErrQueueFull), nil
+		}
+	}
+
+	if s.limits.Timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, s.limits.Timeout)
+		defer cancel()
+	}
+
+	output, err := s.tools.Call(ctx, p.Name, p.Arguments)
+	if err != nil {
+		return toolError(err), nil
+	}
+	if s.limits.MaxResultBytes > 0 && len(output) > s.limits.MaxResultBytes {
+		return toolError(fmt.Errorf("tool result of %d bytes exceeds the limit of %d bytes", len(output), s.limits.MaxResultBytes)), nil
+	}
+
+	result := callResult{Content: []content{{Type: "text", Text: string(output)}}}
+	if len(output) > 0 && output[0] == '{' {
+		result.StructuredContent = output
+	}
+	return result, nil
+}
+
+func toolError(err error) callResult {
+	return callResult{Content: []content{{Type: "text", Text: err.Error()}}, IsError: true}
+}
@@ -0,0 +1,87 @@
+package uvgo
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"strconv"
+)
+
+// MemoryReport represents the memory usage traced by tracemalloc
+type MemoryReport struct {
+	Current     int64            `json:"current"`
+	Peak        int64            `json:"peak"`
+	Allocations []AllocationSite `json:"allocations"`
+}
+
+// AllocationSite represents the memory still allocated from a single source line
+type AllocationSite struct {
+	File  string `json:"file"`
+	Line  int    `json:"line"`
+	Size  int64  `json:"size"`
+	Count int    `json:"count"`
+}
+
+const tracemallocSetup = `
+import tracemalloc as _uvgo_tracemalloc
+_uvgo_tracemalloc.start()
+`
+
+const tracemallocTeardown = `
+_uvgo_current, _uvgo_peak = _uvgo_tracemalloc.get_traced_memory()
+_uvgo_snapshot = _uvgo_tracemalloc.take_snapshot().filter_traces((
+    _uvgo_tracemalloc.Filter(False, __file__),
+    _uvgo_tracemalloc.Filter(False, _uvgo_tracemalloc.__file__),
+    _uvgo_tracemalloc.Filter(False, "<frozen importlib._bootstrap*>"),
+    _uvgo_tracemalloc.Filter(False, "<frozen runpy>"),
+    _uvgo_tracemalloc.Filter(False, "*/runpy.py"),
+))
+_uvgo_tracemalloc.stop()
+import json as _uvgo_json
+with open(os.environ["UVGO_TRACEMALLOC_REPORT"], "w") as _uvgo_f:
+    _uvgo_json.dump({
+        "current": _uvgo_current,
+        "peak": _uvgo_peak,
+        "allocations": [
+            {"file": s.traceback[0].filename, "line": s.traceback[0].lineno, "size": s.size, "count": s.count}
+            for s in _uvgo_snapshot.statistics("lineno")[:int(os.environ["UVGO_TRACEMALLOC_TOP"])]
+        ],
+    }, _uvgo_f)
+`
+
+// withMemoryProfile runs the script under tracemalloc and attaches the top allocation sites to the result
+func (x *execution) withMemoryProfile(top int) error {
+	reportPath, err := x.tempPath("tracemalloc.json")
+	if err != nil {
+		return err
+	}
+
+	x.env = append(x.env, "UVGO_TRACEMALLOC_REPORT="+reportPath, "UVGO_TRACEMALLOC_TOP="+strconv.Itoa(top))
+	x.setup = append(x.setup, tracemallocSetup)
+	x.teardown = append(x.teardown, tracemallocTeardown)
+	x.collect = append(x.collect, func(result *Result) error {
+		report, err := readMemoryReport(reportPath)
+		if err != nil {
+			return err
+		}
+		result.Memory = report
+		return nil
+	})
+	return nil
+}
+
+func readMemoryReport(path string) (*MemoryReport, error) {
+	data, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read memory report: %w", err)
+	}
+
+	var report MemoryReport
+	if err := json.Unmarshal(data, &report); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal memory report: %w", err)
+	}
+	return &report, nil
+}
@@ -0,0 +1,165 @@
+package uvgo
+
+import (
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// Metrics is a prometheus.Collector tracking script execution health
+type Metrics struct {
+	runs      prometheus.Counter
+	failures  *prometheus.CounterVec
+	duration  prometheus.Histogram
+	inFlight  prometheus.Gauge
+	starts    *prometheus.CounterVec
+	cacheHits prometheus.Counter
+}
+
+// NewMetrics creates script execution metrics under the given namespace
+func NewMetrics(namespace string) *Metrics {
+	return &Metrics{
+		runs: prometheus.NewCounter(prometheus.CounterOpts{
+			Namespace: namespace,
+			Subsystem: "uvgo",
+			Name:      "runs_total",
+			Help:      "Total number of script runs.",
+		}),
+		failures: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Subsystem: "uvgo",
+			Name:      "failures_total",
+			Help:      "Total number of failed script runs by failure class.",
+		}, []string{"class"}),
+		duration: prometheus.NewHistogram(prometheus.HistogramOpts{
+			Namespace: namespace,
+			Subsystem: "uvgo",
+			Name:      "run_duration_seconds",
+			Help:      "Wall time of script runs.",
+			Buckets:   prometheus.ExponentialBuckets(0.05, 2, 12),
+		}),
+		inFlight: prometheus.NewGauge(prometheus.GaugeOpts{
+			Namespace: namespace,
+			Subsystem: "uvgo",
+			Name:      "runs_in_flight",
+			Help:      "Number of script runs waiting or executing.",
+		}),
+		starts: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Subsystem: "uvgo",
+			Name:      "environment_starts_total",
+			Help:      "Total number of script runs by whether uv had to install packages first.",
+		}, []string{"kind"}),
+		cacheHits: prometheus.NewCounter(prometheus.CounterOpts{
+			Namespace: namespace,
+			Subsystem: "uvgo",
+			Name:      "cache_hits_total",
+			Help:      "Total number of cold starts satisfied entirely from the uv cache.",
+		}),
+	}
+}
+
+// Describe implements prometheus.Collector
+func (m *Metrics) Describe(ch chan<- *prometheus.Desc) {
+	m.runs.Describe(ch)
+	m.failures.Describe(ch)
+	m.duration.Describe(ch)
+	m.inFlight.Describe(ch)
+	m.starts.Describe(ch)
+	m.cacheHits.Describe(ch)
+}
+
+// Collect implements prometheus.Collector
+func (m *Metrics) Collect(ch chan<- prometheus.Metric) {
+	m.runs.Collect(ch)
+	m.failures.Collect(ch)
+	m.duration.Collect(ch)
+	m.inFlight.Collect(ch)
+	m.starts.Collect(ch)
+	m.cacheHits.Collect(ch)
+}
+
+// WithMetrics records script execution metrics in m
+func WithMetrics(m *Metrics) Option {
+	return func(r *Runner) { r.metrics = m }
+}
+
+// begin records a run entering the runner and returns a function recording its outcome
+func (m *Metrics) begin() func(*Result, error) {
+	if m == nil {
+		return func(*Result, error) {}
+	}
+
+	m.inFlight.Inc()
+	return func(result *Result, err error) {
+		m.inFlight.Dec()
+		m.runs.Inc()
+
+		if err != nil {
+			m.failures.WithLabelValues(failureClass(result)).Inc()
+		}
+		if result == nil {
+			return
+		}
+
+		m.duration.Observe(result.WallTime.Seconds())
+
+		setup := parseSetup(result.Stderr)
+		switch {
+		case setup.installed == 0:
+			m.starts.WithLabelValues("warm").Inc()
+		case setup.prepared == 0:
+			m.starts.WithLabelValues("cold").Inc()
+			m.cacheHits.Inc()
+		default:
+			m.starts.WithLabelValues("cold").Inc()
+		}
+	}
+}
+
+// failureClass buckets a failed run for metric labels
+func failureClass(result *Result) string {
+	switch {
+	case result == nil:
+		return "setup"
+	case result.ExitCode < 0:
+		return "killed"
+	case result.ExitCode > 0:
+		return "exit"
+	default:
+		return "error"
+	}
+}
+
+var (
+	installedPattern = regexp.MustCompile(`(?m)^Installed (\d+) packages? in`)
+	preparedPattern  = regexp.MustCompile(`(?m)^Prepared (\d+) packages? in`)
+	setupTimePattern = regexp.MustCompile(`(?m)^(?:Resolved|Prepared|Installed) \d+ packages? in ([\d.]+\w+(?: [\d.]+\w+)?)`)
+)
+
+// setupStats summarizes the environment setup uv reported on stderr
+type setupStats struct {
+	installed int
+	prepared  int
+	// overhead is the total time uv reported for resolving, preparing and installing packages
+	overhead time.Duration
+}
+
+func parseSetup(stderr string) setupStats {
+	var s setupStats
+	if m := installedPattern.FindStringSubmatch(stderr); m != nil {
+		s.installed, _ = strconv.Atoi(m[1])
+	}
+	if m := preparedPattern.FindStringSubmatch(stderr); m != nil {
+		s.prepared, _ = strconv.Atoi(m[1])
+	}
+	for _, m := range setupTimePattern.FindAllStringSubmatch(stderr, -1) {
+		if d, err := time.ParseDuration(strings.ReplaceAll(m[1], " ", "")); err == nil {
+			s.overhead += d
+		}
+	}
+	return s
+}
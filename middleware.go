@@ -0,0 +1,30 @@
+package uvgo
+
+import (
+	"context"
+	"io"
+)
+
+// RunRequest describes a script execution as seen by middleware
+type RunRequest struct {
+	// ID uniquely identifies the run
+	ID string
+	// ScriptPath is the path of the script file, or "-" when Script holds the script content
+	ScriptPath string
+	Script     string
+	Args       []string
+
+	// inputs are the readers of the run's input channels, which every attempt consumes
+	inputs []io.Reader
+}
+
+// RunFunc executes a script run
+type RunFunc func(ctx context.Context, req *RunRequest) (*Result, error)
+
+// Middleware wraps a RunFunc to add behavior before or after a script run
+type Middleware func(next RunFunc) RunFunc
+
+// WithMiddleware adds middleware around every script run, the first middleware being the outermost
+func WithMiddleware(middleware ...Middleware) Option {
+	return func(r *Runner) { r.middleware = append(r.middleware, middleware...) }
+}
@@ -0,0 +1,198 @@
+package uvgo
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"runtime"
+	"slices"
+	"strings"
+	"time"
+)
+
+// namedEnvFile is the file recording what a named environment was materialized for
+const namedEnvFile = "uvgo-env.json"
+
+// namedEnvLock records the dependency set a named environment was synced to and the packages pinned for it
+type namedEnvLock struct {
+	Python       string   `json:"python"`
+	Dependencies []string `json:"dependencies"`
+	Indexes      []string `json:"indexes"`
+	Requirements string   `json:"requirements"`
+	// Size is the disk usage of the environment in bytes, recorded for the environments of an environment cache
+	Size int64 `json:"size,omitempty"`
+}
+
+// matches reports whether the environment was synced to the same dependency set
+func (l *namedEnvLock) matches(other *namedEnvLock) bool {
+	return l.Python == other.Python && slices.Equal(l.Dependencies, other.Dependencies) && slices.Equal(l.Indexes, other.Indexes)
+}
+
+// WithNamedEnv runs scripts in a persistent virtual environment with the given name, such as "analytics-v3",
+// instead of letting uv set up an environment on every run. The environment is created in the user cache
+// directory on first use and reused across runs and process restarts as long as the lock recorded with it
+// matches the runner's Python version, dependencies and indexes; otherwise it is synced to them before the run.
+// Named environments live on the local filesystem, so they only suit the local executor.
+func WithNamedEnv(name string) Option {
+	return func(r *Runner) { r.namedEnv = name }
+}
+
+// NamedEnvDir returns the directory of the named environment with the given name
+func NamedEnvDir(name string) (string, error) {
+	if name == "" || name != filepath.Base(name) || name == "." || name == ".." {
+		return "", fmt.Errorf("invalid environment name %q", name)
+	}
+	cacheDir, err := os.UserCacheDir()
+	if err != nil {
+		return "", fmt.Errorf("failed to locate cache directory: %w", err)
+	}
+	return filepath.Join(cacheDir, "uvgo", "envs", name), nil
+}
+
+// venvPython returns the path of the interpreter of a virtual environment
+func venvPython(dir string) string {
+	if runtime.GOOS == "windows" {
+		return filepath.Join(dir, "Scripts", "python.exe")
+	}
+	return filepath.Join(dir, "bin", "python")
+}
+
+// persistentEnv reports whether the runner runs scripts in a persistent environment rather than one uv sets up
+func (r *Runner) persistentEnv() bool {
+	return r.namedEnv != "" || r.envCache
+}
+
+// envDir returns the name and directory of the runner's persistent environment, preferring a named environment
+// over the environment cache
+func (r *Runner) envDir() (string, string, error) {
+	if r.namedEnv != "" {
+		dir, err := NamedEnvDir(r.namedEnv)
+		return r.namedEnv, dir, err
+	}
+	digest := r.envDigest()
+	dir, err := envCacheDir()
+	return digest, filepath.Join(dir, digest), err
+}
+
+// envPython returns the interpreter of the runner's persistent environment
+func (r *Runner) envPython() string {
+	_, dir, err := r.envDir()
+	if err != nil {
+		return ""
+	}
+	return venvPython(dir)
+}
+
+// syncEnv materializes the runner's persistent environment, if any, and syncs it to the runner's dependencies
+// unless its lock already matches them. Other processes syncing the same environment wait for each other.
+func (r *Runner) syncEnv(ctx context.Context) error {
+	if !r.persistentEnv() {
+		return nil
+	}
+	name, dir, err := r.envDir()
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(dir), 0o755); err != nil {
+		return fmt.Errorf("failed to create environment directory: %w", err)
+	}
+
+	synced, err := r.syncEnvLocked(ctx, name, dir)
+	if err != nil || !synced || r.namedEnv != "" {
+		return err
+	}
+	// pruning runs once the environment's lock is released, so runners pruning each other's environments
+	// cannot deadlock
+	return pruneEnvCache(filepath.Dir(dir), r.envCacheSize, name)
+}
+
+// syncEnvLocked syncs the environment in dir while holding its lock and reports whether it had to be synced
+func (r *Runner) syncEnvLocked(ctx context.Context, name, dir string) (bool, error) {
+	unlock, err := lockPath(dir + ".lock")
+	if err != nil {
+		return false, fmt.Errorf("failed to lock environment %s: %w", name, err)
+	}
+	defer unlock()
+
+	want := &namedEnvLock{
+		Python:       r.pythonVersion,
+		Dependencies: slices.Sorted(slices.Values(r.dependencies)),
+		Indexes:      r.indexes(),
+	}
+
+	var have namedEnvLock
+	if data, err := os.ReadFile(filepath.Join(dir, namedEnvFile)); err == nil && json.Unmarshal(data, &have) == nil {
+		if fileExists(venvPython(dir)) && have.matches(want) {
+			// the lock's modification time tracks when the environment was last used
+			now := time.Now()
+			os.Chtimes(filepath.Join(dir, namedEnvFile), now, now)
+			return false, nil
+		}
+	}
+
+	if have.Python != want.Python || !fileExists(venvPython(dir)) {
+		if err := r.createVenv(ctx, dir, want.Python); err != nil {
+			return false, fmt.Errorf("failed to create environment %s: %w", name, err)
+		}
+	}
+
+	if len(want.Dependencies) > 0 {
+		requirements, err := r.pipCompile(ctx, want.Dependencies, "--no-annotate", "--python", venvPython(dir))
+		if err != nil {
+			return false, fmt.Errorf("failed to lock environment %s: %w", name, err)
+		}
+		want.Requirements = requirements
+	}
+	if err := r.syncVenv(ctx, dir, want.Requirements); err != nil {
+		return false, fmt.Errorf("failed to sync environment %s: %w", name, err)
+	}
+	if r.namedEnv == "" {
+		if want.Size, err = dirSize(dir); err != nil {
+			return false, fmt.Errorf("failed to measure environment %s: %w", name, err)
+		}
+	}
+	return true, writeNamedEnvLock(dir, want)
+}
+
+// createVenv creates an empty virtual environment in dir, replacing any existing one
+func (r *Runner) createVenv(ctx context.Context, dir, python string) error {
+	if err := os.RemoveAll(dir); err != nil {
+		return err
+	}
+	venvArgs := []string{"venv", dir, "--quiet"}
+	if python != "" {
+		venvArgs = append(venvArgs, "--python", python)
+	}
+	_, err := r.uvOutput(ctx, nil, venvArgs...)
+	return err
+}
+
+// syncVenv makes the packages of the virtual environment in dir match pinned requirements exactly, removing
+// the packages they do not list
+func (r *Runner) syncVenv(ctx context.Context, dir, requirements string, extraArgs ...string) error {
+	syncArgs := []string{"pip", "sync", "-", "--quiet", "--allow-empty-requirements", "--python", venvPython(dir)}
+	syncArgs = append(syncArgs, extraArgs...)
+	syncArgs = append(syncArgs, r.indexArgs()...)
+	_, err := r.uvOutput(ctx, strings.NewReader(requirements), syncArgs...)
+	return err
+}
+
+// writeNamedEnvLock records the dependency set a named environment was synced to
+func writeNamedEnvLock(dir string, lock *namedEnvLock) error {
+	data, err := json.MarshalIndent(lock, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode environment lock: %w", err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, namedEnvFile), data, 0o644); err != nil {
+		return fmt.Errorf("failed to write environment lock: %w", err)
+	}
+	return nil
+}
+
+func fileExists(path string) bool {
+	_, err := os.Stat(path)
+	return !errors.Is(err, os.ErrNotExist)
+}
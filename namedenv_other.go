@@ -0,0 +1,9 @@
+//go:build !unix
+
+package uvgo
+
+// lockPath is a no-op where file locks are unavailable, leaving concurrent syncs of a named environment from
+// several processes unguarded
+func lockPath(path string) (func(), error) {
+	return func() {}, nil
+}
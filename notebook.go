@@ -0,0 +1,181 @@
+package uvgo
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+)
+
+// NotebookResult represents the output of a notebook execution
+type NotebookResult struct {
+	*Result
+	Cells []NotebookCell
+}
+
+// NotebookCell represents a single executed notebook cell
+type NotebookCell struct {
+	Index          int
+	Type           string
+	Source         string
+	ExecutionCount int
+	Outputs        []CellOutput
+}
+
+// CellOutput represents a single output produced by a notebook cell
+type CellOutput struct {
+	// Type is one of stream, execute_result, display_data or error
+	Type string
+	// Name is the stream name for stream outputs
+	Name string
+	// Text is the stream text for stream outputs
+	Text string
+	// Data maps MIME types to their content for rich outputs, binary content stays base64 encoded
+	Data       map[string]string
+	ErrorName  string
+	ErrorValue string
+	Traceback  []string
+}
+
+// Err returns the first error output of the notebook, if any
+func (n *NotebookResult) Err() error {
+	for _, cell := range n.Cells {
+		for _, out := range cell.Outputs {
+			if out.Type == "error" {
+				return fmt.Errorf("cell %d raised %s: %s", cell.Index, out.ErrorName, out.ErrorValue)
+			}
+		}
+	}
+	return nil
+}
+
+type notebookFile struct {
+	Cells []struct {
+		CellType       string          `json:"cell_type"`
+		Source         json.RawMessage `json:"source"`
+		ExecutionCount *int            `json:"execution_count"`
+		Outputs        []struct {
+			OutputType string                     `json:"output_type"`
+			Name       string                     `json:"name"`
+			Text       json.RawMessage            `json:"text"`
+			Data       map[string]json.RawMessage `json:"data"`
+			EName      string                     `json:"ename"`
+			EValue     string                     `json:"evalue"`
+			Traceback  []string                   `json:"traceback"`
+		} `json:"outputs"`
+	} `json:"cells"`
+}
+
+// RunNotebook executes a Jupyter notebook with papermill, injecting the given parameters into its parameters cell
+func (r *Runner) RunNotebook(ctx context.Context, notebookPath string, params map[string]any) (*NotebookResult, error) {
+	if _, err := os.Stat(notebookPath); os.IsNotExist(err) {
+		return nil, fmt.Errorf("notebook file does not exist: %w", err)
+	}
+
+	ctx, cancel := r.withTimeout(ctx)
+	defer cancel()
+
+	x := &execution{}
+	defer x.cleanup()
+
+	outputPath, err := x.tempPath("output.ipynb")
+	if err != nil {
+		return nil, err
+	}
+
+	if err := r.syncEnv(ctx); err != nil {
+		return nil, &PhaseError{Phase: PhaseResolution, Err: err}
+	}
+
+	uvArgs := append(r.runArgs("papermill", "ipykernel"), "papermill", "--kernel", "python3", "--no-progress-bar")
+	if len(params) > 0 {
+		data, err := json.Marshal(params)
+		if err != nil {
+			return nil, fmt.Errorf("failed to marshal notebook parameters: %w", err)
+		}
+		// JSON is valid YAML, which is what papermill expects for parameter files
+		paramsPath, err := x.writeFile("params.yaml", string(data))
+		if err != nil {
+			return nil, err
+		}
+		uvArgs = append(uvArgs, "--parameters_file", paramsPath)
+	}
+	uvArgs = append(uvArgs, notebookPath, outputPath)
+
+	result, runErr := r.invoke(ctx, x, uvArgs, nil)
+
+	// papermill still writes the executed notebook when a cell fails
+	notebook := &NotebookResult{Result: result}
+	if data, err := os.ReadFile(outputPath); err == nil {
+		if notebook.Cells, err = parseNotebook(data); err != nil {
+			return notebook, err
+		}
+	}
+
+	if runErr != nil {
+		if cellErr := notebook.Err(); cellErr != nil && ctx.Err() == nil {
+			return notebook, fmt.Errorf("notebook execution failed: %w", cellErr)
+		}
+		return notebook, r.runError(ctx, result, runErr)
+	}
+	return notebook, nil
+}
+
+func parseNotebook(data []byte) ([]NotebookCell, error) {
+	var nb notebookFile
+	if err := json.Unmarshal(data, &nb); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal notebook: %w", err)
+	}
+
+	cells := make([]NotebookCell, 0, len(nb.Cells))
+	for i, c := range nb.Cells {
+		cell := NotebookCell{
+			Index:  i,
+			Type:   c.CellType,
+			Source: notebookText(c.Source),
+		}
+		if c.ExecutionCount != nil {
+			cell.ExecutionCount = *c.ExecutionCount
+		}
+
+		for _, o := range c.Outputs {
+			out := CellOutput{
+				Type:       o.OutputType,
+				Name:       o.Name,
+				Text:       notebookText(o.Text),
+				ErrorName:  o.EName,
+				ErrorValue: o.EValue,
+				Traceback:  o.Traceback,
+			}
+			if len(o.Data) > 0 {
+				out.Data = make(map[string]string, len(o.Data))
+				for mime, content := range o.Data {
+					out.Data[mime] = notebookText(content)
+				}
+			}
+			cell.Outputs = append(cell.Outputs, out)
+		}
+
+		cells = append(cells, cell)
+	}
+	return cells, nil
+}
+
+// notebookText flattens a notebook multiline string, leaving any other JSON value as its raw text
+func notebookText(raw json.RawMessage) string {
+	if len(raw) == 0 {
+		return ""
+	}
+
+	var s string
+	if err := json.Unmarshal(raw, &s); err == nil {
+		return s
+	}
+
+	var lines []string
+	if err := json.Unmarshal(raw, &lines); err == nil {
+		return strings.Join(lines, "")
+	}
+	return string(raw)
+}
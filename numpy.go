@@ -0,0 +1,325 @@
+package uvgo
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// arrayChannel is the channel a script returns its arrays on
+const arrayChannel = "arrays"
+
+// Array is an n-dimensional numpy array, holding its elements in the raw bytes of the .npy format
+type Array struct {
+	Name string
+	// DType is the numpy type string of the elements, such as "<f8" for little-endian float64
+	DType string
+	Shape []int
+	// FortranOrder reports whether Data holds the elements in column-major order rather than row-major order.
+	// Arrays returned with NumPySnippet are always row-major.
+	FortranOrder bool
+	Data         []byte
+
+	kind  byte
+	size  int
+	order binary.ByteOrder
+}
+
+// ArrayElement is a Go type the elements of an Array decode into
+type ArrayElement interface {
+	bool | int8 | int16 | int32 | int64 | uint8 | uint16 | uint32 | uint64 | float32 | float64
+}
+
+// Len returns the number of elements in the array
+func (a *Array) Len() int {
+	n := 1
+	for _, dim := range a.Shape {
+		n *= dim
+	}
+	return n
+}
+
+// Float64s returns the elements of an array of any boolean, integer or floating point type as float64 values
+func (a *Array) Float64s() ([]float64, error) {
+	values := make([]float64, a.Len())
+	switch a.kind {
+	case 'b', 'u':
+		decodeElements(a, values, func(u uint64) float64 { return float64(u) })
+	case 'i':
+		shift := 64 - 8*a.size
+		decodeElements(a, values, func(u uint64) float64 { return float64(int64(u<<shift) >> shift) })
+	case 'f':
+		if a.size == 4 {
+			decodeElements(a, values, func(u uint64) float64 { return float64(math.Float32frombits(uint32(u))) })
+		} else {
+			decodeElements(a, values, math.Float64frombits)
+		}
+	default:
+		return nil, fmt.Errorf("unsupported array type %s", a.DType)
+	}
+	return values, nil
+}
+
+// ArrayValues returns the elements of an array as values of the specified type, which must match the array's
+// type exactly, such as float32 for "<f4" or uint8 for "|u1"
+func ArrayValues[T ArrayElement](a *Array) ([]T, error) {
+	kind, size := arrayElementType([]T(nil))
+	if a.kind != kind || a.size != size {
+		return nil, fmt.Errorf("array type %s does not match %T", a.DType, *new(T))
+	}
+
+	values := make([]T, a.Len())
+
+	switch v := any(values).(type) {
+	case []bool:
+		decodeElements(a, v, func(u uint64) bool { return u != 0 })
+	case []int8:
+		decodeElements(a, v, func(u uint64) int8 { return int8(u) })
+	case []int16:
+		decodeElements(a, v, func(u uint64) int16 { return int16(u) })
+	case []int32:
+		decodeElements(a, v, func(u uint64) int32 { return int32(u) })
+	case []int64:
+		decodeElements(a, v, func(u uint64) int64 { return int64(u) })
+	case []uint8:
+		decodeElements(a, v, func(u uint64) uint8 { return uint8(u) })
+	case []uint16:
+		decodeElements(a, v, func(u uint64) uint16 { return uint16(u) })
+	case []uint32:
+		decodeElements(a, v, func(u uint64) uint32 { return uint32(u) })
+	case []uint64:
+		decodeElements(a, v, func(u uint64) uint64 { return u })
+	case []float32:
+		decodeElements(a, v, func(u uint64) float32 { return math.Float32frombits(uint32(u)) })
+	case []float64:
+		decodeElements(a, v, math.Float64frombits)
+	}
+	return values, nil
+}
+
+// arrayElementType returns the numpy kind and size matching the element type of values
+func arrayElementType(values any) (byte, int) {
+	switch values.(type) {
+	case []bool:
+		return 'b', 1
+	case []int8:
+		return 'i', 1
+	case []int16:
+		return 'i', 2
+	case []int32:
+		return 'i', 4
+	case []int64:
+		return 'i', 8
+	case []uint8:
+		return 'u', 1
+	case []uint16:
+		return 'u', 2
+	case []uint32:
+		return 'u', 4
+	case []uint64:
+		return 'u', 8
+	case []float32:
+		return 'f', 4
+	default:
+		return 'f', 8
+	}
+}
+
+// decodeElements fills values with the elements of the array, converted from their raw bits
+func decodeElements[T any](a *Array, values []T, convert func(uint64) T) {
+	for i := range values {
+		b := a.Data[i*a.size : (i+1)*a.size]
+		var u uint64
+		switch a.size {
+		case 1:
+			u = uint64(b[0])
+		case 2:
+			u = uint64(a.order.Uint16(b))
+		case 4:
+			u = uint64(a.order.Uint32(b))
+		case 8:
+			u = a.order.Uint64(b)
+		}
+		values[i] = convert(u)
+	}
+}
+
+var (
+	npyMagic = []byte("\x93NUMPY")
+	npyDescr = regexp.MustCompile(`'descr':\s*'([^']*)'`)
+	npyOrder = regexp.MustCompile(`'fortran_order':\s*(True|False)`)
+	npyShape = regexp.MustCompile(`'shape':\s*\(([^)]*)\)`)
+)
+
+// ParseNPY parses an array in the .npy format written by numpy.save. Only arrays of boolean, integer and
+// floating point elements of 1, 2, 4 or 8 bytes are supported.
+func ParseNPY(data []byte) (*Array, error) {
+	if !bytes.HasPrefix(data, npyMagic) || len(data) < 10 {
+		return nil, errors.New("not a .npy file")
+	}
+
+	var headerLen, offset int
+	switch data[6] {
+	case 1:
+		headerLen, offset = int(binary.LittleEndian.Uint16(data[8:10])), 10
+	case 2, 3:
+		if len(data) < 12 {
+			return nil, errors.New("truncated .npy header")
+		}
+		headerLen, offset = int(binary.LittleEndian.Uint32(data[8:12])), 12
+	default:
+		return nil, fmt.Errorf("unsupported .npy version %d", data[6])
+	}
+	if len(data) < offset+headerLen {
+		return nil, errors.New("truncated .npy header")
+	}
+	header := string(data[offset : offset+headerLen])
+
+	descr := npyDescr.FindStringSubmatch(header)
+	order := npyOrder.FindStringSubmatch(header)
+	shape := npyShape.FindStringSubmatch(header)
+	if descr == nil || order == nil || shape == nil {
+		return nil, fmt.Errorf("unsupported .npy header %q", strings.TrimSpace(header))
+	}
+
+	a := &Array{DType: descr[1], FortranOrder: order[1] == "True", Shape: []int{}, Data: data[offset+headerLen:]}
+	for _, dim := range strings.Split(shape[1], ",") {
+		if dim = strings.TrimSpace(dim); dim == "" {
+			continue
+		}
+		n, err := strconv.Atoi(strings.TrimSuffix(dim, "L"))
+		if err != nil {
+			return nil, fmt.Errorf("invalid .npy shape %q", shape[1])
+		}
+		a.Shape = append(a.Shape, n)
+	}
+
+	if len(a.DType) < 3 {
+		return nil, fmt.Errorf("unsupported array type %s", a.DType)
+	}
+	switch a.DType[0] {
+	case '<', '|':
+		a.order = binary.LittleEndian
+	case '>':
+		a.order = binary.BigEndian
+	case '=':
+		a.order = binary.NativeEndian
+	default:
+		return nil, fmt.Errorf("unsupported array type %s", a.DType)
+	}
+	a.kind = a.DType[1]
+	size, err := strconv.Atoi(a.DType[2:])
+	if err != nil || !strings.ContainsRune("biuf", rune(a.kind)) || (size != 1 && size != 2 && size != 4 && size != 8) ||
+		(a.kind == 'f' && size < 4) || (a.kind == 'b' && size != 1) {
+		return nil, fmt.Errorf("unsupported array type %s", a.DType)
+	}
+	a.size = size
+
+	if len(a.Data) < a.Len()*a.size {
+		return nil, fmt.Errorf("truncated .npy data: %d bytes for %d elements of %s", len(a.Data), a.Len(), a.DType)
+	}
+	a.Data = a.Data[:a.Len()*a.size]
+	return a, nil
+}
+
+// NumPySnippet is Python source defining a return_array function that sends a numpy array to the Go runner in
+// the .npy format over the binary channel ArrayOutput passes the script, leaving stdout free
+const NumPySnippet = `import io
+import json
+import os
+
+_uvgo_arrays = None
+
+
+def return_array(name, array):
+    """Return a numpy array to the Go runner under name."""
+    import numpy as np
+
+    global _uvgo_arrays
+    if _uvgo_arrays is None:
+        fd = os.environ.get("UVGO_FD_ARRAYS")
+        if fd is None:
+            raise RuntimeError("no arrays channel, run the script with ArrayOutput")
+        _uvgo_arrays = os.fdopen(int(fd), "wb")
+
+    array = np.asarray(array)
+    if not array.flags.c_contiguous:
+        array = np.ascontiguousarray(array)
+    buf = io.BytesIO()
+    np.save(buf, array, allow_pickle=False)
+    data = buf.getbuffer()
+    _uvgo_arrays.write((json.dumps({"name": name, "size": len(data)}) + "\n").encode())
+    _uvgo_arrays.write(data)
+    _uvgo_arrays.flush()
+`
+
+// ArrayOutput runs a script that returns numpy arrays with the return_array function of NumPySnippet and
+// decodes them by name as they are sent. The arrays are sent over a channel, which needs an executor running
+// uv as a local subprocess.
+func ArrayOutput(ctx context.Context, r *Runner, scriptPath string, args ...string) (*StructuredResult[map[string]*Array], error) {
+	pr, pw := io.Pipe()
+	arrays := make(map[string]*Array)
+	decoded := make(chan error, 1)
+	go func() {
+		decoded <- decodeArrays(pr, arrays)
+	}()
+
+	result, err := r.With(WithChannel(arrayChannel, pw)).Run(ctx, scriptPath, args...)
+	pw.Close()
+	decodeErr := <-decoded
+	if err != nil {
+		return &StructuredResult[map[string]*Array]{Result: result}, err
+	}
+	if decodeErr != nil {
+		return &StructuredResult[map[string]*Array]{Result: result}, &PhaseError{
+			Phase: PhaseOutput,
+			Err:   fmt.Errorf("failed to decode arrays: %w", decodeErr),
+		}
+	}
+	return &StructuredResult[map[string]*Array]{Result: result, Data: arrays}, nil
+}
+
+// decodeArrays decodes the arrays sent by return_array from r into arrays, each as a JSON header line with its
+// name and size followed by its .npy bytes. It always consumes r to the end so the writer never blocks.
+func decodeArrays(r io.Reader, arrays map[string]*Array) error {
+	defer io.Copy(io.Discard, r)
+
+	br := bufio.NewReader(r)
+	for {
+		line, err := br.ReadBytes('\n')
+		if errors.Is(err, io.EOF) && len(bytes.TrimSpace(line)) == 0 {
+			return nil
+		}
+		if err != nil {
+			return fmt.Errorf("failed to read array header: %w", err)
+		}
+
+		var header struct {
+			Name string `json:"name"`
+			Size int    `json:"size"`
+		}
+		if err := json.Unmarshal(line, &header); err != nil {
+			return fmt.Errorf("failed to unmarshal array header: %w", err)
+		}
+		data := make([]byte, header.Size)
+		if _, err := io.ReadFull(br, data); err != nil {
+			return fmt.Errorf("failed to read array %s: %w", header.Name, err)
+		}
+
+		array, err := ParseNPY(data)
+		if err != nil {
+			return fmt.Errorf("failed to parse array %s: %w", header.Name, err)
+		}
+		array.Name = header.Name
+		arrays[header.Name] = array
+	}
+}
@@ -0,0 +1,82 @@
+package uvgo
+
+import "errors"
+
+// Phase is the stage of a run in which it failed
+type Phase int
+
+const (
+	// PhaseUnknown is the phase of errors that do not come from a run
+	PhaseUnknown Phase = iota
+	// PhaseResolution covers uv resolving, downloading and building the dependencies of the script
+	PhaseResolution
+	// PhaseBootstrap covers starting uv and the Python interpreter before the script runs
+	PhaseBootstrap
+	// PhaseRuntime covers the script itself, including timeouts
+	PhaseRuntime
+	// PhaseOutput covers parsing the output of a script that ran successfully
+	PhaseOutput
+)
+
+func (p Phase) String() string {
+	switch p {
+	case PhaseResolution:
+		return "resolution"
+	case PhaseBootstrap:
+		return "bootstrap"
+	case PhaseRuntime:
+		return "runtime"
+	case PhaseOutput:
+		return "output"
+	default:
+		return "unknown"
+	}
+}
+
+// PhaseError tags a failure with the phase it happened in when no more specific error type does
+type PhaseError struct {
+	Phase Phase
+	Err   error
+}
+
+func (e *PhaseError) Error() string {
+	return e.Err.Error()
+}
+
+func (e *PhaseError) Unwrap() error {
+	return e.Err
+}
+
+// ErrorPhase returns the phase of the run in which err happened. Environment failures in the resolution and
+// bootstrap phases are typically operational issues worth retrying or alerting on, while runtime and output
+// failures are usually for the script author to fix.
+func ErrorPhase(err error) Phase {
+	var phased interface{ failurePhase() Phase }
+	if errors.As(err, &phased) {
+		return phased.failurePhase()
+	}
+	return PhaseUnknown
+}
+
+func (e *PhaseError) failurePhase() Phase { return e.Phase }
+
+func (e *UVError) failurePhase() Phase {
+	if e.Kind == UVErrorPython {
+		return PhaseBootstrap
+	}
+	return PhaseResolution
+}
+
+// an exception raised outside of any frame of the script comes from the interpreter or the runner's bootstrap
+func (e *PythonError) failurePhase() Phase {
+	if len(e.Traceback.Frames) == 0 {
+		return PhaseBootstrap
+	}
+	return PhaseRuntime
+}
+
+func (e *ExitError) failurePhase() Phase { return PhaseRuntime }
+
+func (e *TimeoutError) failurePhase() Phase { return PhaseRuntime }
+
+func (e *LicenseError) failurePhase() Phase { return PhaseBootstrap }
@@ -0,0 +1,28 @@
+package uvgo
+
+import (
+	"context"
+	"fmt"
+	"time"
+)
+
+const pingScript = `import json
+print(json.dumps({"ok": True}))
+`
+
+// Ping runs a trivial script through the full execution path and returns its latency, making it suitable
+// for readiness probes
+func (r *Runner) Ping(ctx context.Context) (time.Duration, error) {
+	start := time.Now()
+
+	result, err := StructuredOutputFromString[struct {
+		OK bool `json:"ok"`
+	}](ctx, r, pingScript)
+	if err != nil {
+		return 0, fmt.Errorf("ping failed: %w", err)
+	}
+	if !result.Data.OK {
+		return 0, fmt.Errorf("ping failed: unexpected output %q", result.Stdout)
+	}
+	return time.Since(start), nil
+}
@@ -0,0 +1,196 @@
+package uvgo
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"strings"
+	"sync"
+	"time"
+)
+
+// TransformFunc transforms the output of a pipeline stage before it reaches the next stage
+type TransformFunc func(ctx context.Context, r io.Reader, w io.Writer) error
+
+// Stage is a step of a Pipeline: a script, given by path or content, or a Go transform
+type Stage struct {
+	// Name identifies the stage in results and errors, defaulting to the script path or "transform"
+	Name       string
+	ScriptPath string
+	Script     string
+	Args       []string
+	Transform  TransformFunc
+}
+
+// StageResult describes how a single pipeline stage ran
+type StageResult struct {
+	Name string
+	// Result holds the stage's stderr and timings, with Stdout set only for the last stage. It is nil for
+	// transforms.
+	Result   *Result
+	Duration time.Duration
+	Err      error
+}
+
+// PipelineResult represents the output of a pipeline run
+type PipelineResult struct {
+	// Stdout is the output of the last stage
+	Stdout   string
+	Stages   []StageResult
+	WallTime time.Duration
+}
+
+// StageError attributes a pipeline failure to the stage that caused it
+type StageError struct {
+	Index int
+	Name  string
+	Err   error
+}
+
+func (e *StageError) Error() string {
+	return fmt.Sprintf("pipeline stage %d (%s) failed: %v", e.Index, e.Name, e.Err)
+}
+
+func (e *StageError) Unwrap() error {
+	return e.Err
+}
+
+// Pipeline chains scripts, streaming the stdout of each stage into the stdin of the next
+type Pipeline struct {
+	runner *Runner
+	stages []Stage
+}
+
+// NewPipeline creates a pipeline running its script stages with r
+func NewPipeline(r *Runner, stages ...Stage) *Pipeline {
+	return &Pipeline{runner: r, stages: stages}
+}
+
+// Run executes every stage concurrently, feeding input to the first stage. The runner timeout applies to
+// the pipeline as a whole. When a stage fails, the remaining stages are cancelled and the returned
+// *StageError names the first stage that failed. Every script stage is a run of its own going through the
+// runner's middleware, concurrency limit, rate limit, stats and history, so the concurrency limit must leave
+// room for all script stages at once.
+func (p *Pipeline) Run(ctx context.Context, input io.Reader) (*PipelineResult, error) {
+	if len(p.stages) == 0 {
+		return nil, fmt.Errorf("pipeline has no stages")
+	}
+
+	// stages wait on each other through their pipes, so they would deadlock if they could not all run
+	scripts := 0
+	for _, stage := range p.stages {
+		if stage.Transform == nil {
+			scripts++
+		}
+	}
+	if a := p.runner.admission; a != nil && cap(a.slots) < scripts {
+		return nil, fmt.Errorf("pipeline has %d script stages but the concurrency limit is %d", scripts, cap(a.slots))
+	}
+
+	ctx, cancel := p.runner.withTimeout(ctx)
+	defer cancel()
+
+	var (
+		wg       sync.WaitGroup
+		once     sync.Once
+		stageErr *StageError
+		final    bytes.Buffer
+	)
+	results := make([]StageResult, len(p.stages))
+	start := time.Now()
+
+	in := input
+	if in == nil {
+		in = strings.NewReader("")
+	}
+
+	for i, stage := range p.stages {
+		name := stage.Name
+		if name == "" {
+			name = stage.ScriptPath
+		}
+		if name == "" && stage.Transform != nil {
+			name = "transform"
+		}
+		if name == "" {
+			name = "script"
+		}
+
+		var (
+			out  io.Writer = &final
+			next io.Reader
+			pw   *io.PipeWriter
+		)
+		if i < len(p.stages)-1 {
+			var pr *io.PipeReader
+			pr, pw = io.Pipe()
+			out, next = pw, pr
+		}
+
+		wg.Add(1)
+		go func(in io.Reader) {
+			defer wg.Done()
+
+			stageStart := time.Now()
+			result, err := p.runStage(ctx, stage, in, out)
+
+			// closing both ends lets the next stage see EOF and the previous one stop writing
+			if pw != nil {
+				pw.Close()
+			}
+			if pr, ok := in.(*io.PipeReader); ok {
+				pr.Close()
+			}
+
+			results[i] = StageResult{Name: name, Result: result, Duration: time.Since(stageStart), Err: err}
+			if err != nil {
+				once.Do(func() {
+					stageErr = &StageError{Index: i, Name: name, Err: err}
+					cancel()
+				})
+			}
+		}(in)
+
+		in = next
+	}
+	wg.Wait()
+
+	// stage results only hold the tail of output streamed into the next stage
+	for i := range results[:len(results)-1] {
+		if results[i].Result != nil {
+			results[i].Result.Stdout = ""
+		}
+	}
+	if last := results[len(results)-1].Result; last != nil {
+		last.Stdout = final.String()
+	}
+
+	pipelineResult := &PipelineResult{
+		Stdout:   final.String(),
+		Stages:   results,
+		WallTime: time.Since(start),
+	}
+	if stageErr != nil {
+		return pipelineResult, stageErr
+	}
+	return pipelineResult, nil
+}
+
+// runStage runs a single stage between the given input and output, passing script stages through the
+// runner's middleware like any other run
+func (p *Pipeline) runStage(ctx context.Context, stage Stage, in io.Reader, out io.Writer) (*Result, error) {
+	if stage.Transform != nil {
+		return nil, stage.Transform(ctx, in, out)
+	}
+
+	r := p.runner.With(WithOutputSinks(out, p.runner.stderrSink), func(r *Runner) { r.stdin = in })
+	switch {
+	case stage.ScriptPath != "":
+		return r.Run(ctx, stage.ScriptPath, stage.Args...)
+	case stage.Script != "":
+		return r.RunFromString(ctx, stage.Script, stage.Args...)
+	default:
+		return nil, fmt.Errorf("stage has no script")
+	}
+}
@@ -0,0 +1,130 @@
+package uvgo
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// Platform is a target environment dependencies are resolved for
+type Platform struct {
+	// Platform is the target platform in uv's --python-platform syntax, such as "linux",
+	// "aarch64-unknown-linux-gnu", "x86_64-manylinux_2_28", "macos" or "windows"
+	Platform string
+	// PythonVersion is the target Python version, such as "3.12", defaulting to the runner's Python version
+	PythonVersion string
+}
+
+func (p Platform) String() string {
+	if p.PythonVersion == "" {
+		return p.Platform
+	}
+	return p.Platform + "/python" + p.PythonVersion
+}
+
+// PlatformCheck is the outcome of resolving dependencies for a target platform
+type PlatformCheck struct {
+	Platform Platform
+	// Packages is the pinned set of packages the dependencies resolve to, sorted by name
+	Packages []Package
+	// Err reports why the dependencies do not resolve, typically a *UVError
+	Err error
+}
+
+// Resolved reports whether the dependencies resolve for the platform
+func (c PlatformCheck) Resolved() bool {
+	return c.Err == nil
+}
+
+// CheckPlatforms verifies that dependencies resolve for each target platform, such as linux/aarch64 from a
+// macOS host, without installing or running anything. It defaults to the runner's dependencies. Resolution
+// failures are recorded in each check, so the error is only set when ctx ends.
+func (r *Runner) CheckPlatforms(ctx context.Context, deps []string, platforms ...Platform) ([]PlatformCheck, error) {
+	if len(deps) == 0 {
+		deps = r.dependencies
+	}
+	if len(deps) == 0 {
+		return nil, errors.New("no dependencies to resolve")
+	}
+
+	checks := make([]PlatformCheck, len(platforms))
+	for i, platform := range platforms {
+		checks[i].Platform = platform
+		if platform.Platform == "" {
+			checks[i].Err = errors.New("no target platform given")
+			continue
+		}
+		args := []string{"--python-platform", platform.Platform}
+		if platform.PythonVersion != "" {
+			args = append(args, "--python-version", platform.PythonVersion)
+		}
+		checks[i].Packages, checks[i].Err = r.compile(ctx, deps, args...)
+		if ctx.Err() != nil {
+			return checks, ctx.Err()
+		}
+	}
+	return checks, nil
+}
+
+// compile resolves deps with uv pip compile without installing them and returns the pinned packages
+func (r *Runner) compile(ctx context.Context, deps []string, extraArgs ...string) ([]Package, error) {
+	output, err := r.pipCompile(ctx, deps, append([]string{"--no-annotate"}, extraArgs...)...)
+	if err != nil {
+		return nil, err
+	}
+	return parseRequirements(output), nil
+}
+
+// pipCompile runs uv pip compile on deps and returns its output
+func (r *Runner) pipCompile(ctx context.Context, deps []string, extraArgs ...string) (string, error) {
+	ctx, cancel := r.withTimeout(ctx)
+	defer cancel()
+
+	args := []string{"pip", "compile", "-", "--quiet", "--no-header"}
+	if r.pythonVersion != "" && !containsArg(extraArgs, "--python-version") {
+		args = append(args, "--python-version", r.pythonVersion)
+	}
+	args = append(args, extraArgs...)
+	args = append(args, r.indexArgs()...)
+
+	requirements := strings.NewReader(strings.Join(deps, "\n"))
+	result, err := r.runUV(ctx, &execution{}, args, requirements)
+	if err != nil {
+		if uvErr := ParseUVError(result.Stderr); uvErr != nil {
+			uvErr.Err = err
+			return "", uvErr
+		}
+		if stderr := strings.TrimSpace(result.Stderr); stderr != "" {
+			return "", fmt.Errorf("failed to resolve dependencies: %s", stderr)
+		}
+		return "", fmt.Errorf("failed to resolve dependencies: %w", err)
+	}
+	return result.Stdout, nil
+}
+
+// parseRequirements parses the pinned requirements written by uv pip compile
+func parseRequirements(output string) []Package {
+	var packages []Package
+	for _, line := range strings.Split(output, "\n") {
+		line, _, _ = strings.Cut(line, "#")
+		line, _, _ = strings.Cut(line, ";")
+		line = strings.TrimSpace(strings.TrimSuffix(strings.TrimSpace(line), "\\"))
+		name, version, ok := strings.Cut(line, "==")
+		if !ok {
+			continue
+		}
+		packages = append(packages, Package{Name: strings.TrimSpace(name), Version: strings.TrimSpace(version)})
+	}
+	return packages
+}
+
+// containsArg reports whether args include the flag
+func containsArg(args []string, flag string) bool {
+	for _, arg := range args {
+		if arg == flag || strings.HasPrefix(arg, flag+"=") {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,57 @@
+package uvgo
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestParseRequirements(t *testing.T) {
+	tests := []struct {
+		name   string
+		output string
+		want   []Package
+	}{
+		{
+			name:   "empty",
+			output: "",
+			want:   nil,
+		},
+		{
+			name: "annotated",
+			output: "# This file was autogenerated by uv via the following command:\n" +
+				"#    uv pip compile -\n" +
+				"certifi==2024.2.2\n" +
+				"    # via requests\n" +
+				"requests==2.31.0\n" +
+				"urllib3==2.2.1  # via requests\n",
+			want: []Package{
+				{Name: "certifi", Version: "2024.2.2"},
+				{Name: "requests", Version: "2.31.0"},
+				{Name: "urllib3", Version: "2.2.1"},
+			},
+		},
+		{
+			name: "hashes and markers",
+			output: "requests==2.31.0 \\\n" +
+				"    --hash=sha256:58cd2187c01e70e6e26505bca751777aa9f2ee0b7f4300988b709f44e013003f\n" +
+				"colorama==0.4.6 ; sys_platform == 'win32'\n",
+			want: []Package{
+				{Name: "requests", Version: "2.31.0"},
+				{Name: "colorama", Version: "0.4.6"},
+			},
+		},
+		{
+			name:   "unpinned lines skipped",
+			output: "-e ./local\nrequests>=2\n--index-url https://example.com/simple\n",
+			want:   nil,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := parseRequirements(tt.output); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("parseRequirements() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,84 @@
+package uvgo
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// Figure represents a matplotlib figure rendered after the script finished
+type Figure struct {
+	Number int
+	Format string
+	Data   []byte
+}
+
+const plotTeardown = `
+if "matplotlib.pyplot" in sys.modules:
+    _uvgo_plt = sys.modules["matplotlib.pyplot"]
+    for _uvgo_num in _uvgo_plt.get_fignums():
+        for _uvgo_fmt in os.environ["UVGO_PLOT_FORMATS"].split(","):
+            _uvgo_plt.figure(_uvgo_num).savefig(
+                os.path.join(os.environ["UVGO_PLOT_DIR"], "%d.%s" % (_uvgo_num, _uvgo_fmt)),
+                format=_uvgo_fmt,
+            )
+`
+
+// withPlots renders every open matplotlib figure with a headless backend and attaches them to the result
+func (x *execution) withPlots(formats []string) error {
+	plotDir, err := x.tempPath("figures")
+	if err != nil {
+		return err
+	}
+	if err := os.Mkdir(plotDir, 0o755); err != nil {
+		return fmt.Errorf("failed to create figure directory: %w", err)
+	}
+
+	x.env = append(x.env,
+		"MPLBACKEND=Agg",
+		"UVGO_PLOT_DIR="+plotDir,
+		"UVGO_PLOT_FORMATS="+strings.Join(formats, ","),
+	)
+	x.teardown = append(x.teardown, plotTeardown)
+	x.collect = append(x.collect, func(result *Result) error {
+		figures, err := readFigures(plotDir)
+		if err != nil {
+			return err
+		}
+		result.Figures = figures
+		return nil
+	})
+	return nil
+}
+
+func readFigures(dir string) ([]Figure, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read figures: %w", err)
+	}
+
+	var figures []Figure
+	for _, entry := range entries {
+		var figure Figure
+		name := entry.Name()
+		if _, err := fmt.Sscanf(name, "%d.", &figure.Number); err != nil {
+			continue
+		}
+		figure.Format = strings.TrimPrefix(filepath.Ext(name), ".")
+
+		if figure.Data, err = os.ReadFile(filepath.Join(dir, name)); err != nil {
+			return nil, fmt.Errorf("failed to read figure %s: %w", name, err)
+		}
+		figures = append(figures, figure)
+	}
+
+	sort.Slice(figures, func(i, j int) bool {
+		if figures[i].Number != figures[j].Number {
+			return figures[i].Number < figures[j].Number
+		}
+		return figures[i].Format < figures[j].Format
+	})
+	return figures, nil
+}
@@ -0,0 +1,63 @@
+package uvgo
+
+import (
+	"context"
+	"runtime"
+	"slices"
+	"sync"
+)
+
+// Prefetch warms the uv cache for several dependency sets concurrently, at most GOMAXPROCS at a time, so a
+// service can prepare the environments of all its known scripts at boot. Each set replaces the runner's
+// dependencies the way WithDependencies does, and with WithEnvCache its persistent environment is synced rather
+// than only cached. Every set that fails is listed in the returned *MapError.
+func (r *Runner) Prefetch(ctx context.Context, depSets ...[]string) error {
+	var (
+		mu   sync.Mutex
+		wg   sync.WaitGroup
+		errs []ItemError
+	)
+	fail := func(i int, err error) {
+		mu.Lock()
+		defer mu.Unlock()
+		errs = append(errs, ItemError{Index: i, Err: err})
+	}
+
+	sem := make(chan struct{}, runtime.GOMAXPROCS(0))
+	for i, deps := range depSets {
+		select {
+		case sem <- struct{}{}:
+		case <-ctx.Done():
+			fail(i, ctx.Err())
+			continue
+		}
+
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			defer func() { <-sem }()
+
+			if err := r.With(WithDependencies(deps...)).prefetch(ctx); err != nil {
+				fail(i, err)
+			}
+		}()
+	}
+	wg.Wait()
+
+	if len(errs) > 0 {
+		slices.SortFunc(errs, func(a, b ItemError) int { return a.Index - b.Index })
+		return &MapError{Errors: errs}
+	}
+	return nil
+}
+
+// prefetch prepares the environment the runner's scripts run in without running a script
+func (r *Runner) prefetch(ctx context.Context) error {
+	// a named environment holds a single dependency set, so every set is prefetched into the shared cache instead
+	r.namedEnv = ""
+	if r.persistentEnv() {
+		return r.syncEnv(ctx)
+	}
+	_, err := r.uvOutput(ctx, nil, append(r.runArgs(), "python", "-c", "pass")...)
+	return err
+}
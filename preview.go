@@ -0,0 +1,66 @@
+package uvgo
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/exec"
+)
+
+// CommandPreview describes the uv command a script run would execute
+type CommandPreview struct {
+	// Path is the resolved path of the uv executable, empty for non-local executors
+	Path string
+	// Args is the full argument list, starting with "uv"
+	Args []string
+	// Env holds the variables added to the parent process environment
+	Env []string
+	// Dir is the working directory, empty meaning the current directory
+	Dir string
+	// Stdin holds the script content for scripts passed on standard input
+	Stdin string
+}
+
+// Command returns the command that running a Python script file would execute, without running it.
+// Instrumentation is left out, since it only wraps the script in a bootstrap and sets up the files and
+// listeners it needs when the script runs.
+func (r *Runner) Command(ctx context.Context, scriptPath string, args ...string) (*CommandPreview, error) {
+	if _, err := os.Stat(scriptPath); os.IsNotExist(err) {
+		return nil, fmt.Errorf("script file does not exist: %w", err)
+	}
+	return r.preview(ctx, scriptPath, "", args)
+}
+
+// CommandFromString returns the command that running a Python script from a string would execute, without
+// running it. Like Command, it leaves out instrumentation.
+func (r *Runner) CommandFromString(ctx context.Context, script string, args ...string) (*CommandPreview, error) {
+	if script == "" {
+		return nil, fmt.Errorf("empty script provided")
+	}
+	return r.preview(ctx, "-", script, args)
+}
+
+func (r *Runner) preview(ctx context.Context, scriptPath, scriptContent string, args []string) (*CommandPreview, error) {
+	x := &execution{}
+	uvArgs := append(r.runArgs(), scriptPath)
+
+	preview := &CommandPreview{
+		Args: append(append([]string{"uv"}, uvArgs...), r.runScriptArgs(args)...),
+		Env:  r.commandEnv(ctx, x),
+		Dir:  r.commandDir(x),
+	}
+	if scriptPath == "-" {
+		preview.Stdin = scriptContent
+	}
+	if local, ok := localExecutor(r.executor); ok {
+		path := local.Path
+		if path == "" {
+			path = "uv"
+		}
+		var err error
+		if preview.Path, err = exec.LookPath(path); err != nil {
+			return nil, fmt.Errorf("uv not found in PATH: %w", err)
+		}
+	}
+	return preview, nil
+}
@@ -0,0 +1,249 @@
+package uvgo
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"syscall"
+	"time"
+)
+
+// signalFileName is the name of the file recording the last signal sent to a detached run
+const signalFileName = "signal"
+
+// processPollInterval is how often a Process checks whether a detached run has finished
+const processPollInterval = 200 * time.Millisecond
+
+// Process is a handle on a detached run, possibly started by another Go process
+type Process struct {
+	handle Handle
+}
+
+// Attach returns a handle on a run started with Detach, given the path of its handle file or run directory.
+// A bare process ID is also accepted, in which case no output logs or exit code are available, Wait reports
+// an exit code of -1 and signals go to that process alone rather than its process group.
+func Attach(handle string) (*Process, error) {
+	if pid, err := strconv.Atoi(handle); err == nil {
+		if pid <= 0 {
+			return nil, fmt.Errorf("invalid process ID: %d", pid)
+		}
+		return &Process{handle: Handle{PID: pid}}, nil
+	}
+
+	path := handle
+	if info, err := os.Stat(path); err == nil && info.IsDir() {
+		path = filepath.Join(path, handleFileName)
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read handle file: %w", err)
+	}
+
+	p := &Process{}
+	if err := json.Unmarshal(data, &p.handle); err != nil {
+		return nil, fmt.Errorf("failed to parse handle file: %w", err)
+	}
+	return p, nil
+}
+
+// Handle returns the handle the process was attached with
+func (p *Process) Handle() Handle {
+	return p.handle
+}
+
+// Running reports whether the run is still in progress
+func (p *Process) Running() bool {
+	if _, ok := p.exitCode(); ok {
+		return false
+	}
+	return processAlive(p.handle.PID)
+}
+
+// Wait blocks until the run finishes or ctx is done and returns its result, with the output read from the
+// logs. When the run was ended by a signal, Result.Signal records it. The error wraps an *ExitError when the script exited unsuccessfully.
+func (p *Process) Wait(ctx context.Context) (*Result, error) {
+	ticker := time.NewTicker(processPollInterval)
+	defer ticker.Stop()
+
+	for p.Running() {
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		case <-ticker.C:
+		}
+	}
+
+	result := &Result{ExitCode: -1}
+	code, exited := p.exitCode()
+	if exited {
+		result.ExitCode = code
+	}
+	if sig := p.endSignal(code, exited); sig != 0 {
+		result.ExitCode = -1
+		result.Signal = sig
+	}
+	if !p.handle.Started.IsZero() {
+		result.WallTime = time.Since(p.handle.Started)
+		if info, err := os.Stat(p.handle.ExitFile); err == nil {
+			result.WallTime = info.ModTime().Sub(p.handle.Started)
+		}
+	}
+
+	var err error
+	if result.Stdout, result.Stderr, err = p.Logs(); err != nil {
+		return result, err
+	}
+	if result.Signal != nil {
+		return result, fmt.Errorf("script execution ended by %v signal: %w", result.Signal, &ExitError{Code: -1})
+	}
+	if result.ExitCode != 0 && p.handle.ExitFile != "" {
+		return result, fmt.Errorf("script execution failed with exit code %d: %w", result.ExitCode, &ExitError{Code: result.ExitCode})
+	}
+	return result, nil
+}
+
+// Logs returns the output the run has written so far
+func (p *Process) Logs() (stdout, stderr string, err error) {
+	if p.handle.Stdout == "" {
+		return "", "", nil
+	}
+	out, err := os.ReadFile(p.handle.Stdout)
+	if err != nil {
+		return "", "", fmt.Errorf("failed to read output log: %w", err)
+	}
+	errOut, err := os.ReadFile(p.handle.Stderr)
+	if err != nil {
+		return "", "", fmt.Errorf("failed to read output log: %w", err)
+	}
+	return string(out), string(errOut), nil
+}
+
+// Follow copies the output of the run to stdout and stderr as it is written, starting from the beginning of
+// the logs, until the run finishes or ctx is done. Either writer may be nil.
+func (p *Process) Follow(ctx context.Context, stdout, stderr io.Writer) error {
+	if p.handle.Stdout == "" {
+		return errors.New("process has no output logs")
+	}
+
+	var files []*os.File
+	var writers []io.Writer
+	for _, log := range []struct {
+		path string
+		w    io.Writer
+	}{{p.handle.Stdout, stdout}, {p.handle.Stderr, stderr}} {
+		if log.w == nil {
+			continue
+		}
+		f, err := os.Open(log.path)
+		if err != nil {
+			return fmt.Errorf("failed to open output log: %w", err)
+		}
+		defer f.Close()
+		files = append(files, f)
+		writers = append(writers, log.w)
+	}
+
+	ticker := time.NewTicker(processPollInterval)
+	defer ticker.Stop()
+
+	for {
+		// check before copying so the output written before the run finished is always complete
+		running := p.Running()
+		for i, f := range files {
+			if _, err := io.Copy(writers[i], f); err != nil {
+				return err
+			}
+		}
+		if !running {
+			return nil
+		}
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-ticker.C:
+		}
+	}
+}
+
+// Interrupt sends SIGINT to the run and every process it started, raising KeyboardInterrupt in the script
+func (p *Process) Interrupt() error {
+	return p.signal(syscall.SIGINT)
+}
+
+// Terminate sends SIGTERM to the run and every process it started, asking it to exit
+func (p *Process) Terminate() error {
+	return p.signal(syscall.SIGTERM)
+}
+
+// Kill stops the run immediately, along with every process it started
+func (p *Process) Kill() error {
+	return p.signal(syscall.SIGKILL)
+}
+
+// signal delivers sig to the process group of the run, or to the process of a bare process ID, recording it in
+// the run directory so Wait can report it even when the run is waited on by another process
+func (p *Process) signal(sig syscall.Signal) error {
+	if !p.Running() {
+		return nil
+	}
+	if p.handle.Dir != "" {
+		if err := os.WriteFile(filepath.Join(p.handle.Dir, signalFileName), []byte(strconv.Itoa(int(sig))), 0o644); err != nil {
+			return fmt.Errorf("failed to record signal: %w", err)
+		}
+	}
+	// only detached runs are known to lead their own process group, so an attached bare process ID is signalled
+	// on its own
+	send := signalProcessGroup
+	if p.handle.Dir == "" {
+		send = signalProcess
+	}
+	if err := send(p.handle.PID, sig); err != nil {
+		return fmt.Errorf("failed to signal process: %w", err)
+	}
+	return nil
+}
+
+// endSignal returns the signal that ended a finished run, if any. A run killed outright leaves no exit code, so
+// the last signal sent through a Process is used; otherwise the shell reports a script ended by a signal with
+// an exit code of 128 plus the signal number.
+func (p *Process) endSignal(code int, exited bool) syscall.Signal {
+	if exited {
+		sig, _ := ExitCodeSignal(code)
+		return sig
+	}
+	if p.handle.Dir == "" {
+		return 0
+	}
+	data, err := os.ReadFile(filepath.Join(p.handle.Dir, signalFileName))
+	if err != nil {
+		return 0
+	}
+	n, err := strconv.Atoi(strings.TrimSpace(string(data)))
+	if err != nil {
+		return 0
+	}
+	return syscall.Signal(n)
+}
+
+// exitCode returns the exit code recorded by a finished run
+func (p *Process) exitCode() (int, bool) {
+	if p.handle.ExitFile == "" {
+		return 0, false
+	}
+	data, err := os.ReadFile(p.handle.ExitFile)
+	if err != nil {
+		return 0, false
+	}
+	code, err := strconv.Atoi(strings.TrimSpace(string(data)))
+	if err != nil {
+		return 0, false
+	}
+	return code, true
+}
@@ -0,0 +1,22 @@
+//go:build !unix
+
+package uvgo
+
+import (
+	"errors"
+	"os"
+	"syscall"
+)
+
+func processAlive(pid int) bool {
+	_, err := os.FindProcess(pid)
+	return err == nil
+}
+
+func signalProcessGroup(pid int, sig syscall.Signal) error {
+	return errors.New("detached runs are not supported on this platform")
+}
+
+func signalProcess(pid int, sig syscall.Signal) error {
+	return errors.New("detached runs are not supported on this platform")
+}
@@ -0,0 +1,21 @@
+//go:build unix
+
+package uvgo
+
+import "syscall"
+
+// processAlive reports whether a process with the given ID exists and has not exited
+func processAlive(pid int) bool {
+	err := syscall.Kill(pid, 0)
+	return err == nil || err == syscall.EPERM
+}
+
+// signalProcessGroup sends sig to the process group led by a detached run
+func signalProcessGroup(pid int, sig syscall.Signal) error {
+	return syscall.Kill(-pid, sig)
+}
+
+// signalProcess sends sig to a single process
+func signalProcess(pid int, sig syscall.Signal) error {
+	return syscall.Kill(pid, sig)
+}
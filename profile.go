@@ -0,0 +1,102 @@
+package uvgo
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"strconv"
+	"time"
+)
+
+// FunctionStat represents the cProfile statistics of a single function
+type FunctionStat struct {
+	File           string
+	Line           int
+	Function       string
+	Calls          int
+	PrimitiveCalls int
+	TotalTime      time.Duration
+	CumulativeTime time.Duration
+}
+
+type functionStat struct {
+	File           string  `json:"file"`
+	Line           int     `json:"line"`
+	Function       string  `json:"function"`
+	Calls          int     `json:"calls"`
+	PrimitiveCalls int     `json:"primitive_calls"`
+	TotalTime      float64 `json:"total_time"`
+	CumulativeTime float64 `json:"cumulative_time"`
+}
+
+const profileSetup = `
+import cProfile as _uvgo_cprofile
+_uvgo_profiler = _uvgo_cprofile.Profile()
+_uvgo_profiler.enable()
+`
+
+const profileTeardown = `
+_uvgo_profiler.disable()
+import json as _uvgo_json
+import pstats as _uvgo_pstats
+_uvgo_rows = sorted(
+    ((k, v) for k, v in _uvgo_pstats.Stats(_uvgo_profiler).stats.items() if k[0] != __file__ and "runpy" not in k[0]),
+    key=lambda kv: kv[1][3],
+    reverse=True,
+)
+with open(os.environ["UVGO_PROFILE_REPORT"], "w") as _uvgo_f:
+    _uvgo_json.dump([
+        {"file": k[0], "line": k[1], "function": k[2], "primitive_calls": v[0], "calls": v[1], "total_time": v[2], "cumulative_time": v[3]}
+        for k, v in _uvgo_rows[:int(os.environ["UVGO_PROFILE_TOP"])]
+    ], _uvgo_f)
+`
+
+// withProfile runs the script under cProfile and attaches the top functions by cumulative time to the result
+func (x *execution) withProfile(top int) error {
+	reportPath, err := x.tempPath("profile.json")
+	if err != nil {
+		return err
+	}
+
+	x.env = append(x.env, "UVGO_PROFILE_REPORT="+reportPath, "UVGO_PROFILE_TOP="+strconv.Itoa(top))
+	x.setup = append(x.setup, profileSetup)
+	x.teardown = append(x.teardown, profileTeardown)
+	x.collect = append(x.collect, func(result *Result) error {
+		profile, err := readProfile(reportPath)
+		if err != nil {
+			return err
+		}
+		result.Profile = profile
+		return nil
+	})
+	return nil
+}
+
+func readProfile(path string) ([]FunctionStat, error) {
+	data, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read profile report: %w", err)
+	}
+
+	var raw []functionStat
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal profile report: %w", err)
+	}
+
+	stats := make([]FunctionStat, 0, len(raw))
+	for _, s := range raw {
+		stats = append(stats, FunctionStat{
+			File:           s.File,
+			Line:           s.Line,
+			Function:       s.Function,
+			Calls:          s.Calls,
+			PrimitiveCalls: s.PrimitiveCalls,
+			TotalTime:      seconds(s.TotalTime),
+			CumulativeTime: seconds(s.CumulativeTime),
+		})
+	}
+	return stats, nil
+}
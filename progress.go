@@ -0,0 +1,111 @@
+package uvgo
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"strconv"
+	"strings"
+)
+
+// progressDirective prefixes the stderr lines carrying progress updates
+const progressDirective = "@uvgo:progress"
+
+// Progress is a progress update reported by a script
+type Progress struct {
+	// Percent is the completion percentage between 0 and 100, or negative when the script did not report one
+	Percent float64 `json:"percent"`
+	// Stage names the step the script is working on
+	Stage string `json:"stage,omitempty"`
+	// Message describes the update
+	Message string `json:"message,omitempty"`
+}
+
+// ProgressSnippet is Python source defining a progress function that reports progress to a ProgressHandler.
+// Scripts can paste it or keep it in a helper module, and calls are harmless when no handler is set since the
+// updates only go to stderr.
+const ProgressSnippet = `import json
+import sys
+
+
+def progress(percent=None, stage=None, message=None):
+    """Report progress to the Go runner."""
+    update = {"percent": -1 if percent is None else percent}
+    if stage is not None:
+        update["stage"] = stage
+    if message is not None:
+        update["message"] = message
+    sys.stderr.write("@uvgo:progress " + json.dumps(update) + "\n")
+    sys.stderr.flush()
+`
+
+// ProgressHandler receives the progress updates of a run
+type ProgressHandler func(Progress)
+
+// WithProgressHandler calls fn with every progress update a script reports by writing a line of the form
+// "@uvgo:progress {"percent": 40, "stage": "load", "message": "..."}", or just "@uvgo:progress 40", to stderr
+// as ProgressSnippet does. Progress lines are removed from the captured stderr, and calls follow the same rules
+// as WithLineHandler.
+func WithProgressHandler(fn ProgressHandler) Option {
+	return func(r *Runner) { r.progress = fn }
+}
+
+// parseProgress decodes the payload of a progress line, accepting a bare percentage as shorthand
+func parseProgress(payload string) Progress {
+	if percent, err := strconv.ParseFloat(payload, 64); err == nil {
+		return Progress{Percent: percent}
+	}
+	p := Progress{Percent: -1}
+	if err := json.Unmarshal([]byte(payload), &p); err != nil {
+		p.Message = payload
+	}
+	return p
+}
+
+// directiveWriter passes the lines written to it that start with a known directive to its handler and
+// forwards every other line to next
+type directiveWriter struct {
+	next       io.Writer
+	directives map[string]func(payload string)
+	buf        []byte
+}
+
+func (d *directiveWriter) Write(p []byte) (int, error) {
+	d.buf = append(d.buf, p...)
+	for {
+		i := bytes.IndexByte(d.buf, '\n')
+		if i < 0 {
+			break
+		}
+		line := d.buf[:i+1]
+		d.buf = d.buf[i+1:]
+		if !d.directive(string(line)) {
+			if _, err := d.next.Write(line); err != nil {
+				return len(p), err
+			}
+		}
+	}
+	return len(p), nil
+}
+
+// directive dispatches line to the handler of its directive and reports whether it had one
+func (d *directiveWriter) directive(line string) bool {
+	if !strings.HasPrefix(line, "@uvgo:") {
+		return false
+	}
+	name, payload, _ := strings.Cut(strings.TrimRight(line, "\r\n"), " ")
+	fn, ok := d.directives[name]
+	if !ok {
+		return false
+	}
+	fn(strings.TrimSpace(payload))
+	return true
+}
+
+// flush forwards a final line not terminated by a newline
+func (d *directiveWriter) flush() {
+	if len(d.buf) > 0 && !d.directive(string(d.buf)) {
+		d.next.Write(d.buf)
+	}
+	d.buf = nil
+}
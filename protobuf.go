@@ -0,0 +1,124 @@
+package uvgo
+
+import (
+	"bufio"
+	"context"
+	"encoding/base64"
+	"fmt"
+	"strings"
+
+	"google.golang.org/protobuf/encoding/protowire"
+	"google.golang.org/protobuf/proto"
+)
+
+// ProtoFraming is how a script separates the protobuf messages it writes to stdout
+type ProtoFraming int
+
+const (
+	// ProtoLengthPrefixed writes each serialized message preceded by its length as a varint, the framing of
+	// protobuf's delimited readers and writers
+	ProtoLengthPrefixed ProtoFraming = iota
+	// ProtoBase64 writes each serialized message base64-encoded on its own line
+	ProtoBase64
+)
+
+// ProtoSnippet is Python source defining an emit_proto function that writes a protobuf message to stdout in
+// either framing, for scripts read with ProtoOutput or ProtoMessages
+const ProtoSnippet = `import base64
+import sys
+
+
+def emit_proto(message, base64_lines=False):
+    """Write a protobuf message to stdout for the Go runner."""
+    data = message.SerializeToString()
+    if base64_lines:
+        sys.stdout.write(base64.b64encode(data).decode() + "\n")
+    else:
+        n, prefix = len(data), bytearray()
+        while n > 0x7F:
+            prefix.append((n & 0x7F) | 0x80)
+            n >>= 7
+        prefix.append(n)
+        sys.stdout.flush()
+        sys.stdout.buffer.write(bytes(prefix) + data)
+    sys.stdout.flush()
+`
+
+// ProtoOutput runs a script that writes a single protobuf message to stdout and decodes it into a new message
+// of type T, such as *pb.Report. Length-prefixed output is binary, so it must not be combined with options
+// rewriting output such as WithUTF8 or WithStripANSI.
+func ProtoOutput[T proto.Message](ctx context.Context, r *Runner, framing ProtoFraming, scriptPath string, args ...string) (*StructuredResult[T], error) {
+	messages, err := ProtoMessages[T](ctx, r, framing, scriptPath, args...)
+	if err != nil {
+		return &StructuredResult[T]{Result: messages.Result}, err
+	}
+	if len(messages.Data) != 1 {
+		return &StructuredResult[T]{Result: messages.Result}, &PhaseError{
+			Phase: PhaseOutput,
+			Err:   fmt.Errorf("expected one protobuf message, got %d", len(messages.Data)),
+		}
+	}
+	return &StructuredResult[T]{Result: messages.Result, Data: messages.Data[0]}, nil
+}
+
+// ProtoMessages runs a script that writes a sequence of protobuf messages to stdout and decodes each into a
+// new message of type T
+func ProtoMessages[T proto.Message](ctx context.Context, r *Runner, framing ProtoFraming, scriptPath string, args ...string) (*StructuredResult[[]T], error) {
+	result, err := r.Run(ctx, scriptPath, args...)
+	if err != nil {
+		return &StructuredResult[[]T]{Result: result}, err
+	}
+
+	messages, err := decodeProtoMessages[T](result.Stdout, framing)
+	if err != nil {
+		return &StructuredResult[[]T]{Result: result}, &PhaseError{Phase: PhaseOutput, Err: err}
+	}
+	return &StructuredResult[[]T]{Result: result, Data: messages}, nil
+}
+
+// decodeProtoMessages splits output into framed messages and unmarshals each into a new T
+func decodeProtoMessages[T proto.Message](output string, framing ProtoFraming) ([]T, error) {
+	var frames [][]byte
+	switch framing {
+	case ProtoLengthPrefixed:
+		data := []byte(output)
+		for len(data) > 0 {
+			size, n := protowire.ConsumeVarint(data)
+			if n < 0 || uint64(len(data)-n) < size {
+				return nil, fmt.Errorf("malformed protobuf frame at offset %d", len(output)-len(data))
+			}
+			frames = append(frames, data[n:n+int(size)])
+			data = data[n+int(size):]
+		}
+	case ProtoBase64:
+		scanner := bufio.NewScanner(strings.NewReader(output))
+		scanner.Buffer(make([]byte, 64*1024), 64*1024*1024)
+		for scanner.Scan() {
+			line := strings.TrimSpace(scanner.Text())
+			if line == "" {
+				continue
+			}
+			frame, err := base64.StdEncoding.DecodeString(line)
+			if err != nil {
+				return nil, fmt.Errorf("failed to decode base64 protobuf message: %w", err)
+			}
+			frames = append(frames, frame)
+		}
+		if err := scanner.Err(); err != nil {
+			return nil, fmt.Errorf("failed to read script output: %w", err)
+		}
+	default:
+		return nil, fmt.Errorf("unsupported protobuf framing: %d", framing)
+	}
+
+	messages := make([]T, len(frames))
+	for i, frame := range frames {
+		var zero T
+		msg := zero.ProtoReflect().New().Interface()
+		if err := proto.Unmarshal(frame, msg); err != nil {
+			return nil, fmt.Errorf("failed to unmarshal protobuf message %d: %w", i, err)
+		}
+		messages[i] = msg.(T)
+	}
+	return messages, nil
+}
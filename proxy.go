@@ -0,0 +1,112 @@
+package uvgo
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+)
+
+// Proxy configures the proxies uv and scripts reach the network through
+type Proxy struct {
+	// HTTP is the proxy URL for plain HTTP requests, such as "http://proxy.corp:3128"
+	HTTP string
+	// HTTPS is the proxy URL for HTTPS requests, which package indexes normally use
+	HTTPS string
+	// NoProxy lists the hosts, domains and addresses reached directly, such as "localhost" or ".corp.example.com"
+	NoProxy []string
+}
+
+// WithProxy routes the network traffic of uv and the scripts it runs through proxies, setting HTTP_PROXY,
+// HTTPS_PROXY and NO_PROXY along with their lowercase forms, which some tools read instead
+func WithProxy(proxy Proxy) Option {
+	return func(r *Runner) { r.proxy = proxy }
+}
+
+// WithCABundle trusts the certificate authorities in a PEM bundle, such as a corporate root CA, for the TLS
+// connections of uv and the scripts it runs, by setting SSL_CERT_FILE and REQUESTS_CA_BUNDLE, which the
+// requests library reads instead
+func WithCABundle(path string) Option {
+	return func(r *Runner) { r.caBundle = path }
+}
+
+// WithNativeTLS makes uv trust the certificate authorities of the operating system's certificate store rather
+// than its bundled ones (UV_NATIVE_TLS), for private CAs already installed on the machine
+func WithNativeTLS() Option {
+	return func(r *Runner) { r.nativeTLS = true }
+}
+
+// networkEnv returns the environment variables configuring proxies and TLS trust
+func (r *Runner) networkEnv() []string {
+	var env []string
+	for _, v := range []struct{ name, value string }{
+		{"HTTP_PROXY", r.proxy.HTTP},
+		{"HTTPS_PROXY", r.proxy.HTTPS},
+		{"NO_PROXY", strings.Join(r.proxy.NoProxy, ",")},
+	} {
+		if v.value != "" {
+			env = append(env, v.name+"="+v.value, strings.ToLower(v.name)+"="+v.value)
+		}
+	}
+	if r.caBundle != "" {
+		env = append(env, "SSL_CERT_FILE="+r.caBundle, "REQUESTS_CA_BUNDLE="+r.caBundle)
+	}
+	if r.nativeTLS {
+		env = append(env, "UV_NATIVE_TLS=1")
+	}
+	return env
+}
+
+// httpClient returns a client reaching the network the way uv does, through the runner's proxies and trusting
+// its CA bundle
+func (r *Runner) httpClient() (*http.Client, error) {
+	if r.proxy.HTTP == "" && r.proxy.HTTPS == "" && r.caBundle == "" {
+		return http.DefaultClient, nil
+	}
+
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	if r.proxy.HTTP != "" || r.proxy.HTTPS != "" {
+		transport.Proxy = r.proxy.forRequest
+	}
+	if r.caBundle != "" {
+		pem, err := os.ReadFile(r.caBundle)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read CA bundle: %w", err)
+		}
+		pool, err := x509.SystemCertPool()
+		if err != nil {
+			pool = x509.NewCertPool()
+		}
+		if !pool.AppendCertsFromPEM(pem) {
+			return nil, fmt.Errorf("no certificates found in CA bundle %s", r.caBundle)
+		}
+		transport.TLSClientConfig = &tls.Config{RootCAs: pool}
+	}
+	return &http.Client{Transport: transport}, nil
+}
+
+// forRequest returns the proxy a request goes through, or nil when it is sent directly
+func (p Proxy) forRequest(req *http.Request) (*url.URL, error) {
+	proxy := p.HTTP
+	if req.URL.Scheme == "https" {
+		proxy = p.HTTPS
+	}
+	if proxy == "" || p.bypasses(req.URL.Hostname()) {
+		return nil, nil
+	}
+	return url.Parse(proxy)
+}
+
+// bypasses reports whether NoProxy lists a host, matching domains with or without a leading dot
+func (p Proxy) bypasses(host string) bool {
+	for _, entry := range p.NoProxy {
+		entry = strings.TrimPrefix(strings.TrimSpace(entry), ".")
+		if entry == "*" || host == entry || strings.HasSuffix(host, "."+entry) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,61 @@
+package uvgo
+
+import (
+	"io"
+	"os"
+	"os/exec"
+	"sync"
+	"time"
+)
+
+// WithPTY runs scripts with stdout attached to a pseudo-terminal, so tools that only colorize or show progress
+// on a terminal behave as they do interactively. Stderr stays a separate pipe. Only the LocalExecutor on Linux
+// supports it; other executors ignore it. Combine it with WithStripANSI to keep captured output plain.
+func WithPTY() Option {
+	return func(r *Runner) { r.pty = true }
+}
+
+// ptyOutput copies the output a process writes to a pseudo-terminal into a writer
+type ptyOutput struct {
+	master *os.File
+	tty    *os.File
+	done   chan struct{}
+	once   sync.Once
+}
+
+// attachPTY connects the stdout of cmd to a new pseudo-terminal copied into w
+func attachPTY(cmd *exec.Cmd, w io.Writer) (*ptyOutput, error) {
+	master, tty, err := openPTY()
+	if err != nil {
+		return nil, err
+	}
+	if w == nil {
+		w = io.Discard
+	}
+
+	p := &ptyOutput{master: master, tty: tty, done: make(chan struct{})}
+	cmd.Stdout = tty
+	go func() {
+		defer close(p.done)
+		// reading fails with EIO once every process holding the terminal has exited
+		io.Copy(w, master)
+	}()
+	return p, nil
+}
+
+// started releases the parent's handle on the terminal once the process holds its own
+func (p *ptyOutput) started() {
+	p.tty.Close()
+}
+
+// close waits briefly for the remaining output and releases the terminal
+func (p *ptyOutput) close() {
+	p.once.Do(func() {
+		p.tty.Close()
+		select {
+		case <-p.done:
+		case <-time.After(time.Second):
+		}
+		p.master.Close()
+	})
+}
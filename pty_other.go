@@ -0,0 +1,12 @@
+//go:build !linux
+
+package uvgo
+
+import (
+	"errors"
+	"os"
+)
+
+func openPTY() (master, tty *os.File, err error) {
+	return nil, nil, errors.New("pseudo-terminals are not supported on this platform")
+}
@@ -0,0 +1,131 @@
+package uvgo
+
+import (
+	"context"
+	"encoding/json"
+	"log/slog"
+	"math"
+	"slices"
+	"strconv"
+	"time"
+)
+
+// logDirective prefixes the stderr lines carrying Python log records
+const logDirective = "@uvgo:log"
+
+// WithPythonLogger installs a handler on the root Python logger that forwards every log record of the script
+// to logger, preserving its level, logger name, source location, exception and extra fields. Records below the
+// lowest level logger is enabled for are not emitted, and forwarded records are removed from the captured
+// stderr. Scripts keep using the logging module as usual, and calls to logging.basicConfig have no effect.
+func WithPythonLogger(logger *slog.Logger) Option {
+	return func(r *Runner) { r.pythonLogger = logger }
+}
+
+const pythonLoggingSetup = `
+import json as _uvgo_json
+import logging as _uvgo_logging
+import traceback as _uvgo_traceback
+
+_uvgo_record_fields = set(vars(_uvgo_logging.LogRecord("", 0, "", 0, "", (), None))) | {"message", "asctime", "taskName"}
+
+
+class _UvgoLogHandler(_uvgo_logging.Handler):
+    def emit(self, record):
+        try:
+            entry = {
+                "time": record.created,
+                "level": record.levelno,
+                "logger": record.name,
+                "message": record.getMessage(),
+                "file": record.pathname,
+                "line": record.lineno,
+                "function": record.funcName,
+            }
+            if record.exc_info:
+                entry["exception"] = "".join(_uvgo_traceback.format_exception(*record.exc_info)).rstrip()
+            extra = {k: v for k, v in vars(record).items() if k not in _uvgo_record_fields and not k.startswith("_")}
+            if extra:
+                entry["extra"] = extra
+            sys.stderr.write("@uvgo:log " + _uvgo_json.dumps(entry, default=str) + "\n")
+            sys.stderr.flush()
+        except Exception:
+            self.handleError(record)
+
+
+_uvgo_logging.getLogger().addHandler(_UvgoLogHandler())
+_uvgo_logging.getLogger().setLevel(int(os.environ["UVGO_LOG_LEVEL"]))
+`
+
+// pythonLogRecord is a log record emitted by the bundled Python logging handler
+type pythonLogRecord struct {
+	Time      float64        `json:"time"`
+	Level     int            `json:"level"`
+	Logger    string         `json:"logger"`
+	Message   string         `json:"message"`
+	File      string         `json:"file"`
+	Line      int            `json:"line"`
+	Function  string         `json:"function"`
+	Exception string         `json:"exception"`
+	Extra     map[string]any `json:"extra"`
+}
+
+// withPythonLogging installs the bundled logging handler at the lowest level logger is enabled for
+func (x *execution) withPythonLogging(logger *slog.Logger) {
+	level := slog.LevelError + 4
+	for _, l := range []slog.Level{slog.LevelDebug, slog.LevelInfo, slog.LevelWarn, slog.LevelError} {
+		if logger.Enabled(context.Background(), l) {
+			level = l
+			break
+		}
+	}
+	x.env = append(x.env, "UVGO_LOG_LEVEL="+strconv.Itoa(pythonLevel(level)))
+	x.setup = append(x.setup, pythonLoggingSetup)
+}
+
+// slogLevel converts a Python logging level to the slog level with the same meaning, so that DEBUG, INFO,
+// WARNING and ERROR map to their slog counterparts and CRITICAL sits above slog.LevelError
+func slogLevel(levelno int) slog.Level {
+	return slog.Level((levelno - 20) * 4 / 10)
+}
+
+// pythonLevel converts a slog level to a Python logging level
+func pythonLevel(level slog.Level) int {
+	return 20 + int(level)*10/4
+}
+
+// logPythonRecord forwards a Python log record to logger
+func logPythonRecord(ctx context.Context, logger *slog.Logger, payload string) {
+	var rec pythonLogRecord
+	if err := json.Unmarshal([]byte(payload), &rec); err != nil {
+		logger.WarnContext(ctx, payload)
+		return
+	}
+
+	level := slogLevel(rec.Level)
+	if !logger.Enabled(ctx, level) {
+		return
+	}
+
+	sec, frac := math.Modf(rec.Time)
+	record := slog.NewRecord(time.Unix(int64(sec), int64(frac*1e9)), level, rec.Message, 0)
+	record.AddAttrs(
+		slog.String("logger", rec.Logger),
+		slog.Group(slog.SourceKey,
+			slog.String("function", rec.Function),
+			slog.String("file", rec.File),
+			slog.Int("line", rec.Line),
+		),
+	)
+	if rec.Exception != "" {
+		record.AddAttrs(slog.String("exception", rec.Exception))
+	}
+	keys := make([]string, 0, len(rec.Extra))
+	for k := range rec.Extra {
+		keys = append(keys, k)
+	}
+	slices.Sort(keys)
+	for _, k := range keys {
+		record.AddAttrs(slog.Any(k, rec.Extra[k]))
+	}
+	logger.Handler().Handle(ctx, record)
+}
@@ -0,0 +1,193 @@
+package uvgo
+
+import (
+	"context"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// TestStatus represents the outcome of a single test
+type TestStatus string
+
+const (
+	TestPassed  TestStatus = "passed"
+	TestFailed  TestStatus = "failed"
+	TestError   TestStatus = "error"
+	TestSkipped TestStatus = "skipped"
+)
+
+// TestCase represents a single test reported by pytest
+type TestCase struct {
+	Name      string
+	ClassName string
+	File      string
+	Line      int
+	Status    TestStatus
+	Duration  time.Duration
+	Message   string
+	Details   string
+}
+
+// TestReport represents the parsed results of a pytest run
+type TestReport struct {
+	*Result
+	Tests    []TestCase
+	Passed   int
+	Failed   int
+	Errors   int
+	Skipped  int
+	Duration time.Duration
+}
+
+// OK reports whether every collected test passed or was skipped
+func (t *TestReport) OK() bool {
+	return t.Failed == 0 && t.Errors == 0
+}
+
+// Test runs pytest against a project, directory or test file with optional extra pytest flags
+func (r *Runner) Test(ctx context.Context, path string, flags ...string) (*TestReport, error) {
+	ctx, cancel := r.withTimeout(ctx)
+	defer cancel()
+
+	x := &execution{with: []string{"pytest"}}
+	defer x.cleanup()
+
+	if err := r.instrument(x); err != nil {
+		return nil, err
+	}
+
+	if path != "" && x.workDir != "" {
+		var err error
+		if path, err = filepath.Abs(path); err != nil {
+			return nil, fmt.Errorf("failed to resolve test path: %w", err)
+		}
+	}
+
+	reportPath, err := x.tempPath("junit.xml")
+	if err != nil {
+		return nil, err
+	}
+
+	command := []string{"pytest"}
+	if x.instrumented() {
+		if command, err = x.command("-m", "pytest"); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := r.syncEnv(ctx); err != nil {
+		return nil, &PhaseError{Phase: PhaseResolution, Err: err}
+	}
+
+	uvArgs := append(r.runArgs(x.with...), command...)
+	uvArgs = append(uvArgs, "--junitxml", reportPath, "-p", "no:cacheprovider")
+	uvArgs = append(uvArgs, flags...)
+	if path != "" {
+		uvArgs = append(uvArgs, path)
+	}
+
+	result, err := r.invoke(ctx, x, uvArgs, nil)
+	if err != nil {
+		// pytest exits with code 1 when tests fail and 5 when no tests were collected
+		var exitError *ExitError
+		if !errors.As(err, &exitError) || ctx.Err() != nil || (exitError.Code != 1 && exitError.Code != 5) {
+			return &TestReport{Result: result}, r.runError(ctx, result, err)
+		}
+	}
+
+	data, err := os.ReadFile(reportPath)
+	if err != nil {
+		return &TestReport{Result: result}, fmt.Errorf("failed to read test report: %w", err)
+	}
+
+	report, err := parseJUnitReport(data)
+	if err != nil {
+		return &TestReport{Result: result}, err
+	}
+	report.Result = result
+
+	if err := x.result(result); err != nil {
+		return report, err
+	}
+	return report, nil
+}
+
+type junitReport struct {
+	XMLName xml.Name
+	Suites  []junitSuite `xml:"testsuite"`
+	junitSuite
+}
+
+type junitSuite struct {
+	Time  float64     `xml:"time,attr"`
+	Cases []junitCase `xml:"testcase"`
+}
+
+type junitCase struct {
+	Name      string        `xml:"name,attr"`
+	ClassName string        `xml:"classname,attr"`
+	File      string        `xml:"file,attr"`
+	Line      int           `xml:"line,attr"`
+	Time      float64       `xml:"time,attr"`
+	Failure   *junitMessage `xml:"failure"`
+	Error     *junitMessage `xml:"error"`
+	Skipped   *junitMessage `xml:"skipped"`
+}
+
+type junitMessage struct {
+	Message string `xml:"message,attr"`
+	Text    string `xml:",chardata"`
+}
+
+func parseJUnitReport(data []byte) (*TestReport, error) {
+	var raw junitReport
+	if err := xml.Unmarshal(data, &raw); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal test report: %w", err)
+	}
+
+	suites := raw.Suites
+	if raw.XMLName.Local == "testsuite" {
+		suites = []junitSuite{raw.junitSuite}
+	}
+
+	report := &TestReport{}
+	for _, suite := range suites {
+		report.Duration += seconds(suite.Time)
+
+		for _, c := range suite.Cases {
+			tc := TestCase{
+				Name:      c.Name,
+				ClassName: c.ClassName,
+				File:      c.File,
+				Line:      c.Line,
+				Status:    TestPassed,
+				Duration:  seconds(c.Time),
+			}
+
+			switch {
+			case c.Failure != nil:
+				tc.Status, tc.Message, tc.Details = TestFailed, c.Failure.Message, c.Failure.Text
+				report.Failed++
+			case c.Error != nil:
+				tc.Status, tc.Message, tc.Details = TestError, c.Error.Message, c.Error.Text
+				report.Errors++
+			case c.Skipped != nil:
+				tc.Status, tc.Message, tc.Details = TestSkipped, c.Skipped.Message, c.Skipped.Text
+				report.Skipped++
+			default:
+				report.Passed++
+			}
+
+			report.Tests = append(report.Tests, tc)
+		}
+	}
+	return report, nil
+}
+
+func seconds(s float64) time.Duration {
+	return time.Duration(s * float64(time.Second))
+}
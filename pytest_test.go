@@ -0,0 +1,91 @@
+package uvgo
+
+import (
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestParseJUnitReport(t *testing.T) {
+	tests := []struct {
+		name    string
+		data    string
+		want    *TestReport
+		wantErr bool
+	}{
+		{
+			name: "test suites",
+			data: `<?xml version="1.0" encoding="utf-8"?>
+<testsuites>
+  <testsuite name="pytest" errors="1" failures="1" skipped="1" tests="4" time="1.5">
+    <testcase classname="test_math" name="test_add" file="test_math.py" line="3" time="0.25"/>
+    <testcase classname="test_math" name="test_sub" file="test_math.py" line="7" time="0.5">
+      <failure message="assert 1 == 2">def test_sub():
+&gt;       assert 1 == 2</failure>
+    </testcase>
+    <testcase classname="test_math" name="test_div" time="0">
+      <error message="fixture 'db' not found">setup failed</error>
+    </testcase>
+    <testcase classname="test_math" name="test_mul" time="0">
+      <skipped message="not ready"/>
+    </testcase>
+  </testsuite>
+</testsuites>`,
+			want: &TestReport{
+				Tests: []TestCase{
+					{Name: "test_add", ClassName: "test_math", File: "test_math.py", Line: 3, Status: TestPassed, Duration: 250 * time.Millisecond},
+					{Name: "test_sub", ClassName: "test_math", File: "test_math.py", Line: 7, Status: TestFailed, Duration: 500 * time.Millisecond,
+						Message: "assert 1 == 2", Details: "def test_sub():\n>       assert 1 == 2"},
+					{Name: "test_div", ClassName: "test_math", Status: TestError, Message: "fixture 'db' not found", Details: "setup failed"},
+					{Name: "test_mul", ClassName: "test_math", Status: TestSkipped, Message: "not ready"},
+				},
+				Passed:   1,
+				Failed:   1,
+				Errors:   1,
+				Skipped:  1,
+				Duration: 1500 * time.Millisecond,
+			},
+		},
+		{
+			name: "bare test suite",
+			data: `<testsuite name="pytest" time="0.1">
+  <testcase classname="test_app" name="test_ok" time="0.1"/>
+</testsuite>`,
+			want: &TestReport{
+				Tests: []TestCase{
+					{Name: "test_ok", ClassName: "test_app", Status: TestPassed, Duration: 100 * time.Millisecond},
+				},
+				Passed:   1,
+				Duration: 100 * time.Millisecond,
+			},
+		},
+		{
+			name: "no tests collected",
+			data: `<testsuites><testsuite name="pytest" tests="0" time="0.01"/></testsuites>`,
+			want: &TestReport{Duration: 10 * time.Millisecond},
+		},
+		{
+			name:    "malformed",
+			data:    `<testsuites><testsuite`,
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := parseJUnitReport([]byte(tt.data))
+			if tt.wantErr {
+				if err == nil {
+					t.Fatal("parseJUnitReport() succeeded, want an error")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("parseJUnitReport(): %v", err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("parseJUnitReport() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,400 @@
+package uvgo
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+)
+
+// JobState is the lifecycle state of a queued job
+type JobState string
+
+const (
+	// JobPending means the job is waiting to be run, possibly after a retry delay
+	JobPending JobState = "pending"
+	// JobRunning means a worker has claimed the job
+	JobRunning JobState = "running"
+	// JobDone means the job ran successfully
+	JobDone JobState = "done"
+	// JobDead means the job failed on every attempt and was moved to the dead letter state
+	JobDead JobState = "dead"
+)
+
+// QueuedJob represents a script run stored in a JobQueue
+type QueuedJob struct {
+	ID int64
+	// ScriptPath is the script to run, or empty when Script holds the script content
+	ScriptPath string
+	Script     string
+	Args       []string
+	// Payload is passed to the script in the UVGO_PAYLOAD environment variable
+	Payload json.RawMessage
+	// MaxAttempts is the number of times the job is tried before it is dead-lettered, defaulting to the
+	// queue's setting
+	MaxAttempts int
+	State       JobState
+	Attempts    int
+	LastError   string
+	// Output is the standard output of the successful run
+	Output    string
+	RunAt     time.Time
+	CreatedAt time.Time
+	UpdatedAt time.Time
+}
+
+// JobQueueOptions configures a JobQueue
+type JobQueueOptions struct {
+	// MaxAttempts is the default number of attempts per job, defaulting to 3
+	MaxAttempts int
+	// RetryDelay is the delay before the first retry, doubling with every attempt, defaulting to 5 seconds
+	RetryDelay time.Duration
+	// Lease is how long a claimed job stays hidden from other workers without a heartbeat, defaulting to
+	// 1 minute. Jobs whose worker died are picked up again once their lease expires.
+	Lease time.Duration
+	// PollInterval is how often idle workers check for new jobs, defaulting to 1 second
+	PollInterval time.Duration
+	// OnError, when set, is called with every error workers fail to claim a job or store its outcome with
+	OnError func(error)
+}
+
+// JobQueue is a durable queue of script runs stored in a SQLite database. Jobs are delivered at least once:
+// a job claimed by a worker that dies before finishing it is run again once its lease expires.
+//
+// The queue works with any SQLite driver registered with database/sql, such as modernc.org/sqlite or
+// github.com/mattn/go-sqlite3, version 3.35 or later.
+type JobQueue struct {
+	db     *sql.DB
+	runner *Runner
+	opts   JobQueueOptions
+
+	// claimMu serializes claims from this process, which SQLite would otherwise reject as busy
+	claimMu sync.Mutex
+}
+
+const jobQueueSchema = `
+CREATE TABLE IF NOT EXISTS uvgo_jobs (
+	id           INTEGER PRIMARY KEY AUTOINCREMENT,
+	script_path  TEXT    NOT NULL DEFAULT '',
+	script       TEXT    NOT NULL DEFAULT '',
+	args         TEXT    NOT NULL DEFAULT '[]',
+	payload      TEXT    NOT NULL DEFAULT '',
+	state        TEXT    NOT NULL DEFAULT 'pending',
+	attempts     INTEGER NOT NULL DEFAULT 0,
+	max_attempts INTEGER NOT NULL,
+	last_error   TEXT    NOT NULL DEFAULT '',
+	output       TEXT    NOT NULL DEFAULT '',
+	run_at       INTEGER NOT NULL,
+	lease_until  INTEGER NOT NULL DEFAULT 0,
+	created_at   INTEGER NOT NULL,
+	updated_at   INTEGER NOT NULL
+);
+CREATE INDEX IF NOT EXISTS uvgo_jobs_ready ON uvgo_jobs (state, run_at);
+`
+
+const jobColumns = `id, script_path, script, args, payload, state, attempts, max_attempts, last_error, output,
+	run_at, created_at, updated_at`
+
+// NewJobQueue creates a job queue in db, creating its table if needed, that runs jobs with r
+func NewJobQueue(ctx context.Context, db *sql.DB, r *Runner, opts JobQueueOptions) (*JobQueue, error) {
+	if opts.MaxAttempts <= 0 {
+		opts.MaxAttempts = 3
+	}
+	if opts.RetryDelay <= 0 {
+		opts.RetryDelay = 5 * time.Second
+	}
+	if opts.Lease <= 0 {
+		opts.Lease = time.Minute
+	}
+	if opts.PollInterval <= 0 {
+		opts.PollInterval = time.Second
+	}
+
+	if _, err := db.ExecContext(ctx, jobQueueSchema); err != nil {
+		return nil, fmt.Errorf("failed to create job queue table: %w", err)
+	}
+	return &JobQueue{db: db, runner: r, opts: opts}, nil
+}
+
+// Enqueue stores a job to be run as soon as a worker is available and returns its ID
+func (q *JobQueue) Enqueue(ctx context.Context, job QueuedJob) (int64, error) {
+	if job.ScriptPath == "" && job.Script == "" {
+		return 0, fmt.Errorf("job has no script")
+	}
+	if job.MaxAttempts <= 0 {
+		job.MaxAttempts = q.opts.MaxAttempts
+	}
+
+	args, err := json.Marshal(job.Args)
+	if err != nil {
+		return 0, fmt.Errorf("failed to marshal job args: %w", err)
+	}
+
+	now := time.Now()
+	if job.RunAt.IsZero() {
+		job.RunAt = now
+	}
+
+	res, err := q.db.ExecContext(ctx, `
+		INSERT INTO uvgo_jobs (script_path, script, args, payload, max_attempts, run_at, created_at, updated_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?)`,
+		job.ScriptPath, job.Script, string(args), string(job.Payload), job.MaxAttempts,
+		job.RunAt.UnixMilli(), now.UnixMilli(), now.UnixMilli(),
+	)
+	if err != nil {
+		return 0, fmt.Errorf("failed to enqueue job: %w", err)
+	}
+	return res.LastInsertId()
+}
+
+// Get returns a job by ID
+func (q *JobQueue) Get(ctx context.Context, id int64) (*QueuedJob, error) {
+	row := q.db.QueryRowContext(ctx, `SELECT `+jobColumns+` FROM uvgo_jobs WHERE id = ?`, id)
+	job, err := scanJob(row)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get job %d: %w", id, err)
+	}
+	return job, nil
+}
+
+// DeadLetters returns the jobs that failed on every attempt, oldest first
+func (q *JobQueue) DeadLetters(ctx context.Context) ([]QueuedJob, error) {
+	rows, err := q.db.QueryContext(ctx, `SELECT `+jobColumns+` FROM uvgo_jobs WHERE state = ? ORDER BY id`, JobDead)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list dead letters: %w", err)
+	}
+	defer rows.Close()
+
+	var jobs []QueuedJob
+	for rows.Next() {
+		job, err := scanJob(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to list dead letters: %w", err)
+		}
+		jobs = append(jobs, *job)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to list dead letters: %w", err)
+	}
+	return jobs, nil
+}
+
+// Retry moves a dead-lettered job back into the queue with a fresh attempt budget
+func (q *JobQueue) Retry(ctx context.Context, id int64) error {
+	now := time.Now().UnixMilli()
+	res, err := q.db.ExecContext(ctx, `
+		UPDATE uvgo_jobs SET state = ?, attempts = 0, run_at = ?, updated_at = ?
+		WHERE id = ? AND state = ?`,
+		JobPending, now, now, id, JobDead,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to retry job %d: %w", id, err)
+	}
+	if n, _ := res.RowsAffected(); n == 0 {
+		return fmt.Errorf("job %d is not dead-lettered", id)
+	}
+	return nil
+}
+
+// maxClaimFailures is the number of consecutive failed claims after which workers stop
+const maxClaimFailures = 5
+
+// Work runs jobs with the given number of concurrent workers until ctx is cancelled. Runs interrupted by the
+// cancellation are returned to the queue without counting as an attempt. Work stops every worker and returns
+// the error when claiming jobs fails several times in a row, such as with a SQLite version without RETURNING
+// support.
+func (q *JobQueue) Work(ctx context.Context, workers int) error {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	var (
+		wg      sync.WaitGroup
+		once    sync.Once
+		workErr error
+	)
+	for range max(workers, 1) {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if err := q.work(ctx); err != nil {
+				once.Do(func() {
+					workErr = err
+					cancel()
+				})
+			}
+		}()
+	}
+	wg.Wait()
+	return workErr
+}
+
+func (q *JobQueue) work(ctx context.Context) error {
+	failures := 0
+	for ctx.Err() == nil {
+		job, err := q.claim(ctx)
+		switch {
+		case err != nil && ctx.Err() == nil:
+			q.reportError(ctx, err)
+			if failures++; failures >= maxClaimFailures {
+				return err
+			}
+		case err == nil:
+			failures = 0
+		}
+
+		if job == nil {
+			select {
+			case <-time.After(q.opts.PollInterval):
+			case <-ctx.Done():
+			}
+			continue
+		}
+		q.process(ctx, job)
+	}
+	return nil
+}
+
+// reportError passes a worker error to the OnError handler and the runner's logger
+func (q *JobQueue) reportError(ctx context.Context, err error) {
+	if q.opts.OnError != nil {
+		q.opts.OnError(err)
+	}
+	if q.runner.logger != nil {
+		q.runner.logger.ErrorContext(ctx, "job queue worker failed", "error", err)
+	}
+}
+
+// claim takes the next due job, including running jobs whose lease has expired
+func (q *JobQueue) claim(ctx context.Context) (*QueuedJob, error) {
+	q.claimMu.Lock()
+	defer q.claimMu.Unlock()
+
+	now := time.Now()
+	row := q.db.QueryRowContext(ctx, `
+		UPDATE uvgo_jobs SET state = ?, attempts = attempts + 1, lease_until = ?, updated_at = ?
+		WHERE id = (
+			SELECT id FROM uvgo_jobs
+			WHERE (state = ? AND run_at <= ?) OR (state = ? AND lease_until < ?)
+			ORDER BY run_at, id LIMIT 1
+		)
+		RETURNING `+jobColumns,
+		JobRunning, now.Add(q.opts.Lease).UnixMilli(), now.UnixMilli(),
+		JobPending, now.UnixMilli(), JobRunning, now.UnixMilli(),
+	)
+	job, err := scanJob(row)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to claim job: %w", err)
+	}
+	return job, nil
+}
+
+// process runs a claimed job, extending its lease while it runs, and records the outcome
+func (q *JobQueue) process(ctx context.Context, job *QueuedJob) {
+	heartbeatCtx, stop := context.WithCancel(ctx)
+	defer stop()
+	go q.heartbeat(heartbeatCtx, job.ID)
+
+	runner := q.runner
+	if len(job.Payload) > 0 {
+		runner = runner.With(func(r *Runner) { r.env = append(r.env, "UVGO_PAYLOAD="+string(job.Payload)) })
+	}
+
+	var (
+		result *Result
+		err    error
+	)
+	if job.ScriptPath != "" {
+		result, err = runner.Run(ctx, job.ScriptPath, job.Args...)
+	} else {
+		result, err = runner.RunFromString(ctx, job.Script, job.Args...)
+	}
+	stop()
+
+	// the outcome must be stored even when the worker is shutting down
+	storeCtx := context.WithoutCancel(ctx)
+	now := time.Now()
+
+	switch {
+	case err != nil && ctx.Err() != nil:
+		_, err = q.db.ExecContext(storeCtx, `
+			UPDATE uvgo_jobs SET state = ?, attempts = attempts - 1, lease_until = 0, updated_at = ?
+			WHERE id = ?`,
+			JobPending, now.UnixMilli(), job.ID,
+		)
+	case err == nil:
+		_, err = q.db.ExecContext(storeCtx, `
+			UPDATE uvgo_jobs SET state = ?, output = ?, last_error = '', lease_until = 0, updated_at = ?
+			WHERE id = ?`,
+			JobDone, result.Stdout, now.UnixMilli(), job.ID,
+		)
+	case job.Attempts >= job.MaxAttempts:
+		_, err = q.db.ExecContext(storeCtx, `
+			UPDATE uvgo_jobs SET state = ?, last_error = ?, lease_until = 0, updated_at = ?
+			WHERE id = ?`,
+			JobDead, err.Error(), now.UnixMilli(), job.ID,
+		)
+	default:
+		delay := q.opts.RetryDelay << min(job.Attempts-1, 16)
+		_, err = q.db.ExecContext(storeCtx, `
+			UPDATE uvgo_jobs SET state = ?, last_error = ?, run_at = ?, lease_until = 0, updated_at = ?
+			WHERE id = ?`,
+			JobPending, err.Error(), now.Add(delay).UnixMilli(), now.UnixMilli(), job.ID,
+		)
+	}
+	if err != nil {
+		q.reportError(ctx, fmt.Errorf("failed to store outcome of job %d: %w", job.ID, err))
+	}
+}
+
+// heartbeat extends a job's lease until ctx is cancelled
+func (q *JobQueue) heartbeat(ctx context.Context, id int64) {
+	ticker := time.NewTicker(q.opts.Lease / 3)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			now := time.Now()
+			q.db.ExecContext(ctx, `UPDATE uvgo_jobs SET lease_until = ?, updated_at = ? WHERE id = ? AND state = ?`,
+				now.Add(q.opts.Lease).UnixMilli(), now.UnixMilli(), id, JobRunning)
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+// rowScanner is implemented by *sql.Row and *sql.Rows
+type rowScanner interface {
+	Scan(dest ...any) error
+}
+
+func scanJob(row rowScanner) (*QueuedJob, error) {
+	var (
+		job                         QueuedJob
+		args, payload, state        string
+		runAt, createdAt, updatedAt int64
+	)
+	err := row.Scan(&job.ID, &job.ScriptPath, &job.Script, &args, &payload, &state, &job.Attempts,
+		&job.MaxAttempts, &job.LastError, &job.Output, &runAt, &createdAt, &updatedAt)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := json.Unmarshal([]byte(args), &job.Args); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal job args: %w", err)
+	}
+	if payload != "" {
+		job.Payload = json.RawMessage(payload)
+	}
+	job.State = JobState(state)
+	job.RunAt = time.UnixMilli(runAt)
+	job.CreatedAt = time.UnixMilli(createdAt)
+	job.UpdatedAt = time.UnixMilli(updatedAt)
+	return &job, nil
+}
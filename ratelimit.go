@@ -0,0 +1,73 @@
+package uvgo
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+)
+
+// WithRateLimit limits how fast the runner starts scripts using a token bucket refilled at perSecond tokens
+// per second and holding at most burst tokens. Runs wait for a token before starting, giving up when their
+// context ends. The limit is shared by runners derived with With.
+func WithRateLimit(perSecond float64, burst int) Option {
+	return func(r *Runner) {
+		if perSecond <= 0 {
+			r.limiter = nil
+			return
+		}
+		r.limiter = newRateLimiter(perSecond, max(burst, 1))
+	}
+}
+
+// rateLimiter is a token bucket where waiters reserve tokens in arrival order, letting the balance go
+// negative to represent the queue
+type rateLimiter struct {
+	mu     sync.Mutex
+	rate   float64
+	burst  float64
+	tokens float64
+	last   time.Time
+}
+
+func newRateLimiter(perSecond float64, burst int) *rateLimiter {
+	return &rateLimiter{
+		rate:   perSecond,
+		burst:  float64(burst),
+		tokens: float64(burst),
+		last:   time.Now(),
+	}
+}
+
+// wait blocks until a token is available or ctx ends
+func (l *rateLimiter) wait(ctx context.Context) error {
+	if l == nil {
+		return nil
+	}
+
+	l.mu.Lock()
+	now := time.Now()
+	l.tokens = min(l.burst, l.tokens+now.Sub(l.last).Seconds()*l.rate)
+	l.last = now
+	l.tokens--
+	delay := time.Duration(-l.tokens / l.rate * float64(time.Second))
+	l.mu.Unlock()
+
+	if delay <= 0 {
+		return nil
+	}
+
+	timer := time.NewTimer(delay)
+	defer timer.Stop()
+
+	select {
+	case <-timer.C:
+		return nil
+	case <-ctx.Done():
+		// hand the reserved token back so later waiters are not delayed by an abandoned run
+		l.mu.Lock()
+		l.tokens++
+		l.mu.Unlock()
+		return fmt.Errorf("rate limit wait cancelled: %w", ctx.Err())
+	}
+}
@@ -0,0 +1,215 @@
+package uvgo
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"slices"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// Script describes a named script kept in a Registry
+type Script struct {
+	Name string
+	// Version is the semantic version of the script, such as "2.1.0", letting several versions of a script be
+	// registered side by side. It may be empty when only one version is kept.
+	Version     string
+	Description string
+	// ScriptPath is the script to run, or empty when Source holds the script content
+	ScriptPath string
+	Source     string
+	Args       []string
+	// Options are applied on top of the runner's configuration whenever the script runs
+	Options []Option
+}
+
+// Registry holds named scripts that can be looked up and run by name. Scripts are referred to by name, which
+// selects the latest version, or by name@constraint, such as "etl@2.1.0", "etl@^2" or "etl@>=2.1 <3", which
+// selects the latest version satisfying the constraint. Pre-release versions are only selected when named
+// exactly, and unversioned scripts only by their bare name.
+type Registry struct {
+	mu      sync.RWMutex
+	scripts map[string][]versionedScript
+}
+
+// versionedScript is a registered script along with its parsed version
+type versionedScript struct {
+	Script
+	version   semver
+	versioned bool
+}
+
+// NewRegistry creates an empty registry
+func NewRegistry() *Registry {
+	return &Registry{scripts: make(map[string][]versionedScript)}
+}
+
+// Register adds a script, replacing any script registered under the same name and version
+func (g *Registry) Register(script Script) error {
+	if script.Name == "" {
+		return fmt.Errorf("script name is required")
+	}
+	if strings.Contains(script.Name, "@") {
+		return fmt.Errorf("script name %s contains @", script.Name)
+	}
+	if script.ScriptPath == "" && script.Source == "" {
+		return fmt.Errorf("script %s has no path or source", script.Name)
+	}
+
+	entry := versionedScript{Script: script}
+	if script.Version != "" {
+		version, err := parseSemver(script.Version)
+		if err != nil {
+			return fmt.Errorf("script %s: %w", script.Name, err)
+		}
+		entry.version, entry.versioned = version, true
+	}
+
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	versions := slices.DeleteFunc(g.scripts[script.Name], func(s versionedScript) bool {
+		return s.versioned == entry.versioned && s.version.compare(entry.version) == 0
+	})
+	versions = append(versions, entry)
+	slices.SortFunc(versions, compareVersionedScripts)
+	g.scripts[script.Name] = versions
+	return nil
+}
+
+// Unregister removes the scripts a reference selects: every version for a bare name, or every version
+// satisfying the constraint of name@constraint
+func (g *Registry) Unregister(ref string) {
+	name, constraint, hasConstraint := strings.Cut(ref, "@")
+	c, err := parseVersionConstraint(constraint)
+	if err != nil {
+		return
+	}
+
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	if !hasConstraint {
+		delete(g.scripts, name)
+		return
+	}
+	versions := slices.DeleteFunc(g.scripts[name], func(s versionedScript) bool { return s.versioned && c.matches(s.version) })
+	if len(versions) == 0 {
+		delete(g.scripts, name)
+		return
+	}
+	g.scripts[name] = versions
+}
+
+// Get returns the script a reference selects, either a bare name for its latest version or name@constraint
+// for the latest version satisfying the constraint
+func (g *Registry) Get(ref string) (Script, bool) {
+	name, constraint, hasConstraint := strings.Cut(ref, "@")
+	c, err := parseVersionConstraint(constraint)
+	if err != nil {
+		return Script{}, false
+	}
+
+	g.mu.RLock()
+	defer g.mu.RUnlock()
+	versions := g.scripts[name]
+	for i := len(versions) - 1; i >= 0; i-- {
+		s := versions[i]
+		if !hasConstraint && (!s.versioned || s.version.pre == "") || s.versioned && c.matches(s.version) {
+			return s.Script, true
+		}
+	}
+	// a script with only pre-release versions is still found by its bare name
+	if !hasConstraint && len(versions) > 0 {
+		return versions[len(versions)-1].Script, true
+	}
+	return Script{}, false
+}
+
+// List returns every registered script sorted by name and version
+func (g *Registry) List() []Script {
+	g.mu.RLock()
+	defer g.mu.RUnlock()
+
+	var scripts []Script
+	for _, versions := range g.scripts {
+		for _, s := range versions {
+			scripts = append(scripts, s.Script)
+		}
+	}
+	sort.SliceStable(scripts, func(i, j int) bool { return scripts[i].Name < scripts[j].Name })
+	return scripts
+}
+
+// compareVersionedScripts orders unversioned scripts before versioned ones, and versioned ones by version
+func compareVersionedScripts(a, b versionedScript) int {
+	switch {
+	case a.versioned != b.versioned && a.versioned:
+		return 1
+	case a.versioned != b.versioned:
+		return -1
+	default:
+		return a.version.compare(b.version)
+	}
+}
+
+// Run runs the script a reference selects with r, such as "etl" for its latest version or "etl@^2" for the
+// latest 2.x version. A non-nil input, typically JSON, is passed to the script in the UVGO_INPUT environment
+// variable.
+func (g *Registry) Run(ctx context.Context, r *Runner, ref string, input []byte) (*Result, error) {
+	script, ok := g.Get(ref)
+	if !ok {
+		return nil, fmt.Errorf("script %s is not registered", ref)
+	}
+
+	options := script.Options
+	if input != nil {
+		options = append(options[:len(options):len(options)], WithInput(input))
+	}
+	if len(options) > 0 {
+		r = r.With(options...)
+	}
+
+	if script.ScriptPath != "" {
+		return r.Run(ctx, script.ScriptPath, script.Args...)
+	}
+	return r.RunFromString(ctx, script.Source, script.Args...)
+}
+
+// WithInput passes input, typically JSON, to the script in the UVGO_INPUT environment variable and in a file
+// named by the UVGO_INPUT_FILE environment variable. Input too large for an environment variable is only
+// written to the file, and the script finds it in os.environ["UVGO_INPUT"] all the same, but reading the
+// file avoids handing it down to the subprocesses the script starts.
+func WithInput(input []byte) Option {
+	return func(r *Runner) { r.input = append([]byte{}, input...) }
+}
+
+// maxEnvInput is the size of the largest input passed in an environment variable, well below the 128 KiB
+// Linux allows for a single variable
+const maxEnvInput = 32 * 1024
+
+const inputSetup = `
+with open(os.environ["UVGO_INPUT_FILE"], encoding="utf-8", errors="surrogateescape") as _uvgo_input:
+    os.environ["UVGO_INPUT"] = _uvgo_input.read()
+`
+
+// withInput writes the script's input to a file, also passing it in UVGO_INPUT directly when it is small
+// enough and loading it into os.environ from the file otherwise
+func (x *execution) withInput(input []byte) error {
+	path, err := x.tempPath("input")
+	if err != nil {
+		return err
+	}
+	if err := os.WriteFile(path, input, 0o600); err != nil {
+		return fmt.Errorf("failed to write input: %w", err)
+	}
+
+	x.env = append(x.env, "UVGO_INPUT_FILE="+path)
+	if len(input) <= maxEnvInput {
+		x.env = append(x.env, "UVGO_INPUT="+string(input))
+	} else {
+		x.setup = append(x.setup, inputSetup)
+	}
+	return nil
+}
@@ -0,0 +1,66 @@
+package uvgo
+
+import "testing"
+
+func TestRegistryGet(t *testing.T) {
+	g := NewRegistry()
+	for _, s := range []Script{
+		{Name: "etl", Version: "1.4.0", Source: "print(1)"},
+		{Name: "etl", Version: "2.0.0", Source: "print(2)"},
+		{Name: "etl", Version: "2.1.0", Source: "print(2)"},
+		{Name: "etl", Version: "3.0.0-rc.1", Source: "print(3)"},
+		{Name: "report", Source: "print('report')"},
+		{Name: "report", Version: "1.0.0", Source: "print('report')"},
+		{Name: "beta", Version: "0.1.0-alpha", Source: "print('beta')"},
+	} {
+		if err := g.Register(s); err != nil {
+			t.Fatalf("Register(%s %s): %v", s.Name, s.Version, err)
+		}
+	}
+
+	tests := []struct {
+		ref     string
+		version string
+		found   bool
+	}{
+		{"etl", "2.1.0", true},
+		{"etl@^2", "2.1.0", true},
+		{"etl@2.0.0", "2.0.0", true},
+		{"etl@>=1 <2", "1.4.0", true},
+		{"etl@~1.4", "1.4.0", true},
+		{"etl@3", "", false},
+		{"etl@3.0.0-rc.1", "3.0.0-rc.1", true},
+		{"etl@^4", "", false},
+		{"etl@not-a-version", "", false},
+		{"report", "1.0.0", true},
+		{"report@1", "1.0.0", true},
+		{"beta", "0.1.0-alpha", true},
+		{"beta@^0.1", "", false},
+		{"missing", "", false},
+	}
+
+	for _, tt := range tests {
+		s, ok := g.Get(tt.ref)
+		if ok != tt.found {
+			t.Errorf("Get(%q) found = %v, want %v", tt.ref, ok, tt.found)
+			continue
+		}
+		if ok && s.Version != tt.version {
+			t.Errorf("Get(%q) version = %q, want %q", tt.ref, s.Version, tt.version)
+		}
+	}
+}
+
+func TestRegistryGetUnversioned(t *testing.T) {
+	g := NewRegistry()
+	if err := g.Register(Script{Name: "job", Source: "print(1)"}); err != nil {
+		t.Fatal(err)
+	}
+
+	if s, ok := g.Get("job"); !ok || s.Version != "" {
+		t.Errorf("Get(job) = %+v, %v, want the unversioned script", s, ok)
+	}
+	if _, ok := g.Get("job@*"); ok {
+		t.Error("Get(job@*) found an unversioned script")
+	}
+}
@@ -0,0 +1,146 @@
+package remote
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/joeychilson/uvgo"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+)
+
+// maxFrameSize bounds a single message, which may carry the archived temporary directory
+const maxFrameSize = 256 << 20
+
+// stdinChunkSize is the largest chunk of standard input sent in one Input message
+const stdinChunkSize = 32 * 1024
+
+// Client is a uvgo.Executor running invocations on a remote Server. Features relying on a local connection
+// to the script, such as WithBridge, are not available remotely, and Dir must exist on the server. The remote
+// process cannot be signalled, so WithSignalForwarding has no effect and stopping a server started with Serve
+// kills it at once.
+type Client struct {
+	// Conn is the connection to the server, such as one created with grpc.NewClient
+	Conn grpc.ClientConnInterface
+	// Metadata is sent with every execution, for example to carry credentials
+	Metadata metadata.MD
+}
+
+// Execute implements uvgo.Executor
+func (c *Client) Execute(ctx context.Context, inv *uvgo.Invocation) (*uvgo.ExitStatus, error) {
+	req := Request{
+		Args:    inv.Args,
+		Env:     inv.Env,
+		Dir:     inv.Dir,
+		Stdin:   inv.Stdin != nil,
+		Script:  inv.Script,
+		TempDir: inv.TempDir,
+	}
+
+	var err error
+	if inv.Script != "" {
+		if req.ScriptContent, err = os.ReadFile(inv.Script); err != nil {
+			return nil, fmt.Errorf("failed to read script file: %w", err)
+		}
+	}
+	if inv.TempDir != "" {
+		if req.Files, err = packDir(inv.TempDir); err != nil {
+			return nil, err
+		}
+	}
+
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	if len(c.Metadata) > 0 {
+		md, _ := metadata.FromOutgoingContext(ctx)
+		ctx = metadata.NewOutgoingContext(ctx, metadata.Join(md, c.Metadata))
+	}
+
+	stream, err := c.Conn.NewStream(ctx, &serviceDesc.Streams[0], ExecuteMethod,
+		grpc.CallContentSubtype(codecName), grpc.MaxCallRecvMsgSize(maxFrameSize), grpc.MaxCallSendMsgSize(maxFrameSize))
+	if err != nil {
+		return nil, fmt.Errorf("failed to reach remote server: %w", err)
+	}
+	// a failed send ends the stream, and the reason is reported by the first receive
+	if err := stream.SendMsg(&req); err == nil {
+		if inv.Stdin != nil {
+			go sendStdin(ctx, stream, inv.Stdin)
+		} else {
+			stream.CloseSend()
+		}
+	}
+	return c.follow(ctx, inv, stream)
+}
+
+// sendStdin forwards standard input to the server as it is read, so scripts conversing with the caller over
+// stdin, such as those using WithCallback, get every reply as soon as it is written
+func sendStdin(ctx context.Context, stream grpc.ClientStream, stdin io.Reader) {
+	buf := make([]byte, stdinChunkSize)
+	for ctx.Err() == nil {
+		n, err := stdin.Read(buf)
+		if n > 0 {
+			if stream.SendMsg(&Input{Data: buf[:n]}) != nil {
+				return
+			}
+		}
+		if err != nil {
+			stream.CloseSend()
+			return
+		}
+	}
+}
+
+// follow consumes the frames of an execution, forwarding output and restoring the temporary directory
+func (c *Client) follow(ctx context.Context, inv *uvgo.Invocation, stream grpc.ClientStream) (*uvgo.ExitStatus, error) {
+	started := false
+	for {
+		var frame Frame
+		err := stream.RecvMsg(&frame)
+		if err != nil {
+			if errors.Is(err, io.EOF) {
+				err = io.ErrUnexpectedEOF
+			}
+			if ctx.Err() != nil {
+				err = ctx.Err()
+			}
+			if !started {
+				return nil, fmt.Errorf("remote execution failed: %w", err)
+			}
+			// the process was running when the stream broke off, so report it as killed like a cancelled local run
+			return &uvgo.ExitStatus{ExitCode: -1}, &uvgo.ExitError{Code: -1, Err: fmt.Errorf("remote execution interrupted: %w", err)}
+		}
+
+		switch frame.Type {
+		case FrameStarted:
+			started = true
+			// the process ID belongs to the server's host, so no local process stands for the process
+			if inv.Started != nil {
+				inv.Started(0)
+			}
+		case FrameStdout:
+			if inv.Stdout != nil {
+				inv.Stdout.Write(frame.Data)
+			}
+		case FrameStderr:
+			if inv.Stderr != nil {
+				inv.Stderr.Write(frame.Data)
+			}
+		case FrameError:
+			return nil, errors.New(frame.Error)
+		case FrameExit:
+			if inv.TempDir != "" && len(frame.Files) > 0 {
+				if err := unpackDir(frame.Files, inv.TempDir); err != nil {
+					return nil, err
+				}
+			}
+			status := &uvgo.ExitStatus{ExitCode: frame.ExitCode, SystemTime: frame.SystemTime, UserTime: frame.UserTime}
+			if frame.Error != "" {
+				return status, &uvgo.ExitError{Code: frame.ExitCode, Err: errors.New(frame.Error)}
+			}
+			return status, nil
+		}
+	}
+}
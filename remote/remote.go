@@ -0,0 +1,219 @@
+// Package remote moves script execution off the calling host. A Server exposes an executor as a gRPC service,
+// and a Client implements uvgo.Executor by forwarding every invocation to a server, so a runner configured with
+// uvgo.WithExecutor(client) keeps its instrumentation, artifact collection and history while uv runs remotely.
+//
+// The service is uvgo.remote.v1.Executor, whose single bidirectional streaming Execute method runs one
+// invocation. The client sends a Request followed by Input messages carrying standard input as the caller
+// writes it, closing its side of the stream at the end of the input. The server answers with Frames: a
+// "started" frame, "stdout" and "stderr" frames as output is written, and a final "exit" frame, or an "error"
+// frame when the process could not be started. Cancelling the call cancels the execution. The run's temporary
+// directory travels with the request and the exit frame as a gzipped tar archive, so artifacts and
+// instrumentation files written remotely are collected locally.
+//
+// Messages are encoded as JSON under the "uvgo-json" content subtype rather than as protocol buffers, so the
+// service needs no generated code and clients in other languages only need a JSON codec.
+package remote
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/encoding"
+)
+
+// ExecuteMethod is the full name of the Execute method
+const ExecuteMethod = "/uvgo.remote.v1.Executor/Execute"
+
+// Request is the first message a client sends on an Execute stream
+type Request struct {
+	Args []string `json:"args"`
+	Env  []string `json:"env,omitempty"`
+	Dir  string   `json:"dir,omitempty"`
+	// Stdin reports whether the process reads standard input, which then follows in Input messages
+	Stdin bool `json:"stdin,omitempty"`
+	// Script is the client path of the script file and ScriptContent its content
+	Script        string `json:"script,omitempty"`
+	ScriptContent []byte `json:"script_content,omitempty"`
+	// TempDir is the client path of the run's temporary directory and Files its gzipped tar archive
+	TempDir string `json:"temp_dir,omitempty"`
+	Files   []byte `json:"files,omitempty"`
+}
+
+// Input is a chunk of standard input sent after the request
+type Input struct {
+	Data []byte `json:"data"`
+}
+
+// Frame types
+const (
+	FrameStarted = "started"
+	FrameStdout  = "stdout"
+	FrameStderr  = "stderr"
+	FrameExit    = "exit"
+	FrameError   = "error"
+)
+
+// Frame is a single message of an Execute response stream
+type Frame struct {
+	Type string `json:"type"`
+	// PID is set for started frames to the process ID on the server's host
+	PID int `json:"pid,omitempty"`
+	// Data is set for stdout and stderr frames
+	Data []byte `json:"data,omitempty"`
+	// ExitCode, SystemTime, UserTime and Files are set for exit frames
+	ExitCode   int           `json:"exit_code,omitempty"`
+	SystemTime time.Duration `json:"system_time,omitempty"`
+	UserTime   time.Duration `json:"user_time,omitempty"`
+	Files      []byte        `json:"files,omitempty"`
+	// Error describes why the process failed, set for error frames and unsuccessful exits
+	Error string `json:"error,omitempty"`
+}
+
+// executor is the handler type of the service, implemented by Server
+type executor interface {
+	execute(stream grpc.ServerStream) error
+}
+
+var serviceDesc = grpc.ServiceDesc{
+	ServiceName: "uvgo.remote.v1.Executor",
+	HandlerType: (*executor)(nil),
+	Streams: []grpc.StreamDesc{{
+		StreamName:    "Execute",
+		Handler:       func(srv any, stream grpc.ServerStream) error { return srv.(executor).execute(stream) },
+		ServerStreams: true,
+		ClientStreams: true,
+	}},
+}
+
+// codecName is the content subtype the messages are encoded under
+const codecName = "uvgo-json"
+
+// codec encodes messages as JSON
+type codec struct{}
+
+func (codec) Marshal(v any) ([]byte, error)      { return json.Marshal(v) }
+func (codec) Unmarshal(data []byte, v any) error { return json.Unmarshal(data, v) }
+func (codec) Name() string                       { return codecName }
+
+func init() {
+	encoding.RegisterCodec(codec{})
+}
+
+// packDir archives the contents of dir as a gzipped tar
+func packDir(dir string) ([]byte, error) {
+	var buf bytes.Buffer
+	gz := gzip.NewWriter(&buf)
+	tw := tar.NewWriter(gz)
+
+	err := filepath.WalkDir(dir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if path == dir || !(d.IsDir() || d.Type().IsRegular()) {
+			// sockets and other special files cannot cross hosts
+			return nil
+		}
+
+		info, err := d.Info()
+		if err != nil {
+			return err
+		}
+		header, err := tar.FileInfoHeader(info, "")
+		if err != nil {
+			return err
+		}
+		rel, err := filepath.Rel(dir, path)
+		if err != nil {
+			return err
+		}
+		header.Name = filepath.ToSlash(rel)
+		if err := tw.WriteHeader(header); err != nil {
+			return err
+		}
+
+		if d.IsDir() {
+			return nil
+		}
+		f, err := os.Open(path)
+		if err != nil {
+			return err
+		}
+		defer f.Close()
+		_, err = io.Copy(tw, f)
+		return err
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to archive %s: %w", dir, err)
+	}
+
+	if err := tw.Close(); err != nil {
+		return nil, err
+	}
+	if err := gz.Close(); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// unpackDir extracts a gzipped tar created by packDir into dir, overwriting existing files
+func unpackDir(data []byte, dir string) error {
+	gz, err := gzip.NewReader(bytes.NewReader(data))
+	if err != nil {
+		return fmt.Errorf("failed to read archive: %w", err)
+	}
+	tr := tar.NewReader(gz)
+
+	for {
+		header, err := tr.Next()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return fmt.Errorf("failed to read archive: %w", err)
+		}
+
+		name := filepath.FromSlash(header.Name)
+		if !filepath.IsLocal(name) {
+			return fmt.Errorf("archive entry escapes the directory: %s", header.Name)
+		}
+		path := filepath.Join(dir, name)
+
+		switch header.Typeflag {
+		case tar.TypeDir:
+			if err := os.MkdirAll(path, 0o755); err != nil {
+				return err
+			}
+		case tar.TypeReg:
+			if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+				return err
+			}
+			f, err := os.OpenFile(path, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, fs.FileMode(header.Mode).Perm())
+			if err != nil {
+				return err
+			}
+			_, err = io.Copy(f, tr)
+			f.Close()
+			if err != nil {
+				return err
+			}
+		}
+	}
+}
+
+// rebase replaces the prefix from of every path found in s with to
+func rebase(s, from, to string) string {
+	if from == "" {
+		return s
+	}
+	return strings.ReplaceAll(s, from, to)
+}
@@ -0,0 +1,201 @@
+package remote
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+
+	"github.com/joeychilson/uvgo"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// ServerOptions configures a Server
+type ServerOptions struct {
+	// Authenticate, when set, is called with the context of every execution, whose incoming metadata carries
+	// the client's credentials, and rejects the execution as unauthenticated when it returns an error
+	Authenticate func(ctx context.Context) error
+}
+
+// Server executes invocations received from clients with an executor. Register it with a gRPC server whose
+// grpc.MaxRecvMsgSize leaves room for the scripts and temporary directories of the runs, since the default
+// limit is 4 MiB.
+type Server struct {
+	executor uvgo.Executor
+	opts     ServerOptions
+}
+
+// NewServer creates a server running uv with executor, typically uvgo.LocalExecutor{}
+func NewServer(executor uvgo.Executor, opts ServerOptions) *Server {
+	return &Server{executor: executor, opts: opts}
+}
+
+// Register registers the Executor service on a gRPC server
+func (s *Server) Register(g grpc.ServiceRegistrar) {
+	g.RegisterService(&serviceDesc, s)
+}
+
+func (s *Server) execute(stream grpc.ServerStream) error {
+	if s.opts.Authenticate != nil {
+		if err := s.opts.Authenticate(stream.Context()); err != nil {
+			return status.Error(codes.Unauthenticated, err.Error())
+		}
+	}
+
+	var req Request
+	if err := stream.RecvMsg(&req); err != nil {
+		return status.Errorf(codes.InvalidArgument, "invalid execute request: %v", err)
+	}
+
+	frames := &frameWriter{stream: stream}
+	frame, err := s.run(stream, &req, frames)
+	if err != nil {
+		frame = Frame{Type: FrameError, Error: err.Error()}
+	}
+	return frames.send(frame)
+}
+
+// run runs a request in a scratch directory standing in for the client's paths, returning the final frame
+func (s *Server) run(stream grpc.ServerStream, req *Request, frames *frameWriter) (Frame, error) {
+	scratch, err := os.MkdirTemp("", "uvgo-remote-*")
+	if err != nil {
+		return Frame{}, fmt.Errorf("failed to create execution directory: %w", err)
+	}
+	defer os.RemoveAll(scratch)
+
+	tempDir := filepath.Join(scratch, "run")
+	if err := os.Mkdir(tempDir, 0o755); err != nil {
+		return Frame{}, fmt.Errorf("failed to create execution directory: %w", err)
+	}
+	if len(req.Files) > 0 {
+		if err := unpackDir(req.Files, tempDir); err != nil {
+			return Frame{}, err
+		}
+	}
+
+	var script string
+	if req.Script != "" {
+		script = filepath.Join(scratch, "script", filepath.Base(req.Script))
+		if err := os.MkdirAll(filepath.Dir(script), 0o755); err != nil {
+			return Frame{}, fmt.Errorf("failed to write script: %w", err)
+		}
+		if err := os.WriteFile(script, req.ScriptContent, 0o644); err != nil {
+			return Frame{}, fmt.Errorf("failed to write script: %w", err)
+		}
+	}
+
+	local := func(s string) string {
+		if req.Script != "" && s == req.Script {
+			return script
+		}
+		return rebase(s, req.TempDir, tempDir)
+	}
+
+	inv := &uvgo.Invocation{
+		Dir:     local(req.Dir),
+		Script:  script,
+		Stdout:  frames.output(FrameStdout),
+		Stderr:  frames.output(FrameStderr),
+		Started: func(pid int) { frames.send(Frame{Type: FrameStarted, PID: pid}) },
+	}
+	if req.TempDir != "" {
+		inv.TempDir = tempDir
+	}
+	for _, arg := range req.Args {
+		inv.Args = append(inv.Args, local(arg))
+	}
+	for _, kv := range req.Env {
+		inv.Env = append(inv.Env, local(kv))
+	}
+	if req.Stdin {
+		stdin, err := receiveStdin(stream)
+		if err != nil {
+			return Frame{}, err
+		}
+		defer stdin.Close()
+		inv.Stdin = stdin
+	}
+
+	exit, err := s.executor.Execute(stream.Context(), inv)
+	if exit == nil {
+		if err == nil {
+			err = errors.New("executor returned no exit status")
+		}
+		return Frame{}, err
+	}
+
+	frame := Frame{
+		Type:       FrameExit,
+		ExitCode:   exit.ExitCode,
+		SystemTime: exit.SystemTime,
+		UserTime:   exit.UserTime,
+	}
+	if err != nil {
+		frame.Error = err.Error()
+		if frame.ExitCode == 0 {
+			frame.ExitCode = -1
+		}
+	}
+	if req.TempDir != "" {
+		if frame.Files, err = packDir(tempDir); err != nil {
+			return Frame{}, err
+		}
+	}
+	return frame, nil
+}
+
+// receiveStdin returns a pipe fed with the Input messages of a stream as they arrive, closed once the client
+// closes its side of the stream. The pipe is a file so executors hand it to the process directly, rather than
+// copying it in a goroutine the process exit would wait for.
+func receiveStdin(stream grpc.ServerStream) (*os.File, error) {
+	pr, pw, err := os.Pipe()
+	if err != nil {
+		return nil, fmt.Errorf("failed to create stdin pipe: %w", err)
+	}
+	go func() {
+		defer pw.Close()
+		for {
+			var input Input
+			if err := stream.RecvMsg(&input); err != nil {
+				return
+			}
+			if _, err := pw.Write(input.Data); err != nil {
+				return
+			}
+		}
+	}()
+	return pr, nil
+}
+
+// frameWriter serializes frames onto a response stream
+type frameWriter struct {
+	mu     sync.Mutex
+	stream grpc.ServerStream
+}
+
+func (f *frameWriter) send(frame Frame) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.stream.SendMsg(&frame)
+}
+
+// output returns a writer sending everything written to it as frames of the given type
+func (f *frameWriter) output(frameType string) *outputWriter {
+	return &outputWriter{stream: f, frameType: frameType}
+}
+
+type outputWriter struct {
+	stream    *frameWriter
+	frameType string
+}
+
+func (o *outputWriter) Write(p []byte) (int, error) {
+	if err := o.stream.send(Frame{Type: o.frameType, Data: p}); err != nil {
+		return 0, err
+	}
+	return len(p), nil
+}
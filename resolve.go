@@ -0,0 +1,214 @@
+package uvgo
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"path"
+	"runtime"
+	"slices"
+	"strings"
+
+	"github.com/BurntSushi/toml"
+)
+
+// Resolution is the pinned transitive closure of a set of dependencies
+type Resolution struct {
+	// Python is the Python version the dependencies were resolved for, empty for the default interpreter
+	Python   string
+	Packages []ResolvedPackage
+	// DownloadSize is the total size in bytes of the files chosen for every package, as reported by the index
+	DownloadSize int64
+}
+
+// ResolvedPackage is a package of a Resolution and the distribution file chosen for it
+type ResolvedPackage struct {
+	Name    string
+	Version string
+	// File is the name of the wheel chosen for the host platform, or of the source distribution when no
+	// wheel is compatible
+	File string
+	URL  string
+	// Size is the size of File in bytes, zero when the index does not report it
+	Size   int64
+	SHA256 string
+	// Wheel reports whether File is a wheel rather than a source distribution that must be built
+	Wheel bool
+}
+
+// pylock is the subset of the pylock.toml lock file format written by uv used by Resolve
+type pylock struct {
+	Packages []struct {
+		Name    string       `toml:"name"`
+		Version string       `toml:"version"`
+		Sdist   *pylockFile  `toml:"sdist"`
+		Wheels  []pylockFile `toml:"wheels"`
+	} `toml:"packages"`
+}
+
+type pylockFile struct {
+	Name   string            `toml:"name"`
+	URL    string            `toml:"url"`
+	Path   string            `toml:"path"`
+	Size   int64             `toml:"size"`
+	Hashes map[string]string `toml:"hashes"`
+}
+
+// filename returns the name of the file, taken from its URL or path when not given
+func (f pylockFile) filename() string {
+	if f.Name != "" {
+		return f.Name
+	}
+	location := f.URL
+	if location == "" {
+		location = f.Path
+	}
+	location, _, _ = strings.Cut(location, "#")
+	return path.Base(location)
+}
+
+// Resolve pins the transitive closure of deps, defaulting to the runner's dependencies, for a Python version,
+// defaulting to the runner's, and reports the file chosen for each package on the host platform and the total
+// download size without installing or running anything. It suits cost estimation and policy checks before a run.
+func (r *Runner) Resolve(ctx context.Context, deps []string, pythonVersion string) (*Resolution, error) {
+	if len(deps) == 0 {
+		deps = r.dependencies
+	}
+	if len(deps) == 0 {
+		return nil, errors.New("no dependencies to resolve")
+	}
+	if pythonVersion == "" {
+		pythonVersion = r.pythonVersion
+	}
+
+	args := []string{"--format", "pylock.toml"}
+	if pythonVersion != "" {
+		args = append(args, "--python-version", pythonVersion)
+	}
+	output, err := r.pipCompile(ctx, deps, args...)
+	if err != nil {
+		return nil, err
+	}
+
+	var lock pylock
+	if _, err := toml.Decode(output, &lock); err != nil {
+		return nil, fmt.Errorf("failed to parse resolution: %w", err)
+	}
+
+	res := &Resolution{Python: pythonVersion}
+	for _, p := range lock.Packages {
+		pkg := ResolvedPackage{Name: p.Name, Version: p.Version}
+
+		var chosen *pylockFile
+		best := 0
+		for i, wheel := range p.Wheels {
+			if score := wheelScore(wheel.filename(), pythonVersion); score > best {
+				chosen, best = &p.Wheels[i], score
+			}
+		}
+		if chosen != nil {
+			pkg.Wheel = true
+		} else {
+			chosen = p.Sdist
+		}
+		if chosen != nil {
+			pkg.File = chosen.filename()
+			pkg.URL = chosen.URL
+			pkg.Size = chosen.Size
+			pkg.SHA256 = chosen.Hashes["sha256"]
+		}
+
+		res.DownloadSize += pkg.Size
+		res.Packages = append(res.Packages, pkg)
+	}
+	return res, nil
+}
+
+// wheelScore rates how well a wheel suits the host platform and a Python version, with zero meaning it is
+// incompatible. Platform-specific wheels for the exact interpreter rate highest, then stable ABI wheels, then
+// pure Python wheels.
+func wheelScore(filename, pythonVersion string) int {
+	parts := strings.Split(strings.TrimSuffix(filename, ".whl"), "-")
+	if len(parts) < 5 || !strings.HasSuffix(filename, ".whl") {
+		return 0
+	}
+	pyTags := strings.Split(parts[len(parts)-3], ".")
+	abiTags := strings.Split(parts[len(parts)-2], ".")
+	platTags := strings.Split(parts[len(parts)-1], ".")
+
+	platform := 0
+	for _, tag := range platTags {
+		if tag == "any" {
+			platform = max(platform, 1)
+		} else if hostPlatformTag(tag) {
+			platform = 2
+		}
+	}
+	if platform == 0 {
+		return 0
+	}
+
+	cp := ""
+	if major, minor, ok := strings.Cut(pythonVersion, "."); ok {
+		minor, _, _ = strings.Cut(minor, ".")
+		cp = "cp" + major + minor
+	}
+
+	score := 0
+	for _, py := range pyTags {
+		switch {
+		case py == "py3" || py == "py2.py3":
+			score = max(score, 1)
+		case strings.HasPrefix(py, "cp") && (cp == "" || py == cp):
+			if slices.Contains(abiTags, "abi3") {
+				score = max(score, 2)
+			} else {
+				score = max(score, 3)
+			}
+		case strings.HasPrefix(py, "cp") && slices.Contains(abiTags, "abi3") && cpVersion(py) <= cpVersion(cp):
+			score = max(score, 2)
+		}
+	}
+	if score == 0 {
+		return 0
+	}
+	return score*10 + platform
+}
+
+// hostPlatformTag reports whether a wheel platform tag matches the host operating system and architecture
+func hostPlatformTag(tag string) bool {
+	arch := map[string][]string{
+		"amd64": {"x86_64", "amd64"},
+		"arm64": {"aarch64", "arm64", "universal2"},
+		"386":   {"i686", "win32"},
+	}[runtime.GOARCH]
+
+	var osMatch bool
+	switch runtime.GOOS {
+	case "linux":
+		osMatch = strings.HasPrefix(tag, "manylinux") || strings.HasPrefix(tag, "musllinux") || strings.HasPrefix(tag, "linux")
+	case "darwin":
+		osMatch = strings.HasPrefix(tag, "macosx")
+		if strings.HasSuffix(tag, "universal2") {
+			return osMatch
+		}
+	case "windows":
+		osMatch = strings.HasPrefix(tag, "win")
+	}
+	if !osMatch {
+		return false
+	}
+	for _, a := range arch {
+		if strings.HasSuffix(tag, "_"+a) || tag == a {
+			return true
+		}
+	}
+	return false
+}
+
+// cpVersion returns the minor version of a CPython tag such as "cp312", for comparing tags of Python 3
+func cpVersion(tag string) int {
+	var minor int
+	fmt.Sscanf(strings.TrimPrefix(tag, "cp3"), "%d", &minor)
+	return minor
+}
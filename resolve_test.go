@@ -0,0 +1,55 @@
+package uvgo
+
+import (
+	"runtime"
+	"testing"
+)
+
+// hostWheelPlatform returns a wheel platform tag for the host and one for another platform
+func hostWheelPlatform(t *testing.T) (host, foreign string) {
+	t.Helper()
+	switch runtime.GOOS + "/" + runtime.GOARCH {
+	case "linux/amd64":
+		return "manylinux_2_17_x86_64", "win_amd64"
+	case "linux/arm64":
+		return "manylinux_2_17_aarch64", "win_amd64"
+	case "darwin/amd64":
+		return "macosx_10_9_x86_64", "manylinux_2_17_x86_64"
+	case "darwin/arm64":
+		return "macosx_11_0_arm64", "manylinux_2_17_x86_64"
+	case "windows/amd64":
+		return "win_amd64", "manylinux_2_17_x86_64"
+	}
+	t.Skipf("no wheel platform tag for %s/%s", runtime.GOOS, runtime.GOARCH)
+	return "", ""
+}
+
+func TestWheelScore(t *testing.T) {
+	host, foreign := hostWheelPlatform(t)
+
+	tests := []struct {
+		filename      string
+		pythonVersion string
+		want          int
+	}{
+		{"numpy-2.0.0-cp312-cp312-" + host + ".whl", "3.12", 32},
+		{"numpy-2.0.0-cp312-cp312-" + host + ".whl", "3.12.4", 32},
+		{"numpy-2.0.0-cp312-cp312-" + host + ".whl", "", 32},
+		{"numpy-2.0.0-1-cp312-cp312-" + host + ".whl", "3.12", 32},
+		{"numpy-2.0.0-cp311-cp311-" + host + ".whl", "3.12", 0},
+		{"numpy-2.0.0-cp312-cp312-" + foreign + ".whl", "3.12", 0},
+		{"cryptography-42.0.0-cp39-abi3-" + host + ".whl", "3.12", 22},
+		{"cryptography-42.0.0-cp313-abi3-" + host + ".whl", "3.12", 0},
+		{"requests-2.31.0-py3-none-any.whl", "3.12", 11},
+		{"six-1.16.0-py2.py3-none-any.whl", "3.12", 11},
+		{"legacy-1.0-py2-none-any.whl", "3.12", 0},
+		{"requests-2.31.0.tar.gz", "3.12", 0},
+		{"broken.whl", "3.12", 0},
+	}
+
+	for _, tt := range tests {
+		if got := wheelScore(tt.filename, tt.pythonVersion); got != tt.want {
+			t.Errorf("wheelScore(%q, %q) = %d, want %d", tt.filename, tt.pythonVersion, got, tt.want)
+		}
+	}
+}
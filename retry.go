@@ -0,0 +1,131 @@
+package uvgo
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"regexp"
+	"slices"
+	"time"
+)
+
+// RetryPolicy controls which failed runs WithRetry runs again. A failure is retried when it matches any of
+// Exceptions, StderrPattern or Retryable, or when none of them is set, unless its exception is listed in
+// NeverExceptions. Runs ended by the caller's context are never retried.
+type RetryPolicy struct {
+	// MaxAttempts is the total number of attempts, defaulting to 3
+	MaxAttempts int
+	// Delay is the delay before the first retry, doubling with every attempt, defaulting to 1 second
+	Delay time.Duration
+	// MaxDelay caps the delay between attempts when positive
+	MaxDelay time.Duration
+	// Exceptions lists the Python exception classes worth retrying, such as "ConnectionError", matched like
+	// Traceback.Is
+	Exceptions []string
+	// NeverExceptions lists the Python exception classes that are never retried, such as "ValueError"
+	NeverExceptions []string
+	// StderrPattern retries failures whose stderr it matches
+	StderrPattern *regexp.Regexp
+	// Retryable reports whether a failure is worth retrying
+	Retryable func(result *Result, err error) bool
+}
+
+// WithRetry runs failed scripts again according to policy. Every attempt is a complete run subject to the
+// runner timeout, and the result and error of the last attempt are returned. The readers of input channels are
+// rewound before every retry, so runs with input channels are only retried when every reader is an io.Seeker,
+// such as a *bytes.Reader or a file, rather than running again with input the first attempt consumed.
+func WithRetry(policy RetryPolicy) Option {
+	return WithMiddleware(Retry(policy))
+}
+
+// Retry returns middleware that runs failed scripts again according to policy, for ordering retries relative
+// to other middleware
+func Retry(policy RetryPolicy) Middleware {
+	if policy.MaxAttempts <= 0 {
+		policy.MaxAttempts = 3
+	}
+	if policy.Delay <= 0 {
+		policy.Delay = time.Second
+	}
+
+	return func(next RunFunc) RunFunc {
+		return func(ctx context.Context, req *RunRequest) (*Result, error) {
+			offsets, rewindable := inputOffsets(req.inputs)
+			delay := policy.Delay
+			for attempt := 1; ; attempt++ {
+				result, err := next(ctx, req)
+				if err == nil || attempt >= policy.MaxAttempts || ctx.Err() != nil || !rewindable || !policy.retryable(result, err) {
+					return result, err
+				}
+
+				timer := time.NewTimer(delay)
+				select {
+				case <-ctx.Done():
+					timer.Stop()
+					return result, err
+				case <-timer.C:
+				}
+				if rewindErr := rewindInputs(req.inputs, offsets); rewindErr != nil {
+					return result, errors.Join(err, rewindErr)
+				}
+
+				delay *= 2
+				if policy.MaxDelay > 0 {
+					delay = min(delay, policy.MaxDelay)
+				}
+			}
+		}
+	}
+}
+
+// inputOffsets records the position of every input reader, reporting false when one cannot be rewound
+func inputOffsets(inputs []io.Reader) ([]int64, bool) {
+	offsets := make([]int64, len(inputs))
+	for i, input := range inputs {
+		seeker, ok := input.(io.Seeker)
+		if !ok {
+			return nil, false
+		}
+		// pipes are files too, but fail to report a position
+		offset, err := seeker.Seek(0, io.SeekCurrent)
+		if err != nil {
+			return nil, false
+		}
+		offsets[i] = offset
+	}
+	return offsets, true
+}
+
+// rewindInputs moves every input reader back to the position recorded before the first attempt
+func rewindInputs(inputs []io.Reader, offsets []int64) error {
+	for i, input := range inputs {
+		if _, err := input.(io.Seeker).Seek(offsets[i], io.SeekStart); err != nil {
+			return fmt.Errorf("failed to rewind input channel: %w", err)
+		}
+	}
+	return nil
+}
+
+// retryable reports whether a failed run should be attempted again
+func (p RetryPolicy) retryable(result *Result, err error) bool {
+	var tb *Traceback
+	var pythonErr *PythonError
+	if errors.As(err, &pythonErr) {
+		tb = pythonErr.Traceback
+	}
+	if tb != nil && slices.ContainsFunc(p.NeverExceptions, tb.Is) {
+		return false
+	}
+
+	if len(p.Exceptions) == 0 && p.StderrPattern == nil && p.Retryable == nil {
+		return true
+	}
+	if tb != nil && slices.ContainsFunc(p.Exceptions, tb.Is) {
+		return true
+	}
+	if p.StderrPattern != nil && result != nil && p.StderrPattern.MatchString(result.Stderr) {
+		return true
+	}
+	return p.Retryable != nil && p.Retryable(result, err)
+}
@@ -0,0 +1,146 @@
+package uvgo
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"os"
+	"regexp"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestRetryRewindsInputs(t *testing.T) {
+	errFailed := errors.New("failed")
+
+	tests := []struct {
+		name     string
+		inputs   func(t *testing.T) []io.Reader
+		failures int
+		attempts int
+		reads    []string
+	}{
+		{
+			name:     "no inputs",
+			inputs:   func(*testing.T) []io.Reader { return nil },
+			failures: 2,
+			attempts: 3,
+			reads:    []string{"", "", ""},
+		},
+		{
+			name:     "seekable input rewound",
+			inputs:   func(*testing.T) []io.Reader { return []io.Reader{bytes.NewReader([]byte("abc"))} },
+			failures: 1,
+			attempts: 2,
+			reads:    []string{"abc", "abc"},
+		},
+		{
+			name: "rewound to the starting offset",
+			inputs: func(t *testing.T) []io.Reader {
+				r := strings.NewReader("skipped:data")
+				if _, err := r.Seek(int64(len("skipped:")), io.SeekStart); err != nil {
+					t.Fatal(err)
+				}
+				return []io.Reader{r}
+			},
+			failures: 1,
+			attempts: 2,
+			reads:    []string{"data", "data"},
+		},
+		{
+			name: "unseekable input not retried",
+			inputs: func(*testing.T) []io.Reader {
+				return []io.Reader{bytes.NewReader([]byte("a")), io.MultiReader(strings.NewReader("b"))}
+			},
+			failures: 1,
+			attempts: 1,
+			reads:    []string{"ab"},
+		},
+		{
+			name: "pipe not retried",
+			inputs: func(t *testing.T) []io.Reader {
+				pr, pw, err := os.Pipe()
+				if err != nil {
+					t.Fatal(err)
+				}
+				t.Cleanup(func() { pr.Close() })
+				pw.WriteString("piped")
+				pw.Close()
+				return []io.Reader{pr}
+			},
+			failures: 1,
+			attempts: 1,
+			reads:    []string{"piped"},
+		},
+		{
+			name:     "gives up after max attempts",
+			inputs:   func(*testing.T) []io.Reader { return []io.Reader{bytes.NewReader([]byte("x"))} },
+			failures: 5,
+			attempts: 3,
+			reads:    []string{"x", "x", "x"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := &RunRequest{ID: "run", inputs: tt.inputs(t)}
+
+			var reads []string
+			next := func(ctx context.Context, req *RunRequest) (*Result, error) {
+				var read strings.Builder
+				for _, input := range req.inputs {
+					io.Copy(&read, input)
+				}
+				reads = append(reads, read.String())
+				if len(reads) <= tt.failures {
+					return &Result{}, errFailed
+				}
+				return &Result{}, nil
+			}
+
+			_, err := Retry(RetryPolicy{Delay: time.Nanosecond})(next)(context.Background(), req)
+			if wantErr := tt.attempts <= tt.failures; (err != nil) != wantErr {
+				t.Errorf("Retry() error = %v, want error %v", err, wantErr)
+			}
+			if len(reads) != tt.attempts {
+				t.Errorf("attempts = %d, want %d", len(reads), tt.attempts)
+			}
+			if !slices.Equal(reads, tt.reads) {
+				t.Errorf("reads = %q, want %q", reads, tt.reads)
+			}
+		})
+	}
+}
+
+func TestRetryPolicyRetryable(t *testing.T) {
+	pythonErr := func(exception string) error {
+		return &PythonError{Traceback: &Traceback{Exception: exception}, Err: errors.New("exit status 1")}
+	}
+
+	tests := []struct {
+		name   string
+		policy RetryPolicy
+		result *Result
+		err    error
+		want   bool
+	}{
+		{"any failure by default", RetryPolicy{}, &Result{}, errors.New("boom"), true},
+		{"never exception", RetryPolicy{NeverExceptions: []string{"ValueError"}}, &Result{}, pythonErr("ValueError"), false},
+		{"listed exception", RetryPolicy{Exceptions: []string{"ConnectionError"}}, &Result{}, pythonErr("requests.exceptions.ConnectionError"), true},
+		{"unlisted exception", RetryPolicy{Exceptions: []string{"ConnectionError"}}, &Result{}, pythonErr("KeyError"), false},
+		{"stderr pattern", RetryPolicy{StderrPattern: regexp.MustCompile(`(?i)timed out`)}, &Result{Stderr: "request Timed Out"}, errors.New("boom"), true},
+		{"stderr pattern without result", RetryPolicy{StderrPattern: regexp.MustCompile(`timed out`)}, nil, errors.New("boom"), false},
+		{"retryable func", RetryPolicy{Retryable: func(*Result, error) bool { return true }}, &Result{}, errors.New("boom"), true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.policy.retryable(tt.result, tt.err); got != tt.want {
+				t.Errorf("retryable() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
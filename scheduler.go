@@ -0,0 +1,221 @@
+package uvgo
+
+import (
+	"context"
+	"fmt"
+	"math/rand/v2"
+	"sync"
+	"time"
+)
+
+// jobHistoryLimit is the number of runs kept per scheduled job
+const jobHistoryLimit = 100
+
+// OverlapPolicy controls what happens when a job is due while its previous run is still executing
+type OverlapPolicy int
+
+const (
+	// OverlapSkip drops the activation, recording it as skipped. This is the default.
+	OverlapSkip OverlapPolicy = iota
+	// OverlapQueue runs the activation once the previous run finishes
+	OverlapQueue
+	// OverlapConcurrent runs the activation alongside the previous run
+	OverlapConcurrent
+)
+
+// Job describes a script run on a schedule
+type Job struct {
+	// Name uniquely identifies the job within a scheduler
+	Name string
+	// ScriptPath is the script to run, or empty when Script holds the script content
+	ScriptPath string
+	Script     string
+	Args       []string
+	// Schedule determines when the job runs, see ParseSchedule and Every
+	Schedule Schedule
+	Overlap  OverlapPolicy
+	// Jitter delays each activation by a random duration up to this value, spreading load across jobs
+	Jitter time.Duration
+}
+
+// JobRun represents a single activation of a scheduled job
+type JobRun struct {
+	Job       string
+	Scheduled time.Time
+	Started   time.Time
+	Finished  time.Time
+	// Skipped reports whether the activation was dropped because the previous run was still executing
+	Skipped bool
+	Result  *Result
+	Err     error
+}
+
+// Scheduler runs scripts on cron expressions or intervals
+type Scheduler struct {
+	runner *Runner
+
+	mu   sync.Mutex
+	ctx  context.Context
+	wg   sync.WaitGroup
+	jobs map[string]*jobState
+}
+
+type jobState struct {
+	job     Job
+	running int
+	pending []time.Time
+	history []JobRun
+}
+
+// NewScheduler creates a scheduler running jobs with r. The runner timeout applies to every run.
+func NewScheduler(r *Runner) *Scheduler {
+	return &Scheduler{runner: r, jobs: make(map[string]*jobState)}
+}
+
+// Add registers a job, starting it immediately when the scheduler is running
+func (s *Scheduler) Add(job Job) error {
+	if job.Name == "" {
+		return fmt.Errorf("job name is required")
+	}
+	if job.Schedule == nil {
+		return fmt.Errorf("job %s has no schedule", job.Name)
+	}
+	if job.ScriptPath == "" && job.Script == "" {
+		return fmt.Errorf("job %s has no script", job.Name)
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, ok := s.jobs[job.Name]; ok {
+		return fmt.Errorf("job %s already exists", job.Name)
+	}
+
+	state := &jobState{job: job}
+	s.jobs[job.Name] = state
+	if s.ctx != nil {
+		s.start(state)
+	}
+	return nil
+}
+
+// Run starts every job and blocks until ctx is cancelled, then waits for in-flight runs to be cancelled and
+// finish
+func (s *Scheduler) Run(ctx context.Context) error {
+	s.mu.Lock()
+	if s.ctx != nil {
+		s.mu.Unlock()
+		return fmt.Errorf("scheduler is already running")
+	}
+	s.ctx = ctx
+	for _, state := range s.jobs {
+		s.start(state)
+	}
+	s.mu.Unlock()
+
+	<-ctx.Done()
+	s.wg.Wait()
+
+	s.mu.Lock()
+	s.ctx = nil
+	s.mu.Unlock()
+	return nil
+}
+
+// History returns the most recent runs of a job, oldest first
+func (s *Scheduler) History(name string) []JobRun {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	state, ok := s.jobs[name]
+	if !ok {
+		return nil
+	}
+	return append([]JobRun(nil), state.history...)
+}
+
+// start launches the loop activating a job, called with s.mu held
+func (s *Scheduler) start(state *jobState) {
+	ctx := s.ctx
+	s.wg.Add(1)
+	go func() {
+		defer s.wg.Done()
+
+		for {
+			now := time.Now()
+			next := state.job.Schedule.Next(now)
+			if next.IsZero() {
+				return
+			}
+
+			delay := next.Sub(now)
+			if state.job.Jitter > 0 {
+				delay += rand.N(state.job.Jitter)
+			}
+
+			timer := time.NewTimer(delay)
+			select {
+			case <-timer.C:
+				s.activate(ctx, state, next)
+			case <-ctx.Done():
+				timer.Stop()
+				return
+			}
+		}
+	}()
+}
+
+// activate applies the job's overlap policy to an activation
+func (s *Scheduler) activate(ctx context.Context, state *jobState, scheduled time.Time) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if state.running > 0 {
+		switch state.job.Overlap {
+		case OverlapSkip:
+			s.recordLocked(state, JobRun{Job: state.job.Name, Scheduled: scheduled, Skipped: true})
+			return
+		case OverlapQueue:
+			state.pending = append(state.pending, scheduled)
+			return
+		}
+	}
+
+	state.running++
+	s.wg.Add(1)
+	go s.execute(ctx, state, scheduled)
+}
+
+// execute runs a job, followed by any activations queued behind it
+func (s *Scheduler) execute(ctx context.Context, state *jobState, scheduled time.Time) {
+	defer s.wg.Done()
+
+	for {
+		run := JobRun{Job: state.job.Name, Scheduled: scheduled, Started: time.Now()}
+		if state.job.ScriptPath != "" {
+			run.Result, run.Err = s.runner.Run(ctx, state.job.ScriptPath, state.job.Args...)
+		} else {
+			run.Result, run.Err = s.runner.RunFromString(ctx, state.job.Script, state.job.Args...)
+		}
+		run.Finished = time.Now()
+
+		s.mu.Lock()
+		s.recordLocked(state, run)
+		if len(state.pending) == 0 || ctx.Err() != nil {
+			state.pending = nil
+			state.running--
+			s.mu.Unlock()
+			return
+		}
+		scheduled = state.pending[0]
+		state.pending = state.pending[1:]
+		s.mu.Unlock()
+	}
+}
+
+func (s *Scheduler) recordLocked(state *jobState, run JobRun) {
+	state.history = append(state.history, run)
+	if over := len(state.history) - jobHistoryLimit; over > 0 {
+		state.history = append(state.history[:0], state.history[over:]...)
+	}
+}
@@ -0,0 +1,219 @@
+package uvgo
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// schemaDirective prefixes the stderr line a script uses to declare the schema of its output
+const schemaDirective = "@uvgo:schema"
+
+// SchemaSnippet is Python code declaring the schema version a script's JSON output follows. Prepend it to a
+// script and call declare_schema("orders", 2) before printing the output.
+const SchemaSnippet = `import sys
+
+
+def declare_schema(name, version):
+    """Declare the name and version of the output schema to the Go runner."""
+    sys.stderr.write("@uvgo:schema %s@%d\n" % (name, version))
+    sys.stderr.flush()
+`
+
+// SchemaVersion identifies a version of a named output schema, such as orders@2
+type SchemaVersion struct {
+	Name    string
+	Version int
+}
+
+// String formats the schema version as name@version
+func (s SchemaVersion) String() string {
+	return s.Name + "@" + strconv.Itoa(s.Version)
+}
+
+// parseSchemaVersion parses the payload of a schema line
+func parseSchemaVersion(payload string) SchemaVersion {
+	name, version, _ := strings.Cut(strings.TrimSpace(payload), "@")
+	n, _ := strconv.Atoi(version)
+	return SchemaVersion{Name: name, Version: n}
+}
+
+// withSchemaHint makes runs record the output schema declared by the script
+func withSchemaHint() Option {
+	return func(r *Runner) { r.schemaHint = true }
+}
+
+// OutputSchema is a version of a named schema script output can follow
+type OutputSchema struct {
+	Name    string
+	Version int
+	// Validate checks a JSON document following this version, accepting any document when nil
+	Validate func(data json.RawMessage) error
+}
+
+// Migration converts a JSON document following one version of a schema to the next version
+type Migration func(data json.RawMessage) (json.RawMessage, error)
+
+// ValidateAs returns a validator accepting the JSON documents that decode into T without unknown fields
+func ValidateAs[T any]() func(data json.RawMessage) error {
+	return func(data json.RawMessage) error {
+		dec := json.NewDecoder(bytes.NewReader(data))
+		dec.DisallowUnknownFields()
+		var v T
+		return dec.Decode(&v)
+	}
+}
+
+// SchemaRegistry holds named, versioned output schemas and the migrations between consecutive versions, so
+// long-lived pipelines keep accepting the output of scripts emitting an older version
+type SchemaRegistry struct {
+	mu         sync.RWMutex
+	schemas    map[SchemaVersion]OutputSchema
+	migrations map[SchemaVersion]Migration
+	latest     map[string]int
+}
+
+// NewSchemaRegistry creates an empty schema registry
+func NewSchemaRegistry() *SchemaRegistry {
+	return &SchemaRegistry{
+		schemas:    make(map[SchemaVersion]OutputSchema),
+		migrations: make(map[SchemaVersion]Migration),
+		latest:     make(map[string]int),
+	}
+}
+
+// Register adds a schema version, replacing any schema registered under the same name and version
+func (s *SchemaRegistry) Register(schema OutputSchema) error {
+	if schema.Name == "" {
+		return fmt.Errorf("schema name is required")
+	}
+	if schema.Version < 1 {
+		return fmt.Errorf("schema %s has invalid version %d", schema.Name, schema.Version)
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.schemas[SchemaVersion{Name: schema.Name, Version: schema.Version}] = schema
+	s.latest[schema.Name] = max(s.latest[schema.Name], schema.Version)
+	return nil
+}
+
+// RegisterMigration adds the migration converting documents of version from of a schema to version from+1
+func (s *SchemaRegistry) RegisterMigration(name string, from int, migration Migration) error {
+	if name == "" {
+		return fmt.Errorf("schema name is required")
+	}
+	if migration == nil {
+		return fmt.Errorf("migration of schema %s@%d is nil", name, from)
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.migrations[SchemaVersion{Name: name, Version: from}] = migration
+	return nil
+}
+
+// Latest returns the highest registered version of a schema
+func (s *SchemaRegistry) Latest(name string) (int, bool) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	version, ok := s.latest[name]
+	return version, ok
+}
+
+// Validate checks a document against a registered schema version
+func (s *SchemaRegistry) Validate(version SchemaVersion, data json.RawMessage) error {
+	s.mu.RLock()
+	schema, ok := s.schemas[version]
+	s.mu.RUnlock()
+	if !ok {
+		return fmt.Errorf("schema %s is not registered", version)
+	}
+	if schema.Validate == nil {
+		return nil
+	}
+	if err := schema.Validate(data); err != nil {
+		return fmt.Errorf("output does not match schema %s: %w", version, err)
+	}
+	return nil
+}
+
+// Upgrade validates a document against the version it follows and converts it to the target version of the
+// same schema through the registered migrations, validating the result
+func (s *SchemaRegistry) Upgrade(from SchemaVersion, to int, data json.RawMessage) (json.RawMessage, error) {
+	if from.Version > to {
+		return nil, fmt.Errorf("cannot convert schema %s down to version %d", from, to)
+	}
+	if err := s.Validate(from, data); err != nil {
+		return nil, err
+	}
+
+	for version := from; version.Version < to; version.Version++ {
+		s.mu.RLock()
+		migration, ok := s.migrations[version]
+		s.mu.RUnlock()
+		if !ok {
+			return nil, fmt.Errorf("no migration registered from schema %s", version)
+		}
+
+		var err error
+		if data, err = migration(data); err != nil {
+			return nil, fmt.Errorf("failed to migrate schema %s: %w", version, err)
+		}
+	}
+
+	if from.Version == to {
+		return data, nil
+	}
+	return data, s.Validate(SchemaVersion{Name: from.Name, Version: to}, data)
+}
+
+// SchemaOutput runs a script that declares the schema of its JSON output with SchemaSnippet, validates the output
+// against the declared version and upgrades it to the latest registered version of the named schema before
+// parsing it into the specified type
+func SchemaOutput[T any](ctx context.Context, r *Runner, schemas *SchemaRegistry, name, scriptPath string, args ...string) (*StructuredResult[T], error) {
+	raw, err := StructuredOutput[json.RawMessage](ctx, r.With(withSchemaHint()), scriptPath, args...)
+	return decodeSchemaOutput[T](raw, err, schemas, name)
+}
+
+// SchemaOutputFromString runs a script from a string like SchemaOutput
+func SchemaOutputFromString[T any](ctx context.Context, r *Runner, schemas *SchemaRegistry, name, script string, args ...string) (*StructuredResult[T], error) {
+	raw, err := StructuredOutputFromString[json.RawMessage](ctx, r.With(withSchemaHint()), script, args...)
+	return decodeSchemaOutput[T](raw, err, schemas, name)
+}
+
+// decodeSchemaOutput upgrades the output of a run to the latest version of a schema and parses it
+func decodeSchemaOutput[T any](raw *StructuredResult[json.RawMessage], err error, schemas *SchemaRegistry, name string) (*StructuredResult[T], error) {
+	if raw == nil {
+		return nil, err
+	}
+	result := &StructuredResult[T]{Result: raw.Result}
+	if err != nil {
+		return result, err
+	}
+
+	declared := raw.Schema
+	if declared.Name == "" {
+		return result, &PhaseError{Phase: PhaseOutput, Err: fmt.Errorf("script did not declare the schema of its output")}
+	}
+	if declared.Name != name {
+		return result, &PhaseError{Phase: PhaseOutput, Err: fmt.Errorf("script emitted schema %s, expected %s", declared, name)}
+	}
+	latest, ok := schemas.Latest(name)
+	if !ok {
+		return result, &PhaseError{Phase: PhaseOutput, Err: fmt.Errorf("schema %s is not registered", name)}
+	}
+
+	data, err := schemas.Upgrade(declared, latest, raw.Data)
+	if err != nil {
+		return result, &PhaseError{Phase: PhaseOutput, Err: err}
+	}
+	if err := json.Unmarshal(data, &result.Data); err != nil {
+		return result, &PhaseError{Phase: PhaseOutput, Err: fmt.Errorf("failed to unmarshal script output: %w", err)}
+	}
+	return result, nil
+}
@@ -0,0 +1,194 @@
+package uvgo
+
+import (
+	"cmp"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// semver is a semantic version such as 2.1.0 or 3.0.0-rc.1
+type semver struct {
+	major, minor, patch int
+	pre                 string
+}
+
+// parseSemver parses a semantic version, with an optional "v" prefix and missing minor and patch numbers
+// defaulting to zero
+func parseSemver(s string) (semver, error) {
+	v, _, err := parsePartialSemver(s)
+	return v, err
+}
+
+// parsePartialSemver parses a version that may omit trailing numbers or use "x" or "*" wildcards for them,
+// also returning how many numbers were given
+func parsePartialSemver(s string) (semver, int, error) {
+	s = strings.TrimPrefix(strings.TrimSpace(s), "v")
+	s, _, _ = strings.Cut(s, "+")
+	core, pre, _ := strings.Cut(s, "-")
+
+	parts := strings.Split(core, ".")
+	if len(parts) > 3 || core == "" {
+		return semver{}, 0, fmt.Errorf("invalid version %q", s)
+	}
+	var nums [3]int
+	given := 0
+	for _, part := range parts {
+		if part == "x" || part == "X" || part == "*" {
+			break
+		}
+		n, err := strconv.Atoi(part)
+		if err != nil || n < 0 {
+			return semver{}, 0, fmt.Errorf("invalid version %q", s)
+		}
+		nums[given] = n
+		given++
+	}
+	return semver{major: nums[0], minor: nums[1], patch: nums[2], pre: pre}, given, nil
+}
+
+func (v semver) String() string {
+	s := fmt.Sprintf("%d.%d.%d", v.major, v.minor, v.patch)
+	if v.pre != "" {
+		s += "-" + v.pre
+	}
+	return s
+}
+
+// compare orders versions by precedence, ranking a pre-release below its release
+func (v semver) compare(o semver) int {
+	if c := cmp.Compare(v.major, o.major); c != 0 {
+		return c
+	}
+	if c := cmp.Compare(v.minor, o.minor); c != 0 {
+		return c
+	}
+	if c := cmp.Compare(v.patch, o.patch); c != 0 {
+		return c
+	}
+	switch {
+	case v.pre == o.pre:
+		return 0
+	case v.pre == "":
+		return 1
+	case o.pre == "":
+		return -1
+	}
+
+	a, b := strings.Split(v.pre, "."), strings.Split(o.pre, ".")
+	for i := 0; i < len(a) && i < len(b); i++ {
+		an, aErr := strconv.Atoi(a[i])
+		bn, bErr := strconv.Atoi(b[i])
+		var c int
+		switch {
+		case aErr == nil && bErr == nil:
+			c = cmp.Compare(an, bn)
+		case aErr == nil:
+			c = -1
+		case bErr == nil:
+			c = 1
+		default:
+			c = strings.Compare(a[i], b[i])
+		}
+		if c != 0 {
+			return c
+		}
+	}
+	return cmp.Compare(len(a), len(b))
+}
+
+// versionConstraint is a set of comparisons a version must all satisfy
+type versionConstraint []versionComparison
+
+type versionComparison struct {
+	op      string
+	version semver
+}
+
+// parseVersionConstraint parses a constraint made of comparisons separated by spaces or commas, each an exact
+// version, a partial version such as "2" or "2.1.x", a caret range such as "^2.1", a tilde range such as
+// "~2.1", or a version after one of the operators =, >, >=, < and <=. An empty constraint or "*" matches
+// every release.
+func parseVersionConstraint(s string) (versionConstraint, error) {
+	var c versionConstraint
+	for _, term := range strings.FieldsFunc(s, func(r rune) bool { return r == ' ' || r == ',' }) {
+		if term == "latest" {
+			continue
+		}
+
+		op := ""
+		for _, prefix := range []string{">=", "<=", ">", "<", "=", "^", "~"} {
+			if rest, ok := strings.CutPrefix(term, prefix); ok {
+				op, term = prefix, rest
+				break
+			}
+		}
+		v, given, err := parsePartialSemver(term)
+		if err != nil {
+			return nil, err
+		}
+
+		switch op {
+		case "^":
+			upper := semver{major: v.major + 1}
+			switch {
+			case v.major == 0 && v.minor == 0 && given == 3:
+				upper = semver{patch: v.patch + 1}
+			case v.major == 0 && given >= 2:
+				upper = semver{minor: v.minor + 1}
+			}
+			c = append(c, versionComparison{">=", v}, versionComparison{"<", upper})
+		case "~":
+			upper := semver{major: v.major + 1}
+			if given >= 2 {
+				upper = semver{major: v.major, minor: v.minor + 1}
+			}
+			c = append(c, versionComparison{">=", v}, versionComparison{"<", upper})
+		case "", "=":
+			switch given {
+			case 0:
+				// a bare wildcard matches every release
+			case 1:
+				c = append(c, versionComparison{">=", v}, versionComparison{"<", semver{major: v.major + 1}})
+			case 2:
+				c = append(c, versionComparison{">=", v}, versionComparison{"<", semver{major: v.major, minor: v.minor + 1}})
+			default:
+				c = append(c, versionComparison{"=", v})
+			}
+		default:
+			c = append(c, versionComparison{op, v})
+		}
+	}
+	return c, nil
+}
+
+// matches reports whether a version satisfies every comparison. Pre-releases only match a comparison naming
+// a pre-release of the same version, so ranges never select them by accident.
+func (c versionConstraint) matches(v semver) bool {
+	preAllowed := v.pre == ""
+	for _, comparison := range c {
+		w := comparison.version
+		if w.pre != "" && w.major == v.major && w.minor == v.minor && w.patch == v.patch {
+			preAllowed = true
+		}
+
+		n := v.compare(w)
+		var ok bool
+		switch comparison.op {
+		case "=":
+			ok = n == 0
+		case ">":
+			ok = n > 0
+		case ">=":
+			ok = n >= 0
+		case "<":
+			ok = n < 0
+		case "<=":
+			ok = n <= 0
+		}
+		if !ok {
+			return false
+		}
+	}
+	return preAllowed
+}
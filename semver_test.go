@@ -0,0 +1,67 @@
+package uvgo
+
+import "testing"
+
+func TestParseVersionConstraint(t *testing.T) {
+	tests := []struct {
+		constraint string
+		version    string
+		want       bool
+	}{
+		{"", "1.2.3", true},
+		{"*", "1.2.3", true},
+		{"latest", "0.0.1", true},
+		{"1.2.3", "1.2.3", true},
+		{"=1.2.3", "1.2.4", false},
+		{"v1.2.3", "1.2.3", true},
+		{"2", "2.9.9", true},
+		{"2", "3.0.0", false},
+		{"2.1", "2.1.7", true},
+		{"2.1.x", "2.2.0", false},
+		{"^2.1", "2.9.0", true},
+		{"^2.1", "3.0.0", false},
+		{"^2.1", "2.0.9", false},
+		{"^0.2", "0.2.5", true},
+		{"^0.2", "0.3.0", false},
+		{"^0.0.3", "0.0.3", true},
+		{"^0.0.3", "0.0.4", false},
+		{"~2.1", "2.1.9", true},
+		{"~2.1", "2.2.0", false},
+		{"~2", "2.9.0", true},
+		{">=2.1 <3", "2.5.0", true},
+		{">=2.1,<3", "3.0.0", false},
+		{">2", "2.0.0", false},
+		{"<=2", "2.0.0", true},
+		{"^2", "2.1.0-rc.1", false},
+		{">=2.1.0-rc.1", "2.1.0-rc.2", true},
+		{">=2.1.0-rc.1", "2.2.0-rc.1", false},
+		{"2.1.0-rc.1", "2.1.0-rc.1", true},
+		{">=1.0.0-alpha", "1.0.0-alpha.1", true},
+		{"<1.0.0-beta", "1.0.0-alpha.beta", true},
+		{"<1.0.0-rc.2", "1.0.0-rc.10", false},
+	}
+
+	for _, tt := range tests {
+		c, err := parseVersionConstraint(tt.constraint)
+		if err != nil {
+			t.Errorf("parseVersionConstraint(%q) error: %v", tt.constraint, err)
+			continue
+		}
+		v, err := parseSemver(tt.version)
+		if err != nil {
+			t.Errorf("parseSemver(%q) error: %v", tt.version, err)
+			continue
+		}
+		if got := c.matches(v); got != tt.want {
+			t.Errorf("%q matches %q = %v, want %v", tt.constraint, tt.version, got, tt.want)
+		}
+	}
+}
+
+func TestParseVersionConstraintInvalid(t *testing.T) {
+	for _, constraint := range []string{"abc", "1.2.3.4", ">=1..2", "^"} {
+		if _, err := parseVersionConstraint(constraint); err == nil {
+			t.Errorf("parseVersionConstraint(%q) succeeded, want an error", constraint)
+		}
+	}
+}
@@ -0,0 +1,352 @@
+package uvgo
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"syscall"
+	"time"
+)
+
+// serveLogLimit is the number of trailing bytes of each output stream a Server keeps for diagnostics
+const serveLogLimit = 64 * 1024
+
+// ServeOptions configures a Python HTTP server launched with Serve
+type ServeOptions struct {
+	// Network is "tcp" to listen on an allocated localhost port, or "unix" to listen on a unix socket.
+	// It defaults to "tcp".
+	Network string
+	// HealthPath is polled until the server answers with a status below 500, defaulting to "/"
+	HealthPath string
+	// ReadyTimeout bounds how long to wait for the server to become ready, defaulting to 30 seconds
+	ReadyTimeout time.Duration
+	// StopTimeout bounds how long Close waits for the server to exit after sending it SIGTERM before killing
+	// it, defaulting to 10 seconds
+	StopTimeout time.Duration
+	// Args are passed to the script
+	Args []string
+}
+
+// Server is a Python HTTP server managed by the runner
+type Server struct {
+	// Addr is the host:port or socket path the server listens on
+	Addr string
+	// URL is the base URL to use with Client
+	URL string
+	// Client sends requests to the server
+	Client *http.Client
+
+	network string
+	cancel  context.CancelFunc
+	done    chan struct{}
+	stop    time.Duration
+	mu      sync.Mutex
+	pid     int
+	err     error
+	stdout  outputCapture
+	stderr  outputCapture
+	cleanup func()
+}
+
+// Serve launches a Python HTTP server script, such as a Flask app or a uvicorn server, and waits until it is
+// ready. The script must listen on the address exposed through the UVGO_HOST and UVGO_PORT environment
+// variables (also set as PORT), or UVGO_SOCKET for unix sockets. The server runs until Close is called or ctx
+// is cancelled; the runner timeout does not apply.
+func (r *Runner) Serve(ctx context.Context, scriptPath string, opts ServeOptions) (*Server, error) {
+	if _, err := os.Stat(scriptPath); os.IsNotExist(err) {
+		return nil, fmt.Errorf("script file does not exist: %w", err)
+	}
+	return r.serve(ctx, opts, serveSpec{
+		command: func(*Server) []string { return append([]string{scriptPath}, opts.Args...) },
+	})
+}
+
+// serveSpec describes how to start a particular kind of server
+type serveSpec struct {
+	// with lists extra packages required by the server command
+	with []string
+	// command returns the command run by uv once the server address is known
+	command func(s *Server) []string
+	// stdout, when set, receives every line the server writes to stdout
+	stdout func(line string)
+	// addr, when set, is the address to listen on instead of a newly allocated one
+	addr string
+	// env lists extra environment variables for the server
+	env []string
+	// probe reports whether the server is ready, defaulting to an HTTP request to the health path
+	probe func(ctx context.Context, s *Server) bool
+}
+
+func (r *Runner) serve(ctx context.Context, opts ServeOptions, spec serveSpec) (*Server, error) {
+	if opts.HealthPath == "" {
+		opts.HealthPath = "/"
+	}
+	if opts.ReadyTimeout <= 0 {
+		opts.ReadyTimeout = 30 * time.Second
+	}
+	if opts.StopTimeout <= 0 {
+		opts.StopTimeout = 10 * time.Second
+	}
+
+	if err := r.syncEnv(ctx); err != nil {
+		return nil, &PhaseError{Phase: PhaseResolution, Err: err}
+	}
+
+	x := &execution{}
+	s := &Server{
+		done:    make(chan struct{}),
+		stop:    opts.StopTimeout,
+		stdout:  tailCapture(r.stdoutSink),
+		stderr:  tailCapture(r.stderrSink),
+		cleanup: x.cleanup,
+	}
+
+	switch opts.Network {
+	case "", "tcp":
+		s.network = "tcp"
+		s.Addr = spec.addr
+		if s.Addr == "" {
+			port, err := freePort()
+			if err != nil {
+				return nil, err
+			}
+			s.Addr = net.JoinHostPort("127.0.0.1", strconv.Itoa(port))
+		}
+		host, port, err := net.SplitHostPort(s.Addr)
+		if err != nil {
+			return nil, fmt.Errorf("invalid server address: %w", err)
+		}
+		s.URL = "http://" + s.Addr
+		s.Client = &http.Client{}
+		x.env = append(x.env, "UVGO_HOST="+host, "UVGO_PORT="+port, "PORT="+port)
+	case "unix":
+		s.network = "unix"
+		s.Addr = spec.addr
+		if s.Addr == "" {
+			dir, err := x.tempDir()
+			if err != nil {
+				return nil, err
+			}
+			s.Addr = filepath.Join(dir, "server.sock")
+		} else if err := os.Remove(s.Addr); err != nil && !os.IsNotExist(err) {
+			return nil, fmt.Errorf("failed to remove stale socket: %w", err)
+		}
+		s.URL = "http://unix"
+		s.Client = unixClient(s.Addr)
+		x.env = append(x.env, "UVGO_SOCKET="+s.Addr)
+	default:
+		return nil, fmt.Errorf("unsupported network: %q", opts.Network)
+	}
+	x.env = append(x.env, spec.env...)
+
+	if spec.probe == nil {
+		spec.probe = func(ctx context.Context, s *Server) bool { return s.healthy(ctx, opts.HealthPath) }
+	}
+
+	runCtx, cancel := context.WithCancel(ctx)
+	s.cancel = cancel
+
+	inv := &Invocation{
+		Args:   append(r.runArgs(spec.with...), spec.command(s)...),
+		Env:    r.commandEnv(runCtx, x),
+		Dir:    r.commandDir(x),
+		Stdout: io.Writer(s.stdout),
+		Stderr: s.stderr,
+		Started: func(pid int) {
+			s.mu.Lock()
+			s.pid = pid
+			s.mu.Unlock()
+		},
+	}
+	var lines []*lineWriter
+	for _, fn := range []func(string){spec.stdout, r.lineHandler} {
+		if fn != nil {
+			lw := &lineWriter{fn: fn}
+			lines = append(lines, lw)
+			inv.Stdout = io.MultiWriter(inv.Stdout, lw)
+		}
+	}
+	if r.stderrHandler != nil {
+		lw := &lineWriter{fn: r.stderrHandler}
+		lines = append(lines, lw)
+		inv.Stderr = io.MultiWriter(inv.Stderr, lw)
+	}
+
+	r.logCommand(ctx, "", inv)
+
+	go func() {
+		defer close(s.done)
+		_, s.err = r.executor.Execute(runCtx, inv)
+		// a last line without a trailing newline would otherwise never reach the handlers
+		for _, lw := range lines {
+			lw.flush()
+		}
+	}()
+
+	if err := s.waitReady(ctx, opts.ReadyTimeout, spec.probe); err != nil {
+		s.Close()
+		return nil, err
+	}
+	return s, nil
+}
+
+// waitReady polls probe until it succeeds, the server exits or the timeout elapses
+func (s *Server) waitReady(ctx context.Context, timeout time.Duration, probe func(context.Context, *Server) bool) error {
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	ticker := time.NewTicker(100 * time.Millisecond)
+	defer ticker.Stop()
+
+	for {
+		if probe(ctx, s) {
+			return nil
+		}
+
+		select {
+		case <-s.done:
+			return fmt.Errorf("server exited before becoming ready: %w\n%s", s.exitErr(), s.Stderr())
+		case <-ctx.Done():
+			return fmt.Errorf("server not ready after %v: %w\n%s", timeout, ctx.Err(), s.Stderr())
+		case <-ticker.C:
+		}
+	}
+}
+
+// healthy reports whether the server answers a request to path with a status below 500
+func (s *Server) healthy(ctx context.Context, path string) bool {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, s.URL+path, nil)
+	if err != nil {
+		return false
+	}
+	resp, err := s.Client.Do(req)
+	if err != nil {
+		return false
+	}
+	resp.Body.Close()
+	return resp.StatusCode < 500
+}
+
+// Close stops the server and waits for it to exit. The server is sent SIGTERM first, which uv passes on to
+// Python so it can shut down gracefully, and is killed if it has not exited within ServeOptions.StopTimeout.
+func (s *Server) Close() error {
+	if s.terminate() {
+		timer := time.NewTimer(s.stop)
+		select {
+		case <-s.done:
+		case <-timer.C:
+		}
+		timer.Stop()
+	}
+	s.cancel()
+	<-s.done
+	s.cleanup()
+	return nil
+}
+
+// terminate asks the server process to exit, reporting false when it could not be signalled, such as when
+// the executor did not report its process ID or the platform does not support SIGTERM
+func (s *Server) terminate() bool {
+	s.mu.Lock()
+	pid := s.pid
+	s.mu.Unlock()
+	if pid == 0 {
+		return false
+	}
+
+	select {
+	case <-s.done:
+		return false
+	default:
+	}
+	process, err := os.FindProcess(pid)
+	if err != nil {
+		return false
+	}
+	return process.Signal(syscall.SIGTERM) == nil
+}
+
+// Done returns a channel closed once the server process exits
+func (s *Server) Done() <-chan struct{} {
+	return s.done
+}
+
+// Err returns the error the server exited with, or nil while it is running
+func (s *Server) Err() error {
+	select {
+	case <-s.done:
+		return s.exitErr()
+	default:
+		return nil
+	}
+}
+
+func (s *Server) exitErr() error {
+	if s.err == nil {
+		return errors.New("server exited")
+	}
+	return s.err
+}
+
+// Stdout returns the most recent standard output of the server
+func (s *Server) Stdout() string {
+	return s.stdout.String()
+}
+
+// Stderr returns the most recent standard error of the server
+func (s *Server) Stderr() string {
+	return s.stderr.String()
+}
+
+// freePort asks the kernel for an unused localhost TCP port
+func freePort() (int, error) {
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		return 0, fmt.Errorf("failed to allocate port: %w", err)
+	}
+	defer l.Close()
+	return l.Addr().(*net.TCPAddr).Port, nil
+}
+
+func unixClient(path string) *http.Client {
+	return &http.Client{
+		Transport: &http.Transport{
+			DialContext: func(ctx context.Context, _, _ string) (net.Conn, error) {
+				var d net.Dialer
+				return d.DialContext(ctx, "unix", path)
+			},
+		},
+	}
+}
+
+// tailBuffer is a concurrency-safe writer keeping only the last max bytes written to it
+type tailBuffer struct {
+	mu  sync.Mutex
+	max int
+	buf []byte
+}
+
+func (t *tailBuffer) Write(p []byte) (int, error) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	t.buf = append(t.buf, p...)
+	if over := len(t.buf) - t.max; over > 0 {
+		t.buf = append(t.buf[:0], t.buf[over:]...)
+	}
+	return len(p), nil
+}
+
+func (t *tailBuffer) String() string {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return strings.ToValidUTF8(string(t.buf), "")
+}
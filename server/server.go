@@ -0,0 +1,425 @@
+// Package server exposes a uvgo.Runner over HTTP: clients submit script runs, stream their output with
+// server-sent events, fetch results and manage the registered scripts.
+//
+//	POST   /runs              submit a run, returning its ID
+//	GET    /runs/{id}         fetch the status and result of a run
+//	GET    /runs/{id}/events  stream the output of a run as server-sent events
+//	DELETE /runs/{id}         cancel a run
+//	GET    /scripts           list the registered scripts
+//	PUT    /scripts/{name}    register a script, only when the server allows inline scripts
+//	DELETE /scripts/{name}    unregister a script, only when the server allows inline scripts
+package server
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/joeychilson/uvgo"
+)
+
+// RunStatus is the state of a submitted run
+type RunStatus string
+
+const (
+	StatusRunning   RunStatus = "running"
+	StatusSucceeded RunStatus = "succeeded"
+	StatusFailed    RunStatus = "failed"
+	StatusCancelled RunStatus = "cancelled"
+)
+
+// RunRequest is the body of POST /runs
+type RunRequest struct {
+	// Name selects a registered script, optionally pinned to a version constraint such as "etl@^2"
+	Name string `json:"name,omitempty"`
+	// Script is inline script content, only accepted when the server allows inline scripts
+	Script string `json:"script,omitempty"`
+	// Args are passed to an inline script. Registered scripts run with the arguments they were registered
+	// with, so runs naming one are rejected when Args is set.
+	Args []string `json:"args,omitempty"`
+	// Payload is passed to the script in the UVGO_INPUT environment variable
+	Payload json.RawMessage `json:"payload,omitempty"`
+}
+
+// RunInfo is the body returned for a run
+type RunInfo struct {
+	ID       string        `json:"id"`
+	Status   RunStatus     `json:"status"`
+	Created  time.Time     `json:"created"`
+	Finished *time.Time    `json:"finished,omitempty"`
+	Stdout   string        `json:"stdout,omitempty"`
+	Stderr   string        `json:"stderr,omitempty"`
+	ExitCode int           `json:"exit_code"`
+	Duration time.Duration `json:"duration,omitempty"`
+	Error    string        `json:"error,omitempty"`
+}
+
+// ScriptInfo is the representation of a registered script
+type ScriptInfo struct {
+	Name        string   `json:"name"`
+	Version     string   `json:"version,omitempty"`
+	Description string   `json:"description,omitempty"`
+	Source      string   `json:"source"`
+	Args        []string `json:"args,omitempty"`
+}
+
+// Options configures a Server
+type Options struct {
+	// Authenticate, when set, is called for every request and rejects it with 401 Unauthorized when it
+	// returns an error
+	Authenticate func(r *http.Request) error
+	// AllowInline accepts runs of inline script content and lets clients register and unregister scripts.
+	// Only enable it when every client is trusted, since such runs and scripts execute arbitrary code.
+	AllowInline bool
+	// MaxRuns is the number of finished runs kept for retrieval, defaulting to 1000
+	MaxRuns int
+}
+
+// Server is an http.Handler executing scripts with a runner. Output of every run is streamed through an event
+// bus owned by the server, replacing any event bus configured on the runner.
+type Server struct {
+	runner   *uvgo.Runner
+	registry *uvgo.Registry
+	opts     Options
+	mux      *http.ServeMux
+
+	mu       sync.Mutex
+	runs     map[string]*run
+	finished []string
+}
+
+type run struct {
+	mu     sync.Mutex
+	info   RunInfo
+	events []event
+	notify chan struct{}
+	cancel context.CancelFunc
+}
+
+type event struct {
+	name string
+	data string
+}
+
+// New creates a server running scripts from registry with r
+func New(r *uvgo.Runner, registry *uvgo.Registry, opts Options) *Server {
+	if opts.MaxRuns <= 0 {
+		opts.MaxRuns = 1000
+	}
+
+	s := &Server{
+		runner:   r,
+		registry: registry,
+		opts:     opts,
+		mux:      http.NewServeMux(),
+		runs:     make(map[string]*run),
+	}
+	s.mux.HandleFunc("POST /runs", s.submit)
+	s.mux.HandleFunc("GET /runs/{id}", s.getRun)
+	s.mux.HandleFunc("GET /runs/{id}/events", s.streamRun)
+	s.mux.HandleFunc("DELETE /runs/{id}", s.cancelRun)
+	s.mux.HandleFunc("GET /scripts", s.listScripts)
+	s.mux.HandleFunc("PUT /scripts/{name}", s.putScript)
+	s.mux.HandleFunc("DELETE /scripts/{name}", s.deleteScript)
+	return s
+}
+
+// ServeHTTP implements http.Handler
+func (s *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if s.opts.Authenticate != nil {
+		if err := s.opts.Authenticate(r); err != nil {
+			writeError(w, http.StatusUnauthorized, err)
+			return
+		}
+	}
+	s.mux.ServeHTTP(w, r)
+}
+
+func (s *Server) submit(w http.ResponseWriter, r *http.Request) {
+	var req RunRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, http.StatusBadRequest, fmt.Errorf("invalid run request: %w", err))
+		return
+	}
+
+	switch {
+	case req.Name != "":
+		if len(req.Args) > 0 {
+			writeError(w, http.StatusBadRequest, errors.New("args are only accepted for inline scripts"))
+			return
+		}
+		if _, ok := s.registry.Get(req.Name); !ok {
+			writeError(w, http.StatusNotFound, fmt.Errorf("script %s is not registered", req.Name))
+			return
+		}
+	case req.Script != "":
+		if !s.opts.AllowInline {
+			writeError(w, http.StatusForbidden, errors.New("inline scripts are not allowed"))
+			return
+		}
+	default:
+		writeError(w, http.StatusBadRequest, errors.New("run request needs a script name or script"))
+		return
+	}
+
+	// runs outlive the submitting request, so they are only cancelled through DELETE /runs/{id}
+	ctx, cancel := context.WithCancel(context.Background())
+	rn := &run{
+		info:   RunInfo{ID: newRunID(), Status: StatusRunning, Created: time.Now()},
+		notify: make(chan struct{}),
+		cancel: cancel,
+	}
+
+	s.mu.Lock()
+	s.runs[rn.info.ID] = rn
+	s.mu.Unlock()
+
+	go s.execute(ctx, rn, req)
+
+	w.Header().Set("Location", "/runs/"+rn.info.ID)
+	writeJSON(w, http.StatusAccepted, rn.snapshot())
+}
+
+func (s *Server) execute(ctx context.Context, rn *run, req RunRequest) {
+	defer rn.cancel()
+
+	// output is published line by line, so partial lines are held back per stream until completed
+	var (
+		mu      sync.Mutex
+		partial = make(map[string][]byte)
+	)
+	bus := uvgo.NewEventBus()
+	bus.Subscribe(func(e uvgo.Event) {
+		if e.Type != uvgo.EventOutput {
+			return
+		}
+		mu.Lock()
+		defer mu.Unlock()
+
+		buf := append(partial[e.Stream], e.Data...)
+		for {
+			i := bytes.IndexByte(buf, '\n')
+			if i < 0 {
+				break
+			}
+			rn.publish(event{name: e.Stream, data: string(buf[:i])})
+			buf = buf[i+1:]
+		}
+		partial[e.Stream] = buf
+	})
+	runner := s.runner.With(uvgo.WithEventBus(bus))
+
+	var (
+		result *uvgo.Result
+		err    error
+	)
+	if req.Name != "" {
+		result, err = s.registry.Run(ctx, runner, req.Name, req.Payload)
+	} else {
+		if len(req.Payload) > 0 {
+			runner = runner.With(uvgo.WithInput(req.Payload))
+		}
+		result, err = runner.RunFromString(ctx, req.Script, req.Args...)
+	}
+
+	for _, stream := range []string{"stdout", "stderr"} {
+		if len(partial[stream]) > 0 {
+			rn.publish(event{name: stream, data: string(partial[stream])})
+		}
+	}
+
+	rn.mu.Lock()
+	finished := time.Now()
+	rn.info.Finished = &finished
+	switch {
+	case err != nil && ctx.Err() != nil:
+		rn.info.Status = StatusCancelled
+	case err != nil:
+		rn.info.Status = StatusFailed
+	default:
+		rn.info.Status = StatusSucceeded
+	}
+	if err != nil {
+		rn.info.Error = err.Error()
+	}
+	if result != nil {
+		rn.info.Stdout = result.Stdout
+		rn.info.Stderr = result.Stderr
+		rn.info.ExitCode = result.ExitCode
+		rn.info.Duration = result.WallTime
+	}
+	status, _ := json.Marshal(rn.info)
+	rn.mu.Unlock()
+
+	rn.publish(event{name: "finished", data: string(status)})
+	s.retire(rn.info.ID)
+}
+
+// retire records a finished run, forgetting the oldest finished runs beyond MaxRuns
+func (s *Server) retire(id string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.finished = append(s.finished, id)
+	for len(s.finished) > s.opts.MaxRuns {
+		delete(s.runs, s.finished[0])
+		s.finished = s.finished[1:]
+	}
+}
+
+func (s *Server) lookup(w http.ResponseWriter, r *http.Request) *run {
+	s.mu.Lock()
+	rn, ok := s.runs[r.PathValue("id")]
+	s.mu.Unlock()
+
+	if !ok {
+		writeError(w, http.StatusNotFound, fmt.Errorf("run %s not found", r.PathValue("id")))
+		return nil
+	}
+	return rn
+}
+
+func (s *Server) getRun(w http.ResponseWriter, r *http.Request) {
+	if rn := s.lookup(w, r); rn != nil {
+		writeJSON(w, http.StatusOK, rn.snapshot())
+	}
+}
+
+func (s *Server) cancelRun(w http.ResponseWriter, r *http.Request) {
+	if rn := s.lookup(w, r); rn != nil {
+		rn.cancel()
+		writeJSON(w, http.StatusAccepted, rn.snapshot())
+	}
+}
+
+// streamRun replays the output of a run so far and then follows it until the run finishes. Every output line
+// is sent as a "stdout" or "stderr" event, and a final "finished" event carries the RunInfo of the run.
+func (s *Server) streamRun(w http.ResponseWriter, r *http.Request) {
+	rn := s.lookup(w, r)
+	if rn == nil {
+		return
+	}
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		writeError(w, http.StatusInternalServerError, errors.New("streaming is not supported"))
+		return
+	}
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.WriteHeader(http.StatusOK)
+
+	sent := 0
+	for {
+		rn.mu.Lock()
+		pending := rn.events[sent:]
+		notify := rn.notify
+		rn.mu.Unlock()
+
+		for _, e := range pending {
+			writeEvent(w, e)
+			sent++
+			if e.name == "finished" {
+				flusher.Flush()
+				return
+			}
+		}
+		flusher.Flush()
+
+		select {
+		case <-notify:
+		case <-r.Context().Done():
+			return
+		}
+	}
+}
+
+func writeEvent(w http.ResponseWriter, e event) {
+	fmt.Fprintf(w, "event: %s\ndata: %s\n\n", e.name, strings.TrimSuffix(e.data, "\r"))
+}
+
+func (s *Server) listScripts(w http.ResponseWriter, r *http.Request) {
+	scripts := s.registry.List()
+	infos := make([]ScriptInfo, len(scripts))
+	for i, script := range scripts {
+		infos[i] = ScriptInfo{Name: script.Name, Version: script.Version, Description: script.Description, Source: script.Source, Args: script.Args}
+	}
+	writeJSON(w, http.StatusOK, infos)
+}
+
+func (s *Server) putScript(w http.ResponseWriter, r *http.Request) {
+	if !s.opts.AllowInline {
+		writeError(w, http.StatusForbidden, errors.New("registering scripts is not allowed"))
+		return
+	}
+
+	var info ScriptInfo
+	if err := json.NewDecoder(r.Body).Decode(&info); err != nil {
+		writeError(w, http.StatusBadRequest, fmt.Errorf("invalid script: %w", err))
+		return
+	}
+	info.Name = r.PathValue("name")
+
+	err := s.registry.Register(uvgo.Script{
+		Name:        info.Name,
+		Version:     info.Version,
+		Description: info.Description,
+		Source:      info.Source,
+		Args:        info.Args,
+	})
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, info)
+}
+
+func (s *Server) deleteScript(w http.ResponseWriter, r *http.Request) {
+	if !s.opts.AllowInline {
+		writeError(w, http.StatusForbidden, errors.New("unregistering scripts is not allowed"))
+		return
+	}
+
+	s.registry.Unregister(r.PathValue("name"))
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// publish appends an event and wakes up every stream following the run
+func (rn *run) publish(e event) {
+	rn.mu.Lock()
+	defer rn.mu.Unlock()
+
+	rn.events = append(rn.events, e)
+	close(rn.notify)
+	rn.notify = make(chan struct{})
+}
+
+func (rn *run) snapshot() RunInfo {
+	rn.mu.Lock()
+	defer rn.mu.Unlock()
+	return rn.info
+}
+
+func newRunID() string {
+	b := make([]byte, 16)
+	rand.Read(b)
+	return hex.EncodeToString(b)
+}
+
+func writeJSON(w http.ResponseWriter, status int, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(v)
+}
+
+func writeError(w http.ResponseWriter, status int, err error) {
+	writeJSON(w, status, map[string]string{"error": err.Error()})
+}
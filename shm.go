@@ -0,0 +1,165 @@
+package uvgo
+
+import (
+	"fmt"
+	"maps"
+	"os"
+	"path/filepath"
+)
+
+// MappedFile is a file a script produced through shared memory, mapped into the Go process so its contents
+// are not copied. It stays valid after the run's files are removed, until Close is called.
+type MappedFile struct {
+	Name string
+	data []byte
+}
+
+// Bytes returns the contents of the file. The slice is read-only and must not be used after Close.
+func (m *MappedFile) Bytes() []byte {
+	return m.data
+}
+
+// Close unmaps the file
+func (m *MappedFile) Close() error {
+	data := m.data
+	m.data = nil
+	return unmapFile(data)
+}
+
+// SharedOutput returns the shared memory output with the given name
+func (r *Result) SharedOutput(name string) (*MappedFile, bool) {
+	for _, m := range r.SharedOutputs {
+		if m.Name == name {
+			return m, true
+		}
+	}
+	return nil, false
+}
+
+// WithSharedMemory exchanges large payloads with scripts through memory-mapped files instead of pipes. Each
+// input is written to a file the script maps with the bundled uvgo_shm module, and the outputs the script
+// creates with it are mapped into Result.SharedOutputs, which the caller must Close. Files live in /dev/shm
+// when available, so they never touch disk, and the script must run on the local host.
+//
+//	import uvgo_shm
+//	data = uvgo_shm.input("matrix")                             # read-only memoryview
+//	arr = uvgo_shm.input_array("matrix", "float64", (1000, 1000)) # numpy.memmap
+//	out = uvgo_shm.output_array("result", "float64", (1000,))     # writable numpy.memmap
+//	uvgo_shm.write_output("summary", b"...")
+func WithSharedMemory(inputs map[string][]byte) Option {
+	return func(r *Runner) {
+		merged := maps.Clone(r.sharedInputs)
+		if merged == nil {
+			merged = make(map[string][]byte, len(inputs))
+		}
+		maps.Copy(merged, inputs)
+		r.sharedInputs = merged
+	}
+}
+
+const shmModule = `import mmap
+import os
+
+_dir = os.environ["UVGO_SHM_DIR"]
+
+
+def _path(kind, name):
+    if os.sep in name or name in ("", ".", ".."):
+        raise ValueError("invalid shared memory name: %r" % name)
+    return os.path.join(_dir, kind, name)
+
+
+def input(name):
+    """Map a shared input read-only and return a memoryview of it."""
+    with open(_path("in", name), "rb") as f:
+        if os.fstat(f.fileno()).st_size == 0:
+            return memoryview(b"")
+        return memoryview(mmap.mmap(f.fileno(), 0, access=mmap.ACCESS_READ))
+
+
+def input_array(name, dtype, shape=None):
+    """Map a shared input read-only as a numpy array."""
+    import numpy
+    return numpy.memmap(_path("in", name), dtype=dtype, mode="r", shape=shape)
+
+
+def output(name, size):
+    """Create a shared output of size bytes and return a writable memoryview of it."""
+    with open(_path("out", name), "w+b") as f:
+        f.truncate(size)
+        if size == 0:
+            return memoryview(bytearray())
+        return memoryview(mmap.mmap(f.fileno(), size, access=mmap.ACCESS_WRITE))
+
+
+def output_array(name, dtype, shape):
+    """Create a shared output holding a writable numpy array."""
+    import numpy
+    return numpy.memmap(_path("out", name), dtype=dtype, mode="w+", shape=shape)
+
+
+def write_output(name, data):
+    """Write a bytes-like object to a shared output."""
+    with open(_path("out", name), "wb") as f:
+        f.write(data)
+`
+
+const shmSetup = `
+sys.path.append(os.environ["UVGO_SHM_LIB"])
+`
+
+// withSharedMemory writes the shared inputs, exposes the uvgo_shm module and maps the outputs once the script
+// finishes
+func (x *execution) withSharedMemory(inputs map[string][]byte) error {
+	dir, err := os.MkdirTemp(shmRoot(), "uvgo-shm-*")
+	if err != nil {
+		return fmt.Errorf("failed to create shared memory directory: %w", err)
+	}
+	x.release = append(x.release, func() { os.RemoveAll(dir) })
+
+	for _, sub := range []string{"in", "out", "lib"} {
+		if err := os.Mkdir(filepath.Join(dir, sub), 0o755); err != nil {
+			return fmt.Errorf("failed to create shared memory directory: %w", err)
+		}
+	}
+	for name, data := range inputs {
+		if filepath.Base(name) != name || name == "." || name == ".." {
+			return fmt.Errorf("invalid shared memory name: %q", name)
+		}
+		if err := os.WriteFile(filepath.Join(dir, "in", name), data, 0o600); err != nil {
+			return fmt.Errorf("failed to write shared input %s: %w", name, err)
+		}
+	}
+	if err := os.WriteFile(filepath.Join(dir, "lib", "uvgo_shm.py"), []byte(shmModule), 0o644); err != nil {
+		return fmt.Errorf("failed to write uvgo_shm.py: %w", err)
+	}
+
+	x.env = append(x.env, "UVGO_SHM_DIR="+dir, "UVGO_SHM_LIB="+filepath.Join(dir, "lib"))
+	x.setup = append(x.setup, shmSetup)
+	x.collect = append(x.collect, func(result *Result) error {
+		entries, err := os.ReadDir(filepath.Join(dir, "out"))
+		if err != nil {
+			return fmt.Errorf("failed to list shared outputs: %w", err)
+		}
+		for _, entry := range entries {
+			if !entry.Type().IsRegular() {
+				continue
+			}
+			data, err := mapFile(filepath.Join(dir, "out", entry.Name()))
+			if err != nil {
+				return fmt.Errorf("failed to map shared output %s: %w", entry.Name(), err)
+			}
+			result.SharedOutputs = append(result.SharedOutputs, &MappedFile{Name: entry.Name(), data: data})
+		}
+		return nil
+	})
+	return nil
+}
+
+// shmRoot returns the directory shared memory files are created in
+func shmRoot() string {
+	if info, err := os.Stat("/dev/shm"); err == nil && info.IsDir() {
+		return "/dev/shm"
+	}
+	return os.TempDir()
+}
@@ -0,0 +1,14 @@
+//go:build !unix
+
+package uvgo
+
+import "os"
+
+// mapFile reads a file into memory on platforms without mmap support
+func mapFile(path string) ([]byte, error) {
+	return os.ReadFile(path)
+}
+
+func unmapFile(data []byte) error {
+	return nil
+}
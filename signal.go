@@ -0,0 +1,90 @@
+package uvgo
+
+import (
+	"os"
+	"os/signal"
+	"sync"
+	"syscall"
+)
+
+// WithSignalForwarding forwards signals received by the Go process to running scripts instead of letting them
+// terminate it, so Ctrl-C in a command embedding uvgo interrupts the script cleanly and its finally blocks
+// run. mapping translates each received signal into the one sent to the script, defaulting to forwarding
+// SIGINT and SIGTERM unchanged. uv passes forwarded signals on to Python.
+func WithSignalForwarding(mapping map[os.Signal]os.Signal) Option {
+	return func(r *Runner) {
+		if len(mapping) == 0 {
+			mapping = map[os.Signal]os.Signal{os.Interrupt: os.Interrupt, syscall.SIGTERM: syscall.SIGTERM}
+		}
+		r.signals = mapping
+	}
+}
+
+// signalForwarder relays signals received by the Go process to a single running process
+type signalForwarder struct {
+	mapping map[os.Signal]os.Signal
+	ch      chan os.Signal
+	done    chan struct{}
+
+	mu      sync.Mutex
+	process *os.Process
+	pending []os.Signal
+}
+
+// forwardSignals starts relaying the signals in mapping until stop is called
+func forwardSignals(mapping map[os.Signal]os.Signal) *signalForwarder {
+	f := &signalForwarder{mapping: mapping, ch: make(chan os.Signal, 4), done: make(chan struct{})}
+	for sig := range mapping {
+		signal.Notify(f.ch, sig)
+	}
+
+	go func() {
+		for {
+			select {
+			case sig := <-f.ch:
+				f.forward(f.mapping[sig])
+			case <-f.done:
+				return
+			}
+		}
+	}()
+	return f
+}
+
+// forward sends sig to the process, holding it back until the process has started
+func (f *signalForwarder) forward(sig os.Signal) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if f.process == nil {
+		f.pending = append(f.pending, sig)
+		return
+	}
+	f.process.Signal(sig)
+}
+
+// started records the process signals are relayed to, delivering the signals received before it started
+func (f *signalForwarder) started(pid int) {
+	if pid <= 0 {
+		return
+	}
+	process, err := os.FindProcess(pid)
+	if err != nil {
+		return
+	}
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	f.process = process
+	for _, sig := range f.pending {
+		process.Signal(sig)
+	}
+	f.pending = nil
+}
+
+// stop restores the default handling of the forwarded signals
+func (f *signalForwarder) stop() {
+	signal.Stop(f.ch)
+	close(f.done)
+}
@@ -0,0 +1,64 @@
+package uvgo
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+)
+
+// Package is a distribution installed in the environment a script ran in
+type Package struct {
+	Name    string `json:"name"`
+	Version string `json:"version"`
+}
+
+// String returns the package as a pinned requirement, such as "requests==2.32.3"
+func (p Package) String() string {
+	return p.Name + "==" + p.Version
+}
+
+// WithEnvSnapshot records the packages installed in the environment every script ran in, sorted by name,
+// in Result.Packages so runs can be reproduced and debugged afterwards
+func WithEnvSnapshot() Option {
+	return func(r *Runner) { r.envSnapshot = true }
+}
+
+const envSnapshotTeardown = `
+import importlib.metadata as _uvgo_metadata
+import json as _uvgo_json
+_uvgo_packages = {}
+for _uvgo_dist in _uvgo_metadata.distributions():
+    _uvgo_name = _uvgo_dist.metadata["Name"]
+    if _uvgo_name:
+        _uvgo_packages.setdefault(_uvgo_name, _uvgo_dist.version)
+with open(os.environ["UVGO_ENV_SNAPSHOT"], "w") as _uvgo_f:
+    _uvgo_json.dump([
+        {"name": name, "version": version}
+        for name, version in sorted(_uvgo_packages.items(), key=lambda p: p[0].lower())
+    ], _uvgo_f)
+`
+
+// withEnvSnapshot lists the installed distributions once the script finishes and attaches them to the result
+func (x *execution) withEnvSnapshot() error {
+	snapshotPath, err := x.tempPath("packages.json")
+	if err != nil {
+		return err
+	}
+
+	x.env = append(x.env, "UVGO_ENV_SNAPSHOT="+snapshotPath)
+	x.teardown = append(x.teardown, envSnapshotTeardown)
+	x.collect = append(x.collect, func(result *Result) error {
+		data, err := os.ReadFile(snapshotPath)
+		if os.IsNotExist(err) {
+			return nil
+		}
+		if err != nil {
+			return fmt.Errorf("failed to read environment snapshot: %w", err)
+		}
+		if err := json.Unmarshal(data, &result.Packages); err != nil {
+			return fmt.Errorf("failed to parse environment snapshot: %w", err)
+		}
+		return nil
+	})
+	return nil
+}
@@ -0,0 +1,229 @@
+// Package snippets provides ready-made Python scripts for the most common embedded-script tasks, such as
+// summarizing a CSV file, plotting series to a PNG image or fetching a URL. Each constructor takes typed
+// parameters and returns a Snippet whose Run decodes the script's JSON output into a typed result.
+package snippets
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"path/filepath"
+	"time"
+
+	"github.com/joeychilson/uvgo"
+)
+
+// Snippet is a parameterized script producing output of type T
+type Snippet[T any] struct {
+	// Source is the Python script, which reads its parameters as JSON from the UVGO_INPUT environment variable
+	Source string
+	// Dependencies are the packages the script needs
+	Dependencies []string
+	// Input holds the parameters passed to the script
+	Input any
+}
+
+// Run runs the snippet with r, in place of the runner's dependencies installing the snippet's own, and parses
+// its output
+func (s Snippet[T]) Run(ctx context.Context, r *uvgo.Runner) (*uvgo.StructuredResult[T], error) {
+	input, err := json.Marshal(s.Input)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal snippet input: %w", err)
+	}
+	return uvgo.StructuredOutputFromString[T](ctx, r.With(uvgo.WithDependencies(s.Dependencies...), uvgo.WithInput(input)), s.Source)
+}
+
+// ColumnSummary describes one column of a CSV file
+type ColumnSummary struct {
+	Name string `json:"name"`
+	// Type is "number" when every present value is numeric, otherwise "string"
+	Type    string `json:"type"`
+	Count   int    `json:"count"`
+	Missing int    `json:"missing"`
+	Unique  int    `json:"unique"`
+	// Min, Max and Mean are set for numeric columns with at least one value
+	Min  *float64 `json:"min,omitempty"`
+	Max  *float64 `json:"max,omitempty"`
+	Mean *float64 `json:"mean,omitempty"`
+	// Top lists up to five of the most frequent values of string columns
+	Top []string `json:"top,omitempty"`
+}
+
+// CSVSummary describes the rows and columns of a CSV file
+type CSVSummary struct {
+	Rows    int             `json:"rows"`
+	Columns []ColumnSummary `json:"columns"`
+}
+
+// SummarizeCSV returns a snippet reading a CSV file with a header row and summarizing each column, without
+// any dependencies
+func SummarizeCSV(path string) Snippet[CSVSummary] {
+	if abs, err := filepath.Abs(path); err == nil {
+		path = abs
+	}
+	return Snippet[CSVSummary]{Source: summarizeCSVSource, Input: map[string]string{"path": path}}
+}
+
+const summarizeCSVSource = `import collections
+import csv
+import json
+import os
+
+params = json.loads(os.environ["UVGO_INPUT"])
+with open(params["path"], newline="") as f:
+    reader = csv.reader(f)
+    header = next(reader, [])
+    values = [[] for _ in header]
+    rows = 0
+    for row in reader:
+        rows += 1
+        for i in range(len(header)):
+            values[i].append(row[i].strip() if i < len(row) else "")
+
+
+def number(value):
+    try:
+        return float(value)
+    except ValueError:
+        return None
+
+
+columns = []
+for name, column in zip(header, values):
+    present = [v for v in column if v != ""]
+    numbers = [number(v) for v in present]
+    summary = {"name": name, "count": len(present), "missing": len(column) - len(present), "unique": len(set(present))}
+    if present and all(n is not None for n in numbers):
+        summary.update(type="number", min=min(numbers), max=max(numbers), mean=sum(numbers) / len(numbers))
+    else:
+        summary.update(type="string", top=[v for v, _ in collections.Counter(present).most_common(5)])
+    columns.append(summary)
+
+print(json.dumps({"rows": rows, "columns": columns}))
+`
+
+// Series is a named line of points in a plot
+type Series struct {
+	Name string    `json:"name"`
+	X    []float64 `json:"x"`
+	Y    []float64 `json:"y"`
+}
+
+// PlotOptions configures a line plot
+type PlotOptions struct {
+	Title  string `json:"title,omitempty"`
+	XLabel string `json:"xlabel,omitempty"`
+	YLabel string `json:"ylabel,omitempty"`
+	// Width and Height are the size of the image in inches, defaulting to 8 by 5
+	Width  float64 `json:"width,omitempty"`
+	Height float64 `json:"height,omitempty"`
+	// DPI is the resolution of the image, defaulting to 100
+	DPI int `json:"dpi,omitempty"`
+}
+
+// Plot is a rendered image
+type Plot struct {
+	PNG []byte `json:"png"`
+}
+
+// PlotSeries returns a snippet drawing series as lines with matplotlib and rendering them to a PNG image
+func PlotSeries(opts PlotOptions, series ...Series) Snippet[Plot] {
+	return Snippet[Plot]{
+		Source:       plotSeriesSource,
+		Dependencies: []string{"matplotlib"},
+		Input:        map[string]any{"options": opts, "series": series},
+	}
+}
+
+const plotSeriesSource = `import base64
+import io
+import json
+import os
+
+import matplotlib
+
+matplotlib.use("Agg")
+import matplotlib.pyplot as plt
+
+params = json.loads(os.environ["UVGO_INPUT"])
+options = params["options"]
+fig, ax = plt.subplots(figsize=(options.get("width") or 8, options.get("height") or 5))
+for series in params["series"] or []:
+    ax.plot(series["x"] or [], series["y"] or [], label=series["name"] or None)
+if options.get("title"):
+    ax.set_title(options["title"])
+if options.get("xlabel"):
+    ax.set_xlabel(options["xlabel"])
+if options.get("ylabel"):
+    ax.set_ylabel(options["ylabel"])
+if any(series["name"] for series in params["series"] or []):
+    ax.legend()
+
+buf = io.BytesIO()
+fig.savefig(buf, format="png", dpi=options.get("dpi") or 100, bbox_inches="tight")
+print(json.dumps({"png": base64.b64encode(buf.getvalue()).decode()}))
+`
+
+// FetchRequest describes an HTTP request
+type FetchRequest struct {
+	URL string `json:"url"`
+	// Method defaults to GET
+	Method  string            `json:"method,omitempty"`
+	Headers map[string]string `json:"headers,omitempty"`
+	Body    string            `json:"body,omitempty"`
+	// Timeout bounds the request, defaulting to 30 seconds
+	Timeout time.Duration `json:"-"`
+}
+
+// FetchResponse is the response to an HTTP request
+type FetchResponse struct {
+	Status  int               `json:"status"`
+	Headers map[string]string `json:"headers"`
+	Body    string            `json:"body"`
+	// JSON holds the body when the response declares a JSON content type and the body parses
+	JSON json.RawMessage `json:"json,omitempty"`
+}
+
+// HTTPFetch returns a snippet sending an HTTP request with the Python standard library and returning the
+// response, including error statuses, as a structured result
+func HTTPFetch(req FetchRequest) Snippet[FetchResponse] {
+	timeout := req.Timeout
+	if timeout <= 0 {
+		timeout = 30 * time.Second
+	}
+	return Snippet[FetchResponse]{
+		Source: httpFetchSource,
+		Input:  map[string]any{"request": req, "timeout": timeout.Seconds()},
+	}
+}
+
+const httpFetchSource = `import json
+import os
+import urllib.error
+import urllib.request
+
+params = json.loads(os.environ["UVGO_INPUT"])
+request = params["request"]
+body = request.get("body")
+req = urllib.request.Request(
+    request["url"],
+    data=body.encode() if body else None,
+    headers=request.get("headers") or {},
+    method=request.get("method") or "GET",
+)
+try:
+    resp = urllib.request.urlopen(req, timeout=params["timeout"])
+except urllib.error.HTTPError as err:
+    resp = err
+
+with resp:
+    text = resp.read().decode(resp.headers.get_content_charset() or "utf-8", errors="replace")
+    response = {"status": resp.status, "headers": dict(resp.headers.items()), "body": text}
+    if resp.headers.get_content_type().endswith("json"):
+        try:
+            response["json"] = json.loads(text)
+        except ValueError:
+            pass
+
+print(json.dumps(response))
+`
@@ -0,0 +1,214 @@
+package uvgo
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"context"
+	"errors"
+	"fmt"
+	"os/exec"
+	"path"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// SSHExecutor runs uv on a remote machine, such as a GPU host, through the ssh command. The script and the run's
+// temporary directory are pushed to a staging directory before every run and the temporary directory is
+// copied back afterwards, so artifacts and instrumentation work as they do locally. Authentication relies on
+// keys or an agent, since ssh runs in batch mode. Features relying on a local connection to the script, such
+// as WithBridge, are not available, and a configured working directory must exist on the remote host.
+type SSHExecutor struct {
+	// Host is the ssh destination, such as "user@gpu-host" or a host alias from the ssh configuration
+	Host string
+	// Port overrides the ssh port when set
+	Port int
+	// IdentityFile is the private key used to authenticate, defaulting to the ssh configuration
+	IdentityFile string
+	// Options are extra ssh options in "Key=Value" form, such as "StrictHostKeyChecking=accept-new"
+	Options []string
+	// UVPath is the uv executable on the remote host, defaulting to "uv"
+	UVPath string
+	// StagingDir is the remote directory runs are staged under, defaulting to "/tmp"
+	StagingDir string
+	// SSHPath is the local ssh executable, defaulting to "ssh" looked up in PATH
+	SSHPath string
+}
+
+// Execute implements Executor
+func (e SSHExecutor) Execute(ctx context.Context, inv *Invocation) (*ExitStatus, error) {
+	stagingDir := e.StagingDir
+	if stagingDir == "" {
+		stagingDir = "/tmp"
+	}
+	stage := path.Join(stagingDir, "uvgo-"+newID())
+
+	if err := e.push(ctx, stage, inv); err != nil {
+		return nil, err
+	}
+	defer e.cleanup(stage)
+
+	local := stagedPaths(inv, path.Join(stage, "run"), path.Join(stage, "script", filepath.Base(inv.Script)))
+
+	uvPath := e.UVPath
+	if uvPath == "" {
+		uvPath = "uv"
+	}
+	var command strings.Builder
+	if inv.Dir != "" {
+		fmt.Fprintf(&command, "cd %s && ", shellQuote(local(inv.Dir)))
+	}
+	fmt.Fprintf(&command, "echo $$ > %s && exec env", shellQuote(path.Join(stage, "pid")))
+	for _, kv := range inv.Env {
+		command.WriteString(" " + shellQuote(local(kv)))
+	}
+	command.WriteString(" " + shellQuote(uvPath))
+	for _, arg := range inv.Args {
+		command.WriteString(" " + shellQuote(local(arg)))
+	}
+
+	cmd := e.command(ctx, command.String())
+	cmd.Stdin = inv.Stdin
+	cmd.Stdout = inv.Stdout
+	cmd.Stderr = inv.Stderr
+	// killing the local ssh client leaves the remote process running, so cancellation signals it first
+	cmd.Cancel = func() error {
+		killCtx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+		defer cancel()
+		e.command(killCtx, fmt.Sprintf("kill -KILL $(cat %s)", shellQuote(path.Join(stage, "pid")))).Run()
+		return cmd.Process.Kill()
+	}
+	cmd.WaitDelay = 10 * time.Second
+
+	if err := cmd.Start(); err != nil {
+		return nil, err
+	}
+	if inv.Started != nil {
+		inv.Started(cmd.Process.Pid)
+	}
+
+	err := cmd.Wait()
+	status := &ExitStatus{ExitCode: -1}
+	if cmd.ProcessState != nil {
+		status.ExitCode = cmd.ProcessState.ExitCode()
+	}
+
+	if inv.TempDir != "" {
+		if pullErr := e.pull(stage, inv.TempDir); pullErr != nil && err == nil {
+			return status, pullErr
+		}
+	}
+
+	var exitError *exec.ExitError
+	if errors.As(err, &exitError) {
+		if ctx.Err() != nil {
+			status.ExitCode = -1
+		}
+		return status, &ExitError{Code: status.ExitCode, Err: err}
+	}
+	return status, err
+}
+
+// push copies the script and the temporary directory of an invocation to the staging directory
+func (e SSHExecutor) push(ctx context.Context, stage string, inv *Invocation) error {
+	var buf bytes.Buffer
+	gz := gzip.NewWriter(&buf)
+	tw := tar.NewWriter(gz)
+
+	if err := tw.WriteHeader(&tar.Header{Typeflag: tar.TypeDir, Name: "run/", Mode: 0o755}); err != nil {
+		return err
+	}
+	if inv.TempDir != "" {
+		if err := tarDir(tw, inv.TempDir, "run"); err != nil {
+			return fmt.Errorf("failed to archive execution directory: %w", err)
+		}
+	}
+	if inv.Script != "" {
+		if err := tarFile(tw, inv.Script, path.Join("script", filepath.Base(inv.Script))); err != nil {
+			return fmt.Errorf("failed to archive script: %w", err)
+		}
+	}
+	if err := tw.Close(); err != nil {
+		return err
+	}
+	if err := gz.Close(); err != nil {
+		return err
+	}
+
+	var stderr bytes.Buffer
+	cmd := e.command(ctx, fmt.Sprintf("mkdir -p %[1]s && tar -xzf - -C %[1]s", shellQuote(stage)))
+	cmd.Stdin = &buf
+	cmd.Stderr = &stderr
+	if err := cmd.Run(); err != nil {
+		return fmt.Errorf("failed to push script to %s: %w: %s", e.Host, err, strings.TrimSpace(stderr.String()))
+	}
+	return nil
+}
+
+// pull copies the staged temporary directory back into dir
+func (e SSHExecutor) pull(stage, dir string) error {
+	ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
+	defer cancel()
+
+	var stdout, stderr bytes.Buffer
+	cmd := e.command(ctx, fmt.Sprintf("tar -czf - -C %s .", shellQuote(path.Join(stage, "run"))))
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+	if err := cmd.Run(); err != nil {
+		return fmt.Errorf("failed to pull execution directory from %s: %w: %s", e.Host, err, strings.TrimSpace(stderr.String()))
+	}
+
+	gz, err := gzip.NewReader(&stdout)
+	if err != nil {
+		return fmt.Errorf("failed to read execution directory: %w", err)
+	}
+	return untar(gz, dir)
+}
+
+func (e SSHExecutor) cleanup(stage string) {
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+	e.command(ctx, "rm -rf "+shellQuote(stage)).Run()
+}
+
+// command returns an ssh command running a shell command on the host
+func (e SSHExecutor) command(ctx context.Context, command string) *exec.Cmd {
+	sshPath := e.SSHPath
+	if sshPath == "" {
+		sshPath = "ssh"
+	}
+
+	args := []string{"-o", "BatchMode=yes"}
+	if e.Port > 0 {
+		args = append(args, "-p", strconv.Itoa(e.Port))
+	}
+	if e.IdentityFile != "" {
+		args = append(args, "-i", e.IdentityFile)
+	}
+	for _, opt := range e.Options {
+		args = append(args, "-o", opt)
+	}
+	args = append(args, e.Host, command)
+	return exec.CommandContext(ctx, sshPath, args...)
+}
+
+// stagedPaths returns a function rewriting the local script and temporary directory paths referenced by an
+// invocation into their staged counterparts
+func stagedPaths(inv *Invocation, tempDir, script string) func(string) string {
+	return func(s string) string {
+		if inv.Script != "" && s == inv.Script {
+			return script
+		}
+		if inv.TempDir != "" {
+			return strings.ReplaceAll(s, inv.TempDir, tempDir)
+		}
+		return s
+	}
+}
+
+// shellQuote quotes s for a POSIX shell
+func shellQuote(s string) string {
+	return "'" + strings.ReplaceAll(s, "'", `'\''`) + "'"
+}
@@ -0,0 +1,35 @@
+package uvgo
+
+import "testing"
+
+func TestStagedPaths(t *testing.T) {
+	inv := &Invocation{Script: "/home/me/job.py", TempDir: "/tmp/uvgo-123"}
+	staged := stagedPaths(inv, "/var/tmp/uvgo-remote", "/var/tmp/uvgo-remote/script.py")
+
+	tests := []struct {
+		in   string
+		want string
+	}{
+		{"/home/me/job.py", "/var/tmp/uvgo-remote/script.py"},
+		{"/home/me/job.py.bak", "/home/me/job.py.bak"},
+		{"/tmp/uvgo-123/callback.sock", "/var/tmp/uvgo-remote/callback.sock"},
+		{"UVGO_OUTPUT_DIR=/tmp/uvgo-123/out", "UVGO_OUTPUT_DIR=/var/tmp/uvgo-remote/out"},
+		{"--with=requests", "--with=requests"},
+		{"", ""},
+	}
+
+	for _, tt := range tests {
+		if got := staged(tt.in); got != tt.want {
+			t.Errorf("staged(%q) = %q, want %q", tt.in, got, tt.want)
+		}
+	}
+}
+
+func TestStagedPathsEmptyInvocation(t *testing.T) {
+	staged := stagedPaths(&Invocation{}, "/var/tmp/uvgo-remote", "/var/tmp/uvgo-remote/script.py")
+	for _, s := range []string{"", "-", "/tmp/uvgo-123/out"} {
+		if got := staged(s); got != s {
+			t.Errorf("staged(%q) = %q, want it unchanged", s, got)
+		}
+	}
+}
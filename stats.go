@@ -0,0 +1,110 @@
+package uvgo
+
+import (
+	"slices"
+	"sync"
+	"time"
+)
+
+// statsWindow is the number of recent run durations kept for percentiles
+const statsWindow = 1024
+
+// RunStats summarizes the runs of a runner since it was created
+type RunStats struct {
+	Since     time.Time
+	Runs      int64
+	Succeeded int64
+	Failed    int64
+	// SuccessRate is the fraction of runs that succeeded, zero before the first run
+	SuccessRate float64
+	// P50, P95 and P99 are wall time percentiles over the most recent 1024 runs
+	P50 time.Duration
+	P95 time.Duration
+	P99 time.Duration
+	// ColdStarts counts runs for which uv had to install packages first
+	ColdStarts int64
+	// AvgColdStartOverhead is the average time uv spent resolving, preparing and installing packages in a
+	// cold start
+	AvgColdStartOverhead time.Duration
+}
+
+// Stats returns aggregate statistics over the runs of the runner and every runner derived from it with With
+func (r *Runner) Stats() RunStats {
+	return r.stats.snapshot()
+}
+
+// runStats accumulates RunStats, shared by a runner and its derived runners
+type runStats struct {
+	mu           sync.Mutex
+	since        time.Time
+	runs         int64
+	failed       int64
+	coldStarts   int64
+	coldOverhead time.Duration
+	durations    []time.Duration
+	next         int
+}
+
+func newRunStats() *runStats {
+	return &runStats{since: time.Now()}
+}
+
+// record adds a finished run
+func (s *runStats) record(result *Result, err error) {
+	if s == nil {
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.runs++
+	if err != nil {
+		s.failed++
+	}
+	if result == nil {
+		return
+	}
+
+	if len(s.durations) < statsWindow {
+		s.durations = append(s.durations, result.WallTime)
+	} else {
+		s.durations[s.next] = result.WallTime
+		s.next = (s.next + 1) % statsWindow
+	}
+
+	if setup := parseSetup(result.Stderr); setup.installed > 0 {
+		s.coldStarts++
+		s.coldOverhead += setup.overhead
+	}
+}
+
+func (s *runStats) snapshot() RunStats {
+	if s == nil {
+		return RunStats{}
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	stats := RunStats{
+		Since:      s.since,
+		Runs:       s.runs,
+		Succeeded:  s.runs - s.failed,
+		Failed:     s.failed,
+		ColdStarts: s.coldStarts,
+	}
+	if s.runs > 0 {
+		stats.SuccessRate = float64(stats.Succeeded) / float64(s.runs)
+	}
+	if s.coldStarts > 0 {
+		stats.AvgColdStartOverhead = s.coldOverhead / time.Duration(s.coldStarts)
+	}
+
+	sorted := slices.Clone(s.durations)
+	slices.Sort(sorted)
+	stats.P50 = percentile(sorted, 50)
+	stats.P95 = percentile(sorted, 95)
+	stats.P99 = percentile(sorted, 99)
+	return stats
+}
@@ -0,0 +1,54 @@
+package uvgo
+
+import (
+	"bytes"
+	"strings"
+)
+
+// WithUnbuffered makes Python write its output as soon as it is produced instead of buffering stdout in
+// blocks when it is not a terminal. WithLineHandler, WithStderrHandler, WithTranscript and
+// WithProgressHandler imply it.
+func WithUnbuffered() Option {
+	return func(r *Runner) { r.unbuffered = true }
+}
+
+// WithLineHandler calls fn with every line the script writes to stdout as soon as it is written, without the
+// trailing newline. Output is still captured in the Result. Calls for a run are sequential, but runs executing
+// concurrently call fn concurrently.
+func WithLineHandler(fn func(line string)) Option {
+	return func(r *Runner) { r.lineHandler = fn }
+}
+
+// WithStderrHandler calls fn with every line the script writes to stderr as soon as it is written, without the
+// trailing newline, such as to route Python warnings and logs to a Go logger while stdout carries data. Output
+// is still captured in the Result, and calls follow the same rules as WithLineHandler.
+func WithStderrHandler(fn func(line string)) Option {
+	return func(r *Runner) { r.stderrHandler = fn }
+}
+
+// lineWriter calls fn with every complete line written to it
+type lineWriter struct {
+	fn  func(line string)
+	buf []byte
+}
+
+func (l *lineWriter) Write(p []byte) (int, error) {
+	l.buf = append(l.buf, p...)
+	for {
+		i := bytes.IndexByte(l.buf, '\n')
+		if i < 0 {
+			break
+		}
+		l.fn(strings.TrimSuffix(string(l.buf[:i]), "\r"))
+		l.buf = l.buf[i+1:]
+	}
+	return len(p), nil
+}
+
+// flush passes a final line not terminated by a newline to fn
+func (l *lineWriter) flush() {
+	if len(l.buf) > 0 {
+		l.fn(strings.TrimSuffix(string(l.buf), "\r"))
+		l.buf = nil
+	}
+}
@@ -0,0 +1,136 @@
+package uvgo
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+)
+
+// ErrRestartLimit is returned by Supervise when a script crashed more often than its restart budget allows
+var ErrRestartLimit = errors.New("restart limit reached")
+
+// SupervisorState is the state of a supervised script
+type SupervisorState string
+
+const (
+	// StateRunning means the script has been started
+	StateRunning SupervisorState = "running"
+	// StateBackoff means the script crashed and is waiting to be restarted
+	StateBackoff SupervisorState = "backoff"
+	// StateStopped means supervision ended because the script exited cleanly or the context was cancelled
+	StateStopped SupervisorState = "stopped"
+	// StateFailed means supervision ended because the restart budget was exhausted
+	StateFailed SupervisorState = "failed"
+)
+
+// StateChange describes a transition of a supervised script
+type StateChange struct {
+	State SupervisorState
+	// Restarts is the number of restarts so far
+	Restarts int
+	// Result and Err describe the run that ended, when the transition was caused by one
+	Result *Result
+	Err    error
+	// Backoff is the delay before the next restart for StateBackoff
+	Backoff time.Duration
+}
+
+// SuperviseOptions configures how Supervise restarts a script
+type SuperviseOptions struct {
+	// Args are passed to the script
+	Args []string
+	// MinBackoff is the delay before the first restart, doubling after each consecutive crash, defaulting to
+	// 1 second
+	MinBackoff time.Duration
+	// MaxBackoff caps the restart delay, defaulting to 1 minute. A run lasting longer than MaxBackoff resets
+	// the delay to MinBackoff.
+	MaxBackoff time.Duration
+	// MaxRestarts is the restart budget, unlimited when zero
+	MaxRestarts int
+	// RestartWindow limits the restart budget to restarts within the window, counting all restarts when zero
+	RestartWindow time.Duration
+	// RestartOnSuccess restarts the script after it exits cleanly instead of ending supervision
+	RestartOnSuccess bool
+	// OnStateChange, when set, is called on every state transition
+	OnStateChange func(StateChange)
+}
+
+// Supervise runs a long-running script and restarts it with exponential backoff whenever it crashes. It blocks
+// until the script exits cleanly or ctx is cancelled, returning nil, or until the restart budget is exhausted,
+// returning an error wrapping ErrRestartLimit and the last failure. The runner timeout does not apply to
+// supervised runs.
+func (r *Runner) Supervise(ctx context.Context, scriptPath string, opts SuperviseOptions) error {
+	if opts.MinBackoff <= 0 {
+		opts.MinBackoff = time.Second
+	}
+	if opts.MaxBackoff <= 0 {
+		opts.MaxBackoff = time.Minute
+	}
+	if opts.MaxBackoff < opts.MinBackoff {
+		opts.MaxBackoff = opts.MinBackoff
+	}
+
+	notify := func(change StateChange) {
+		if opts.OnStateChange != nil {
+			opts.OnStateChange(change)
+		}
+	}
+
+	runner := r.With(WithTimeout(0))
+	backoff := opts.MinBackoff
+
+	var (
+		total    int
+		restarts []time.Time
+	)
+	for {
+		notify(StateChange{State: StateRunning, Restarts: total})
+
+		start := time.Now()
+		result, err := runner.Run(ctx, scriptPath, opts.Args...)
+
+		if ctx.Err() != nil || (err == nil && !opts.RestartOnSuccess) {
+			notify(StateChange{State: StateStopped, Restarts: total, Result: result, Err: err})
+			return nil
+		}
+
+		if time.Since(start) > opts.MaxBackoff {
+			backoff = opts.MinBackoff
+		}
+
+		now := time.Now()
+		if opts.RestartWindow > 0 {
+			kept := restarts[:0]
+			for _, t := range restarts {
+				if now.Sub(t) < opts.RestartWindow {
+					kept = append(kept, t)
+				}
+			}
+			restarts = kept
+		}
+
+		if opts.MaxRestarts > 0 && len(restarts) >= opts.MaxRestarts {
+			notify(StateChange{State: StateFailed, Restarts: total, Result: result, Err: err})
+			if err == nil {
+				return ErrRestartLimit
+			}
+			return fmt.Errorf("%w: %w", ErrRestartLimit, err)
+		}
+
+		notify(StateChange{State: StateBackoff, Restarts: total, Result: result, Err: err, Backoff: backoff})
+
+		timer := time.NewTimer(backoff)
+		select {
+		case <-timer.C:
+		case <-ctx.Done():
+			timer.Stop()
+			notify(StateChange{State: StateStopped, Restarts: total})
+			return nil
+		}
+
+		total++
+		restarts = append(restarts, time.Now())
+		backoff = min(backoff*2, opts.MaxBackoff)
+	}
+}
@@ -0,0 +1,65 @@
+package uvgo
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+)
+
+// TimeoutPolicy controls how the runner timeout interacts with the caller's context deadline
+type TimeoutPolicy int
+
+const (
+	// TimeoutShortest applies the runner timeout on top of the caller's deadline, so whichever expires
+	// first ends the run. This is the default.
+	TimeoutShortest TimeoutPolicy = iota
+	// TimeoutPreferCaller applies the runner timeout only when the caller's context has no deadline
+	TimeoutPreferCaller
+)
+
+// errRunnerTimeout is the cancellation cause recorded when the runner timeout, not the caller, ended a run
+var errRunnerTimeout = errors.New("runner timeout exceeded")
+
+// WithTimeoutPolicy sets how the runner timeout interacts with the caller's context deadline
+func WithTimeoutPolicy(policy TimeoutPolicy) Option {
+	return func(r *Runner) { r.timeoutPolicy = policy }
+}
+
+// withTimeout derives the context a run executes under according to the runner timeout and policy
+func (r *Runner) withTimeout(ctx context.Context) (context.Context, context.CancelFunc) {
+	if r.timeout <= 0 {
+		return context.WithCancel(ctx)
+	}
+	if _, ok := ctx.Deadline(); ok && r.timeoutPolicy == TimeoutPreferCaller {
+		return context.WithCancel(ctx)
+	}
+	return context.WithTimeoutCause(ctx, r.timeout, errRunnerTimeout)
+}
+
+// runnerTimedOut reports whether a run's context was ended by the runner timeout
+func runnerTimedOut(ctx context.Context) bool {
+	return errors.Is(context.Cause(ctx), errRunnerTimeout)
+}
+
+// TimeoutError reports a run killed because its deadline passed. It matches context.DeadlineExceeded with
+// errors.Is, and the Result returned alongside it, also available as Result, holds the output captured until
+// the process was killed.
+type TimeoutError struct {
+	// Timeout is the runner timeout that expired, or zero when the caller's context deadline ended the run
+	Timeout time.Duration
+	// Result holds the partial output of the run
+	Result *Result
+	Err    error
+}
+
+func (e *TimeoutError) Error() string {
+	if e.Timeout > 0 {
+		return fmt.Sprintf("script execution timed out after %v: %v", e.Timeout, e.Err)
+	}
+	return fmt.Sprintf("script execution exceeded the context deadline: %v", e.Err)
+}
+
+func (e *TimeoutError) Unwrap() []error {
+	return []error{context.DeadlineExceeded, e.Err}
+}
@@ -0,0 +1,105 @@
+package uvgo
+
+import (
+	"fmt"
+	"os"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Timings breaks the wall time of a run down into the environment setup uv performed and the script itself
+type Timings struct {
+	// Resolution is the time uv spent resolving dependencies
+	Resolution time.Duration
+	// Download is the time uv spent downloading and building packages, reported by uv as preparing them
+	Download time.Duration
+	// Install is the time uv spent installing packages into the environment
+	Install time.Duration
+	// Script is the time the script itself ran, measured inside the interpreter
+	Script time.Duration
+	// Total is the wall time of the whole run
+	Total time.Duration
+}
+
+// Setup returns the time spent outside the script, including uv's environment setup and interpreter startup
+func (t *Timings) Setup() time.Duration {
+	return max(t.Total-t.Script, 0)
+}
+
+// String summarizes the timings on one line
+func (t *Timings) String() string {
+	return fmt.Sprintf("resolution %v, download %v, install %v, script %v, total %v",
+		t.Resolution, t.Download, t.Install, t.Script, t.Total)
+}
+
+// WithTimings records how long uv spent resolving, downloading and installing dependencies and how long the
+// script ran in Result.Timings, showing whether a slow run is spent in environment setup or in the script. The
+// uv phases are read from the summary lines uv prints to stderr, so they are zero when uv runs with --quiet or
+// reuses a cached environment.
+func WithTimings() Option {
+	return func(r *Runner) { r.timings = true }
+}
+
+// uvTimingPattern matches the summary lines uv prints after each step, such as "Resolved 12 packages in 85ms"
+var uvTimingPattern = regexp.MustCompile(`^\s*(Resolved|Prepared|Installed) \d+ packages? in (.+)$`)
+
+// parseLine adds the duration of a uv summary line to the matching phase
+func (t *Timings) parseLine(line string) {
+	match := uvTimingPattern.FindStringSubmatch(line)
+	if match == nil {
+		return
+	}
+	d, err := time.ParseDuration(strings.ReplaceAll(strings.TrimSpace(match[2]), " ", ""))
+	if err != nil {
+		return
+	}
+	switch match[1] {
+	case "Resolved":
+		t.Resolution += d
+	case "Prepared":
+		t.Download += d
+	case "Installed":
+		t.Install += d
+	}
+}
+
+const timingSetup = `
+import time as _uvgo_time
+_uvgo_script_start = _uvgo_time.perf_counter()
+`
+
+const timingTeardown = `
+with open(os.environ["UVGO_TIMINGS"], "w") as _uvgo_f:
+    _uvgo_f.write(repr(_uvgo_time.perf_counter() - _uvgo_script_start))
+`
+
+// withTimings measures the script from the end of the setup to the start of the teardown, so it must be applied
+// after every other instrumentation
+func (x *execution) withTimings() error {
+	timingsPath, err := x.tempPath("timings")
+	if err != nil {
+		return err
+	}
+
+	x.env = append(x.env, "UVGO_TIMINGS="+timingsPath)
+	x.setup = append(x.setup, timingSetup)
+	x.teardown = append([]string{timingTeardown}, x.teardown...)
+	x.collect = append(x.collect, func(result *Result) error {
+		data, err := os.ReadFile(timingsPath)
+		if os.IsNotExist(err) || result.Timings == nil {
+			return nil
+		}
+		if err != nil {
+			return fmt.Errorf("failed to read script timing: %w", err)
+		}
+		seconds, err := strconv.ParseFloat(strings.TrimSpace(string(data)), 64)
+		if err != nil {
+			return fmt.Errorf("failed to parse script timing: %w", err)
+		}
+		result.Timings.Script = time.Duration(seconds * float64(time.Second))
+		return nil
+	})
+	return nil
+}
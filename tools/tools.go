@@ -0,0 +1,122 @@
+// Package tools exposes scripts from a uvgo.Registry as tools for LLM tool calling. Tool inputs and outputs
+// are Go types: their JSON Schema is generated for the model, tool call arguments are decoded into the input
+// type and passed to the script as JSON in the UVGO_INPUT environment variable, and the script's JSON output
+// is decoded into the output type before being returned to the model.
+package tools
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"sync"
+
+	"github.com/joeychilson/uvgo"
+)
+
+// Definition describes a tool to a model, matching the shape expected by common tool calling APIs
+type Definition struct {
+	Name        string          `json:"name"`
+	Description string          `json:"description"`
+	InputSchema json.RawMessage `json:"input_schema"`
+}
+
+// Toolset dispatches tool calls to registered scripts
+type Toolset struct {
+	runner   *uvgo.Runner
+	registry *uvgo.Registry
+
+	mu    sync.RWMutex
+	tools map[string]*tool
+}
+
+type tool struct {
+	definition Definition
+	call       func(ctx context.Context, arguments json.RawMessage) (json.RawMessage, error)
+}
+
+// New creates an empty toolset running scripts from registry with r
+func New(r *uvgo.Runner, registry *uvgo.Registry) *Toolset {
+	return &Toolset{runner: r, registry: registry, tools: make(map[string]*tool)}
+}
+
+// Add exposes the registered script ref selects as a tool taking In and returning Out. The ref may pin a
+// version, such as "etl@^2", but the tool is named and called by the script's bare name, since models only
+// see that name. The tool description is the script's description.
+func Add[In, Out any](ts *Toolset, ref string) error {
+	script, ok := ts.registry.Get(ref)
+	if !ok {
+		return fmt.Errorf("script %s is not registered", ref)
+	}
+	name := script.Name
+
+	t := &tool{
+		definition: Definition{
+			Name:        script.Name,
+			Description: script.Description,
+			InputSchema: Schema[In](),
+		},
+		call: func(ctx context.Context, arguments json.RawMessage) (json.RawMessage, error) {
+			var input In
+			if err := decodeStrict(arguments, &input); err != nil {
+				return nil, fmt.Errorf("invalid arguments for tool %s: %w", name, err)
+			}
+			data, err := json.Marshal(input)
+			if err != nil {
+				return nil, fmt.Errorf("failed to marshal tool input: %w", err)
+			}
+
+			result, err := ts.registry.Run(ctx, ts.runner, ref, data)
+			if err != nil {
+				return nil, err
+			}
+
+			var output Out
+			if err := json.Unmarshal(bytes.TrimSpace([]byte(result.Stdout)), &output); err != nil {
+				return nil, &uvgo.PhaseError{Phase: uvgo.PhaseOutput, Err: fmt.Errorf("failed to unmarshal script output: %w", err)}
+			}
+			return json.Marshal(output)
+		},
+	}
+
+	ts.mu.Lock()
+	defer ts.mu.Unlock()
+	ts.tools[name] = t
+	return nil
+}
+
+// Definitions returns the definitions of every tool sorted by name
+func (ts *Toolset) Definitions() []Definition {
+	ts.mu.RLock()
+	defer ts.mu.RUnlock()
+
+	definitions := make([]Definition, 0, len(ts.tools))
+	for _, t := range ts.tools {
+		definitions = append(definitions, t.definition)
+	}
+	sort.Slice(definitions, func(i, j int) bool { return definitions[i].Name < definitions[j].Name })
+	return definitions
+}
+
+// Call runs the tool with the JSON arguments supplied by the model and returns its JSON result
+func (ts *Toolset) Call(ctx context.Context, name string, arguments json.RawMessage) (json.RawMessage, error) {
+	ts.mu.RLock()
+	t, ok := ts.tools[name]
+	ts.mu.RUnlock()
+
+	if !ok {
+		return nil, fmt.Errorf("unknown tool %s", name)
+	}
+	return t.call(ctx, arguments)
+}
+
+// decodeStrict unmarshals JSON, rejecting fields the target type does not declare
+func decodeStrict(data []byte, v any) error {
+	if len(bytes.TrimSpace(data)) == 0 {
+		data = []byte("{}")
+	}
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.DisallowUnknownFields()
+	return dec.Decode(v)
+}
@@ -0,0 +1,126 @@
+package uvgo
+
+import (
+	"context"
+	"slices"
+	"strings"
+)
+
+// TorchBackend selects a build of PyTorch, named like the values of uv's --torch-backend setting
+type TorchBackend string
+
+const (
+	// TorchAuto lets uv pick the build matching the host's GPU driver. uv only supports it when installing
+	// with its pip interface, so it applies to named environments and not to plain runs.
+	TorchAuto  TorchBackend = "auto"
+	TorchCPU   TorchBackend = "cpu"
+	TorchCU118 TorchBackend = "cu118"
+	TorchCU121 TorchBackend = "cu121"
+	TorchCU124 TorchBackend = "cu124"
+	TorchCU126 TorchBackend = "cu126"
+	TorchCU128 TorchBackend = "cu128"
+	TorchROCm  TorchBackend = "rocm6.3"
+	TorchXPU   TorchBackend = "xpu"
+)
+
+// torchIndexBase is the PyTorch package index, with one sub-index per backend
+const torchIndexBase = "https://download.pytorch.org/whl/"
+
+// IndexURL returns the PyTorch index hosting the builds for the backend, or "" for TorchAuto
+func (b TorchBackend) IndexURL() string {
+	if b == "" || b == TorchAuto {
+		return ""
+	}
+	return torchIndexBase + string(b)
+}
+
+// WithTorchBackend installs torch, torchvision and torchaudio built for the given backend, such as TorchCPU
+// to avoid downloading CUDA libraries or TorchCU124 for CUDA 12.4, by searching its PyTorch index ahead of the
+// default index and setting uv's torch backend
+func WithTorchBackend(backend TorchBackend) Option {
+	return func(r *Runner) { r.torchBackend = backend }
+}
+
+// torchEnv returns the environment variables selecting uv's torch backend
+func (r *Runner) torchEnv() []string {
+	if r.torchBackend == "" {
+		return nil
+	}
+	return []string{"UV_TORCH_BACKEND=" + string(r.torchBackend)}
+}
+
+// GPUDevice describes an accelerator visible to PyTorch
+type GPUDevice struct {
+	Index int    `json:"index"`
+	Name  string `json:"name"`
+	// Memory is the total device memory in bytes, when PyTorch reports it
+	Memory int64 `json:"memory,omitempty"`
+	// Capability is the CUDA compute capability, such as "8.6", for CUDA devices
+	Capability string `json:"capability,omitempty"`
+}
+
+// GPUInfo describes the accelerators PyTorch can use in the runner's environment
+type GPUInfo struct {
+	// Available reports whether any accelerator is usable
+	Available bool `json:"available"`
+	// Backend is the accelerator PyTorch uses: "cuda", "rocm", "xpu", "mps" or "cpu"
+	Backend      string      `json:"backend"`
+	TorchVersion string      `json:"torch_version"`
+	CUDAVersion  string      `json:"cuda_version"`
+	Devices      []GPUDevice `json:"devices"`
+}
+
+const gpuProbeScript = `import json
+
+import torch
+
+info = {"torch_version": torch.__version__, "cuda_version": torch.version.cuda, "devices": []}
+if torch.cuda.is_available():
+    info["backend"] = "rocm" if getattr(torch.version, "hip", None) else "cuda"
+    for i in range(torch.cuda.device_count()):
+        props = torch.cuda.get_device_properties(i)
+        capability = "%d.%d" % (props.major, props.minor)
+        info["devices"].append({"index": i, "name": props.name, "memory": props.total_memory, "capability": capability})
+elif hasattr(torch, "xpu") and torch.xpu.is_available():
+    info["backend"] = "xpu"
+    for i in range(torch.xpu.device_count()):
+        props = torch.xpu.get_device_properties(i)
+        info["devices"].append({"index": i, "name": props.name, "memory": props.total_memory})
+elif torch.backends.mps.is_available():
+    info["backend"] = "mps"
+    info["devices"].append({"index": 0, "name": "mps"})
+else:
+    info["backend"] = "cpu"
+info["available"] = info["backend"] != "cpu"
+
+print(json.dumps(info))
+`
+
+// ProbeGPU runs a probe script in the runner's environment, adding torch to its dependencies unless it is
+// already one, and reports the accelerators PyTorch can use there
+func (r *Runner) ProbeGPU(ctx context.Context) (*GPUInfo, error) {
+	probe := r
+	if !r.hasDependency("torch") {
+		probe = r.With(WithDependencies(slices.Concat(r.dependencies, []string{"torch"})...))
+	}
+
+	result, err := StructuredOutputFromString[GPUInfo](ctx, probe, gpuProbeScript)
+	if err != nil {
+		return nil, err
+	}
+	return &result.Data, nil
+}
+
+// hasDependency reports whether the runner depends on the named package, ignoring extras and version specifiers
+func (r *Runner) hasDependency(name string) bool {
+	for _, dep := range r.dependencies {
+		end := strings.IndexAny(dep, "[<>=!~;@ ")
+		if end < 0 {
+			end = len(dep)
+		}
+		if strings.EqualFold(strings.TrimSpace(dep[:end]), name) {
+			return true
+		}
+	}
+	return false
+}
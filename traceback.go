@@ -0,0 +1,194 @@
+package uvgo
+
+import (
+	"io/fs"
+	"maps"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// Traceback is a Python traceback parsed from the stderr of a failed script
+type Traceback struct {
+	// Exception is the exception class as Python printed it, qualified with its module unless it is a builtin,
+	// such as "ValueError" or "requests.exceptions.ConnectionError"
+	Exception string
+	Message   string
+	// Frames lists the stack from the outermost call to the one that raised, without the frames of the
+	// runner's own bootstrap
+	Frames []Frame
+}
+
+// Frame is a single stack frame of a Traceback
+type Frame struct {
+	File     string
+	Line     int
+	Function string
+	Code     string
+}
+
+// Is reports whether the exception is of the class name, comparing only class names when either is not
+// qualified with its module
+func (t *Traceback) Is(name string) bool {
+	if t.Exception == name {
+		return true
+	}
+	if !strings.Contains(name, ".") || !strings.Contains(t.Exception, ".") {
+		return exceptionName(t.Exception) == exceptionName(name)
+	}
+	return false
+}
+
+// String returns the final line of the traceback, such as "ValueError: bad input"
+func (t *Traceback) String() string {
+	if t.Message == "" {
+		return t.Exception
+	}
+	return t.Exception + ": " + t.Message
+}
+
+var (
+	frameLine     = regexp.MustCompile(`^  File "(.*)", line (\d+)(?:, in (.*))?$`)
+	exceptionLine = regexp.MustCompile(`^([A-Za-z_][\w.]*)(?:: (.*))?$`)
+)
+
+// ParseTraceback extracts the traceback of the exception that ended a script from its stderr, returning nil
+// when there is none. When exceptions were chained, the last one printed is returned.
+func ParseTraceback(stderr string) *Traceback {
+	lines := strings.Split(strings.ReplaceAll(stderr, "\r\n", "\n"), "\n")
+
+	start := -1
+	for i, line := range lines {
+		if line == "Traceback (most recent call last):" {
+			start = i + 1
+		}
+	}
+	if start < 0 {
+		// syntax errors in the script itself are reported without a header
+		for i, line := range lines {
+			if frameLine.MatchString(line) {
+				start = i
+				break
+			}
+		}
+	}
+	if start < 0 {
+		return nil
+	}
+
+	t := &Traceback{}
+	for i := start; i < len(lines); i++ {
+		line := lines[i]
+		if m := frameLine.FindStringSubmatch(line); m != nil {
+			n, _ := strconv.Atoi(m[2])
+			t.Frames = append(t.Frames, Frame{File: m[1], Line: n, Function: m[3]})
+			continue
+		}
+		if strings.HasPrefix(line, " ") {
+			if f := len(t.Frames) - 1; f >= 0 && t.Frames[f].Code == "" {
+				t.Frames[f].Code = strings.TrimSpace(line)
+			}
+			continue
+		}
+
+		m := exceptionLine.FindStringSubmatch(line)
+		if m == nil {
+			return nil
+		}
+		t.Exception = m[1]
+		message := []string{m[2]}
+		for _, next := range lines[i+1:] {
+			if next == "" {
+				break
+			}
+			message = append(message, next)
+		}
+		t.Message = strings.Join(message, "\n")
+		break
+	}
+	if t.Exception == "" {
+		return nil
+	}
+
+	t.Frames = slices.DeleteFunc(t.Frames, func(f Frame) bool {
+		return f.File == "<frozen runpy>" || strings.HasSuffix(f.File, "/runpy.py") || strings.HasSuffix(f.File, "uvgo_bootstrap.py")
+	})
+	return t
+}
+
+// exceptionName returns the unqualified name of an exception class
+func exceptionName(name string) string {
+	if i := strings.LastIndexByte(name, '.'); i >= 0 {
+		return name[i+1:]
+	}
+	return name
+}
+
+// defaultExceptionErrors maps Python exceptions to the Go errors with the same meaning
+var defaultExceptionErrors = map[string]error{
+	"FileNotFoundError": fs.ErrNotExist,
+	"FileExistsError":   fs.ErrExist,
+	"PermissionError":   fs.ErrPermission,
+}
+
+// WithExceptionErrors maps Python exception classes to Go errors, so that errors.Is matches the mapped error
+// when a script fails with that exception. Classes are matched by qualified name, or by class name alone when no
+// mapping matches exactly. FileNotFoundError, FileExistsError and
+// PermissionError map to fs.ErrNotExist, fs.ErrExist and fs.ErrPermission unless overridden. Mappings from
+// repeated calls are merged.
+func WithExceptionErrors(mapping map[string]error) Option {
+	return func(r *Runner) {
+		merged := maps.Clone(r.exceptionErrors)
+		if merged == nil {
+			merged = make(map[string]error, len(mapping))
+		}
+		maps.Copy(merged, mapping)
+		r.exceptionErrors = merged
+	}
+}
+
+// PythonError reports a script that failed with an uncaught Python exception. Its message is that of the
+// underlying run error, and errors.Is also matches the Go error the exception class is mapped to.
+type PythonError struct {
+	Traceback *Traceback
+	// Mapped is the Go error the exception class is mapped to, if any
+	Mapped error
+	Err    error
+}
+
+func (e *PythonError) Error() string {
+	return e.Err.Error()
+}
+
+func (e *PythonError) Unwrap() []error {
+	if e.Mapped == nil {
+		return []error{e.Err}
+	}
+	return []error{e.Mapped, e.Err}
+}
+
+// pythonError attaches the traceback in the stderr of a failed run to its error, if there is one
+func (r *Runner) pythonError(stderr string, err error) error {
+	tb := ParseTraceback(stderr)
+	if tb == nil {
+		return err
+	}
+	return &PythonError{Traceback: tb, Mapped: r.exceptionError(tb), Err: err}
+}
+
+// exceptionError returns the Go error an exception is mapped to, preferring an exact match of the class name
+// over one ignoring the module, since Python omits the module of classes defined in builtins and the script
+func (r *Runner) exceptionError(tb *Traceback) error {
+	for _, mapping := range []map[string]error{r.exceptionErrors, defaultExceptionErrors} {
+		if err, ok := mapping[tb.Exception]; ok {
+			return err
+		}
+		for _, name := range slices.Sorted(maps.Keys(mapping)) {
+			if exceptionName(name) == exceptionName(tb.Exception) {
+				return mapping[name]
+			}
+		}
+	}
+	return nil
+}
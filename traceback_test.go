@@ -0,0 +1,173 @@
+package uvgo
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestParseTraceback(t *testing.T) {
+	tests := []struct {
+		name   string
+		stderr string
+		want   *Traceback
+	}{
+		{
+			name:   "no traceback",
+			stderr: "warning: something odd\n",
+			want:   nil,
+		},
+		{
+			name: "builtin exception",
+			stderr: "Traceback (most recent call last):\n" +
+				"  File \"/tmp/script.py\", line 3, in <module>\n" +
+				"    main()\n" +
+				"  File \"/tmp/script.py\", line 2, in main\n" +
+				"    raise ValueError(\"bad input\")\n" +
+				"ValueError: bad input\n",
+			want: &Traceback{
+				Exception: "ValueError",
+				Message:   "bad input",
+				Frames: []Frame{
+					{File: "/tmp/script.py", Line: 3, Function: "<module>", Code: "main()"},
+					{File: "/tmp/script.py", Line: 2, Function: "main", Code: "raise ValueError(\"bad input\")"},
+				},
+			},
+		},
+		{
+			name: "qualified exception without message",
+			stderr: "Traceback (most recent call last):\n" +
+				"  File \"/tmp/script.py\", line 1, in <module>\n" +
+				"    raise requests.exceptions.ConnectionError\n" +
+				"requests.exceptions.ConnectionError\n",
+			want: &Traceback{
+				Exception: "requests.exceptions.ConnectionError",
+				Frames: []Frame{
+					{File: "/tmp/script.py", Line: 1, Function: "<module>", Code: "raise requests.exceptions.ConnectionError"},
+				},
+			},
+		},
+		{
+			name: "multiline message",
+			stderr: "Traceback (most recent call last):\n" +
+				"  File \"/tmp/script.py\", line 1, in <module>\n" +
+				"    fail()\n" +
+				"RuntimeError: first line\n" +
+				"second line\n" +
+				"\n" +
+				"trailing output\n",
+			want: &Traceback{
+				Exception: "RuntimeError",
+				Message:   "first line\nsecond line",
+				Frames: []Frame{
+					{File: "/tmp/script.py", Line: 1, Function: "<module>", Code: "fail()"},
+				},
+			},
+		},
+		{
+			name: "chained exceptions keep the last",
+			stderr: "Traceback (most recent call last):\n" +
+				"  File \"/tmp/script.py\", line 2, in <module>\n" +
+				"    int(\"x\")\n" +
+				"ValueError: invalid literal\n" +
+				"\n" +
+				"During handling of the above exception, another exception occurred:\n" +
+				"\n" +
+				"Traceback (most recent call last):\n" +
+				"  File \"/tmp/script.py\", line 4, in <module>\n" +
+				"    raise KeyError(\"k\")\n" +
+				"KeyError: 'k'\n",
+			want: &Traceback{
+				Exception: "KeyError",
+				Message:   "'k'",
+				Frames: []Frame{
+					{File: "/tmp/script.py", Line: 4, Function: "<module>", Code: "raise KeyError(\"k\")"},
+				},
+			},
+		},
+		{
+			name: "bootstrap frames removed",
+			stderr: "Traceback (most recent call last):\n" +
+				"  File \"<frozen runpy>\", line 198, in _run_module_as_main\n" +
+				"  File \"/usr/lib/python3.12/runpy.py\", line 88, in _run_code\n" +
+				"    exec(code, run_globals)\n" +
+				"  File \"/tmp/uvgo_bootstrap.py\", line 10, in <module>\n" +
+				"    runpy.run_path(path)\n" +
+				"  File \"/tmp/script.py\", line 1, in <module>\n" +
+				"    1 / 0\n" +
+				"ZeroDivisionError: division by zero\n",
+			want: &Traceback{
+				Exception: "ZeroDivisionError",
+				Message:   "division by zero",
+				Frames: []Frame{
+					{File: "/tmp/script.py", Line: 1, Function: "<module>", Code: "1 / 0"},
+				},
+			},
+		},
+		{
+			name: "syntax error without header",
+			stderr: "  File \"/tmp/script.py\", line 1\n" +
+				"    def (\n" +
+				"        ^\n" +
+				"SyntaxError: invalid syntax\n",
+			want: &Traceback{
+				Exception: "SyntaxError",
+				Message:   "invalid syntax",
+				Frames: []Frame{
+					{File: "/tmp/script.py", Line: 1, Code: "def ("},
+				},
+			},
+		},
+		{
+			name: "windows line endings",
+			stderr: "Traceback (most recent call last):\r\n" +
+				"  File \"C:\\script.py\", line 1, in <module>\r\n" +
+				"    boom()\r\n" +
+				"NameError: name 'boom' is not defined\r\n",
+			want: &Traceback{
+				Exception: "NameError",
+				Message:   "name 'boom' is not defined",
+				Frames: []Frame{
+					{File: "C:\\script.py", Line: 1, Function: "<module>", Code: "boom()"},
+				},
+			},
+		},
+		{
+			name: "header without exception line",
+			stderr: "Traceback (most recent call last):\n" +
+				"  File \"/tmp/script.py\", line 1, in <module>\n" +
+				"    boom()\n",
+			want: nil,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := ParseTraceback(tt.stderr)
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("ParseTraceback() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestTracebackIs(t *testing.T) {
+	tests := []struct {
+		exception string
+		name      string
+		want      bool
+	}{
+		{"ValueError", "ValueError", true},
+		{"ValueError", "TypeError", false},
+		{"requests.exceptions.ConnectionError", "ConnectionError", true},
+		{"ConnectionError", "requests.exceptions.ConnectionError", true},
+		{"requests.exceptions.ConnectionError", "requests.exceptions.ConnectionError", true},
+		{"requests.exceptions.ConnectionError", "httpx.ConnectionError", false},
+	}
+
+	for _, tt := range tests {
+		tb := &Traceback{Exception: tt.exception}
+		if got := tb.Is(tt.name); got != tt.want {
+			t.Errorf("Traceback{%q}.Is(%q) = %v, want %v", tt.exception, tt.name, got, tt.want)
+		}
+	}
+}
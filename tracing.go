@@ -0,0 +1,84 @@
+package uvgo
+
+import (
+	"context"
+	"os"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/trace"
+	"go.opentelemetry.io/otel/trace/noop"
+)
+
+const tracerName = "github.com/joeychilson/uvgo"
+
+// WithTracerProvider records script executions as OpenTelemetry spans and propagates the trace context to
+// the script through the TRACEPARENT and TRACESTATE environment variables
+func WithTracerProvider(provider trace.TracerProvider) Option {
+	return func(r *Runner) { r.tracer = provider.Tracer(tracerName) }
+}
+
+// startSpan starts a span for a script execution, doing nothing when tracing is disabled
+func (r *Runner) startSpan(ctx context.Context, name, runID, scriptPath, scriptContent string) (context.Context, trace.Span) {
+	if r.tracer == nil {
+		return ctx, noop.Span{}
+	}
+
+	attrs := []attribute.KeyValue{
+		attribute.String("uvgo.python.version", r.pythonVersion),
+		attribute.Int("uvgo.dependencies.count", len(r.dependencies)),
+	}
+	if runID != "" {
+		attrs = append(attrs, attribute.String("uvgo.run.id", runID))
+	}
+	if hash, err := scriptHash(scriptPath, scriptContent); err == nil {
+		attrs = append(attrs, attribute.String("uvgo.script.hash", hash))
+	}
+
+	return r.tracer.Start(ctx, name, trace.WithAttributes(attrs...))
+}
+
+// endSpan records the outcome of a script execution on its span and ends it
+func endSpan(span trace.Span, result *Result, err error) {
+	if result != nil {
+		span.SetAttributes(attribute.Int("uvgo.exit_code", result.ExitCode))
+	}
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+	}
+	span.End()
+}
+
+// traceEnv returns the environment variables carrying the current trace context to the script
+func (r *Runner) traceEnv(ctx context.Context) []string {
+	if r.tracer == nil {
+		return nil
+	}
+
+	carrier := propagation.MapCarrier{}
+	propagation.TraceContext{}.Inject(ctx, carrier)
+
+	var env []string
+	if traceparent := carrier.Get("traceparent"); traceparent != "" {
+		env = append(env, "TRACEPARENT="+traceparent)
+	}
+	if tracestate := carrier.Get("tracestate"); tracestate != "" {
+		env = append(env, "TRACESTATE="+tracestate)
+	}
+	return env
+}
+
+// scriptHash returns the digest of a script given either its path or, for "-", its content
+func scriptHash(scriptPath, scriptContent string) (string, error) {
+	if scriptPath == "-" {
+		return hashScript(scriptContent), nil
+	}
+
+	content, err := os.ReadFile(scriptPath)
+	if err != nil {
+		return "", err
+	}
+	return hashScript(string(content)), nil
+}
@@ -0,0 +1,68 @@
+package uvgo
+
+import (
+	"io"
+	"strings"
+	"sync"
+	"time"
+)
+
+// OutputChunk is a single write of a script to stdout or stderr
+type OutputChunk struct {
+	// Stream is "stdout" or "stderr"
+	Stream string
+	Data   string
+	Time   time.Time
+}
+
+// Transcript is the output of a run across both streams in the order it was written
+type Transcript []OutputChunk
+
+// String returns the combined output, as a terminal would show it
+func (t Transcript) String() string {
+	var b strings.Builder
+	for _, chunk := range t {
+		b.WriteString(chunk.Data)
+	}
+	return b.String()
+}
+
+// WithTranscript records the merged stdout and stderr of every run in Result.Transcript, keeping the
+// interleaving that separate buffers lose. Like the streaming handlers, it makes Python output unbuffered so
+// the order is accurate.
+func WithTranscript() Option {
+	return func(r *Runner) { r.transcript = true }
+}
+
+// transcriptRecorder collects the chunks written to the writers it hands out
+type transcriptRecorder struct {
+	mu     sync.Mutex
+	chunks Transcript
+}
+
+// writer returns a writer recording every write as a chunk of stream
+func (t *transcriptRecorder) writer(stream string) io.Writer {
+	return writerFunc(func(p []byte) (int, error) {
+		t.mu.Lock()
+		defer t.mu.Unlock()
+		t.chunks = append(t.chunks, OutputChunk{Stream: stream, Data: string(p), Time: time.Now()})
+		return len(p), nil
+	})
+}
+
+// result returns the recorded transcript, or nil when t is nil
+func (t *transcriptRecorder) result() Transcript {
+	if t == nil {
+		return nil
+	}
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return t.chunks
+}
+
+// writerFunc adapts a function to io.Writer
+type writerFunc func(p []byte) (int, error)
+
+func (f writerFunc) Write(p []byte) (int, error) {
+	return f(p)
+}
@@ -0,0 +1,133 @@
+package uvgo
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+)
+
+// TypeDiagnostic represents a single message reported by mypy
+type TypeDiagnostic struct {
+	File     string `json:"file"`
+	Line     int    `json:"line"`
+	Column   int    `json:"column"`
+	Message  string `json:"message"`
+	Hint     string `json:"hint"`
+	Code     string `json:"code"`
+	Severity string `json:"severity"`
+}
+
+// TypeCheckResult represents the output of a mypy type check
+type TypeCheckResult struct {
+	*Result
+	Diagnostics []TypeDiagnostic
+}
+
+// OK reports whether the type check found no errors
+func (t *TypeCheckResult) OK() bool {
+	for _, d := range t.Diagnostics {
+		if d.Severity == "error" {
+			return false
+		}
+	}
+	return true
+}
+
+// TypeCheck runs mypy against a Python script file with optional extra mypy flags
+func (r *Runner) TypeCheck(ctx context.Context, scriptPath string, flags ...string) (*TypeCheckResult, error) {
+	if _, err := os.Stat(scriptPath); os.IsNotExist(err) {
+		return nil, fmt.Errorf("script file does not exist: %w", err)
+	}
+	return r.typeCheck(ctx, scriptPath, flags)
+}
+
+// TypeCheckFromString runs mypy against a Python script from a string with optional extra mypy flags
+func (r *Runner) TypeCheckFromString(ctx context.Context, script string, flags ...string) (*TypeCheckResult, error) {
+	if script == "" {
+		return nil, fmt.Errorf("empty script provided")
+	}
+
+	scriptPath, cleanup, err := writeTempScript(script)
+	if err != nil {
+		return nil, err
+	}
+	defer cleanup()
+
+	return r.typeCheck(ctx, scriptPath, flags)
+}
+
+func (r *Runner) typeCheck(ctx context.Context, scriptPath string, flags []string) (*TypeCheckResult, error) {
+	ctx, cancel := r.withTimeout(ctx)
+	defer cancel()
+
+	if err := r.syncEnv(ctx); err != nil {
+		return nil, &PhaseError{Phase: PhaseResolution, Err: err}
+	}
+
+	uvArgs := append(r.runArgs("mypy"), "mypy", "--output", "json", "--no-error-summary", "--no-color-output")
+	uvArgs = append(uvArgs, flags...)
+	uvArgs = append(uvArgs, scriptPath)
+
+	result, err := r.runUV(ctx, &execution{}, uvArgs, nil)
+	if err != nil {
+		// mypy exits with code 1 when it reports type errors
+		var exitError *ExitError
+		if !errors.As(err, &exitError) || exitError.Code != 1 || ctx.Err() != nil {
+			return &TypeCheckResult{Result: result}, r.runError(ctx, result, err)
+		}
+	}
+
+	diagnostics, err := parseTypeDiagnostics(result.Stdout)
+	if err != nil {
+		return &TypeCheckResult{Result: result}, err
+	}
+
+	return &TypeCheckResult{
+		Result:      result,
+		Diagnostics: diagnostics,
+	}, nil
+}
+
+func parseTypeDiagnostics(output string) ([]TypeDiagnostic, error) {
+	var diagnostics []TypeDiagnostic
+
+	scanner := bufio.NewScanner(strings.NewReader(output))
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if !strings.HasPrefix(line, "{") {
+			continue
+		}
+
+		var d TypeDiagnostic
+		if err := json.Unmarshal([]byte(line), &d); err != nil {
+			return nil, fmt.Errorf("failed to unmarshal mypy output: %w", err)
+		}
+		diagnostics = append(diagnostics, d)
+	}
+	return diagnostics, nil
+}
+
+// writeTempScript writes a script to a temporary file and returns its path along with a cleanup function
+func writeTempScript(script string) (string, func(), error) {
+	f, err := os.CreateTemp("", "uvgo-*.py")
+	if err != nil {
+		return "", nil, fmt.Errorf("failed to create temporary script file: %w", err)
+	}
+
+	cleanup := func() { os.Remove(f.Name()) }
+
+	if _, err := f.WriteString(script); err != nil {
+		f.Close()
+		cleanup()
+		return "", nil, fmt.Errorf("failed to write temporary script file: %w", err)
+	}
+	if err := f.Close(); err != nil {
+		cleanup()
+		return "", nil, fmt.Errorf("failed to write temporary script file: %w", err)
+	}
+	return f.Name(), cleanup, nil
+}
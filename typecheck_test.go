@@ -0,0 +1,81 @@
+package uvgo
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestParseTypeDiagnostics(t *testing.T) {
+	tests := []struct {
+		name    string
+		output  string
+		want    []TypeDiagnostic
+		wantErr bool
+	}{
+		{
+			name:   "clean",
+			output: "",
+			want:   nil,
+		},
+		{
+			name: "diagnostics",
+			output: `{"file": "script.py", "line": 3, "column": 4, "message": "Incompatible return value type (got \"str\", expected \"int\")", "hint": null, "code": "return-value", "severity": "error"}` + "\n" +
+				`{"file": "script.py", "line": 5, "column": 0, "message": "Name \"foo\" is not defined", "hint": "Did you mean \"for\"?", "code": "name-defined", "severity": "error"}` + "\n" +
+				`{"file": "script.py", "line": 5, "column": 0, "message": "See docs", "hint": null, "code": null, "severity": "note"}` + "\n",
+			want: []TypeDiagnostic{
+				{File: "script.py", Line: 3, Column: 4, Message: `Incompatible return value type (got "str", expected "int")`, Code: "return-value", Severity: "error"},
+				{File: "script.py", Line: 5, Message: `Name "foo" is not defined`, Hint: `Did you mean "for"?`, Code: "name-defined", Severity: "error"},
+				{File: "script.py", Line: 5, Message: "See docs", Severity: "note"},
+			},
+		},
+		{
+			name: "other output skipped",
+			output: "Installed 1 package in 3ms\n" +
+				`  {"file": "script.py", "line": 1, "column": 0, "message": "m", "hint": null, "code": "misc", "severity": "error"}  ` + "\n" +
+				"Found 1 error in 1 file\n",
+			want: []TypeDiagnostic{
+				{File: "script.py", Line: 1, Message: "m", Code: "misc", Severity: "error"},
+			},
+		},
+		{
+			name:    "malformed",
+			output:  `{"file": "script.py", "line": "x"}`,
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := parseTypeDiagnostics(tt.output)
+			if tt.wantErr {
+				if err == nil {
+					t.Fatal("parseTypeDiagnostics() succeeded, want an error")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("parseTypeDiagnostics(): %v", err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("parseTypeDiagnostics() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestTypeCheckResultOK(t *testing.T) {
+	tests := []struct {
+		diagnostics []TypeDiagnostic
+		want        bool
+	}{
+		{nil, true},
+		{[]TypeDiagnostic{{Severity: "note"}}, true},
+		{[]TypeDiagnostic{{Severity: "note"}, {Severity: "error"}}, false},
+	}
+
+	for _, tt := range tests {
+		if got := (&TypeCheckResult{Diagnostics: tt.diagnostics}).OK(); got != tt.want {
+			t.Errorf("OK() with %+v = %v, want %v", tt.diagnostics, got, tt.want)
+		}
+	}
+}
@@ -2,25 +2,100 @@ package uvgo
 
 import (
 	"bufio"
-	"bytes"
 	"context"
-	"encoding/json"
+	"errors"
 	"fmt"
+	"io"
+	"io/fs"
+	"log/slog"
 	"os"
 	"os/exec"
+	"path/filepath"
+	"slices"
 	"strings"
 	"time"
+
+	"go.opentelemetry.io/otel/trace"
 )
 
-// Runner is a Python script runner using the UV tool
+// Runner is a Python script runner using the UV tool.
+// A Runner is not modified after creation and is safe for concurrent use.
 type Runner struct {
-	pythonVersion string
-	extraFlags    []string
-	timeout       time.Duration
-	env           []string
-	workDir       string
-	dependencies  []string
-	scriptArgs    []string
+	pythonVersion   string
+	extraFlags      []string
+	timeout         time.Duration
+	env             []string
+	workDir         string
+	dependencies    []string
+	scriptArgs      []string
+	coverage        bool
+	profileTop      int
+	memoryTop       int
+	artifacts       bool
+	maxArtifact     int64
+	inputFiles      []string
+	inputFS         []fs.FS
+	plotFormats     []string
+	history         HistoryStore
+	logger          *slog.Logger
+	tracer          trace.Tracer
+	metrics         *Metrics
+	middleware      []Middleware
+	events          *EventBus
+	executor        Executor
+	indexURL        string
+	extraIndexes    []string
+	timeoutPolicy   TimeoutPolicy
+	bridge          bool
+	onMessage       func(Message)
+	stdoutSink      io.Writer
+	stderrSink      io.Writer
+	limiter         *rateLimiter
+	admission       *admission
+	stats           *runStats
+	lineHandler     func(string)
+	stderrHandler   func(string)
+	transcript      bool
+	stripANSI       bool
+	utf8            bool
+	unbuffered      bool
+	signals         map[os.Signal]os.Signal
+	pty             bool
+	backends        *Backends
+	backendPolicy   BackendPolicy
+	backend         string
+	envSnapshot     bool
+	progress        ProgressHandler
+	pythonLogger    *slog.Logger
+	warnings        bool
+	warningFilters  []string
+	exceptionErrors map[string]error
+	decoders        map[string]Decoder
+	contentType     string
+	contentTypeHint bool
+	blobs           bool
+	callbacks       map[string]Callback
+	sharedInputs    map[string][]byte
+	channels        []channel
+	licenses        bool
+	allowedLicenses []string
+	timings         bool
+	namedEnv        string
+	envCache        bool
+	envCacheSize    int64
+	downloadLimit   int
+	buildLimit      int
+	installLimit    int
+	proxy           Proxy
+	caBundle        string
+	nativeTLS       bool
+	airGapped       string
+	schemaHint      bool
+	helperModules   map[string]string
+	helperFS        []fs.FS
+	torchBackend    TorchBackend
+	stdin           io.Reader
+	input           []byte
 }
 
 // Option represents a configuration option for the Runner
@@ -28,29 +103,73 @@ type Option func(*Runner)
 
 // New creates a new UV runner with the provided options
 func New(options ...Option) (*Runner, error) {
-	_, err := exec.LookPath("uv")
-	if err != nil {
-		return nil, fmt.Errorf("uv not found in PATH: %w", err)
-	}
-
-	r := &Runner{timeout: 30 * time.Second}
+	r := &Runner{timeout: 30 * time.Second, stats: newRunStats()}
 	for _, opt := range options {
 		opt(r)
 	}
+
+	if r.executor == nil {
+		_, err := exec.LookPath("uv")
+		if err != nil {
+			return nil, fmt.Errorf("uv not found in PATH: %w", err)
+		}
+		r.executor = LocalExecutor{}
+	}
 	return r, nil
 }
 
+// With returns a copy of the runner with the given options applied, leaving the original unchanged
+func (r *Runner) With(options ...Option) *Runner {
+	c := *r
+
+	// clipping forces options that append to reallocate instead of writing into the original's arrays
+	c.extraFlags = slices.Clip(c.extraFlags)
+	c.env = slices.Clip(c.env)
+	c.dependencies = slices.Clip(c.dependencies)
+	c.scriptArgs = slices.Clip(c.scriptArgs)
+	c.inputFiles = slices.Clip(c.inputFiles)
+	c.inputFS = slices.Clip(c.inputFS)
+	c.plotFormats = slices.Clip(c.plotFormats)
+	c.middleware = slices.Clip(c.middleware)
+	c.extraIndexes = slices.Clip(c.extraIndexes)
+	c.warningFilters = slices.Clip(c.warningFilters)
+	c.channels = slices.Clip(c.channels)
+	c.allowedLicenses = slices.Clip(c.allowedLicenses)
+	c.helperFS = slices.Clip(c.helperFS)
+
+	for _, opt := range options {
+		opt(&c)
+	}
+	return &c
+}
+
 // WithPython sets the python version to use
 func WithPython(version string) Option {
 	return func(r *Runner) { r.pythonVersion = version }
 }
 
+// WithIndexURL sets the default package index, replacing PyPI
+func WithIndexURL(url string) Option {
+	return func(r *Runner) { r.indexURL = url }
+}
+
+// WithExtraIndexURLs adds package indexes searched in addition to the default index
+func WithExtraIndexURLs(urls ...string) Option {
+	return func(r *Runner) { r.extraIndexes = append(r.extraIndexes, urls...) }
+}
+
 // WithExtraFlags sets the extra flags to pass to the UV command
 func WithExtraFlags(flags ...string) Option {
 	return func(r *Runner) { r.extraFlags = flags }
 }
 
-// WithTimeout sets the execution timeout
+// ExtraFlags returns the extra flags passed to the UV command, as set with WithExtraFlags
+func (r *Runner) ExtraFlags() []string {
+	return slices.Clone(r.extraFlags)
+}
+
+// WithTimeout sets the execution timeout, defaulting to 30 seconds. A zero timeout disables it, leaving only
+// the caller's context to bound a run.
 func WithTimeout(timeout time.Duration) Option {
 	return func(r *Runner) { r.timeout = timeout }
 }
@@ -75,12 +194,106 @@ func WithScriptArgs(args ...string) Option {
 	return func(r *Runner) { r.scriptArgs = args }
 }
 
+// WithCoverage enables collecting line coverage with coverage.py
+func WithCoverage() Option {
+	return func(r *Runner) { r.coverage = true }
+}
+
+// WithProfile runs the script under cProfile and reports the top functions by cumulative time
+func WithProfile(top int) Option {
+	return func(r *Runner) {
+		if top <= 0 {
+			top = 20
+		}
+		r.profileTop = top
+	}
+}
+
+// WithMemoryProfile runs the script under tracemalloc and reports the top allocation sites by size
+func WithMemoryProfile(top int) Option {
+	return func(r *Runner) {
+		if top <= 0 {
+			top = 20
+		}
+		r.memoryTop = top
+	}
+}
+
+// WithArtifacts exposes a managed output directory to the script through the UVGO_OUTPUT_DIR environment
+// variable and returns the files written to it, reading at most maxFileSize bytes per file when positive
+func WithArtifacts(maxFileSize int64) Option {
+	return func(r *Runner) {
+		r.artifacts = true
+		r.maxArtifact = maxFileSize
+	}
+}
+
+// WithInputFiles stages files into a temporary working directory under their base names before each run.
+// Files are hard-linked when possible, so scripts must not modify them in place.
+func WithInputFiles(paths ...string) Option {
+	return func(r *Runner) { r.inputFiles = append(r.inputFiles, paths...) }
+}
+
+// WithInputFS copies the contents of a file system into a temporary working directory before each run
+func WithInputFS(fsys fs.FS) Option {
+	return func(r *Runner) { r.inputFS = append(r.inputFS, fsys) }
+}
+
+// WithPlotCapture runs matplotlib with a headless backend and returns every open figure rendered in the
+// given formats, defaulting to png
+func WithPlotCapture(formats ...string) Option {
+	return func(r *Runner) {
+		if len(formats) == 0 {
+			formats = []string{"png"}
+		}
+		r.plotFormats = formats
+	}
+}
+
+// WithHistory records every script run in the given history store
+func WithHistory(store HistoryStore) Option {
+	return func(r *Runner) { r.history = store }
+}
+
+// WithLogger logs the lifecycle of every uv command, redacting secrets from arguments and environment
+func WithLogger(logger *slog.Logger) Option {
+	return func(r *Runner) { r.logger = logger }
+}
+
 // Result represents the output of a script execution
 type Result struct {
 	Stdout     string
 	Stderr     string
+	ExitCode   int
+	WallTime   time.Duration
 	SystemTime time.Duration
 	UserTime   time.Duration
+	Coverage   *Coverage
+	Profile    []FunctionStat
+	Memory     *MemoryReport
+	Artifacts  []Artifact
+	Figures    []Figure
+	Messages   []Message
+	Packages   []Package
+	Transcript Transcript
+	// Signal is the signal that ended a detached run waited on through a Process, if any
+	Signal os.Signal
+	// Warnings lists the Python warnings the script emitted when WithWarnings is set
+	Warnings []Warning
+	// ContentType is the content type the script declared for its output, if any
+	ContentType string
+	// Blobs lists the binary blobs the script returned when WithBlobs is set
+	Blobs []Blob
+	// SharedOutputs lists the memory-mapped outputs of the script, sorted by name, when WithSharedMemory is set
+	SharedOutputs []*MappedFile
+	// Channels holds the output of the channels added with WithChannel without a writer, by name
+	Channels map[string][]byte
+	// Licenses lists the licenses of the packages in the script's environment, sorted by name, when WithLicenses is set
+	Licenses []PackageLicense
+	// Timings breaks the wall time down into uv's environment setup and the script when WithTimings is set
+	Timings *Timings
+	// Schema is the output schema the script declared, if any
+	Schema SchemaVersion
 }
 
 // Run executes a Python script from a file with optional arguments
@@ -100,73 +313,438 @@ func (r *Runner) RunFromString(ctx context.Context, script string, args ...strin
 }
 
 func (r *Runner) execute(ctx context.Context, scriptPath, scriptContent string, args []string) (*Result, error) {
-	ctx, cancel := context.WithTimeout(ctx, r.timeout)
+	run := r.runRequest
+	for i := len(r.middleware) - 1; i >= 0; i-- {
+		run = r.middleware[i](run)
+	}
+	req := &RunRequest{ID: newID(), ScriptPath: scriptPath, Script: scriptContent, Args: args}
+	for _, ch := range r.channels {
+		if ch.r != nil {
+			req.inputs = append(req.inputs, ch.r)
+		}
+	}
+	if r.stdin != nil {
+		req.inputs = append(req.inputs, r.stdin)
+	}
+	return run(ctx, req)
+}
+
+func (r *Runner) runRequest(ctx context.Context, req *RunRequest) (*Result, error) {
+	ctx, span := r.startSpan(ctx, "uvgo.Run", req.ID, req.ScriptPath, req.Script)
+	done := r.metrics.begin()
+	r.events.publish(Event{Type: EventQueued, RunID: req.ID})
+	result, err := r.launch(ctx, req)
+	r.stats.record(result, err)
+	done(result, err)
+	endSpan(span, result, err)
+	r.events.finished(ctx, req.ID, result, err)
+
+	if r.history != nil {
+		if recordErr := r.record(ctx, req, result, err); recordErr != nil && err == nil {
+			return result, recordErr
+		}
+	}
+	return result, err
+}
+
+func (r *Runner) launch(ctx context.Context, req *RunRequest) (*Result, error) {
+	release, err := r.admission.acquire(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer release()
+
+	if err := r.limiter.wait(ctx); err != nil {
+		return nil, err
+	}
+
+	ctx, cancel := r.withTimeout(ctx)
 	defer cancel()
 
+	executor, err := r.route(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := r.syncEnv(ctx); err != nil {
+		return nil, &PhaseError{Phase: PhaseResolution, Err: err}
+	}
+
+	x := &execution{runID: req.ID, executor: executor, scriptRun: true}
+	defer x.cleanup()
+
+	uvArgs, stdin, err := r.prepare(x, req.ScriptPath, req.Script, req.Args)
+	if err != nil {
+		return nil, err
+	}
+
+	result, err := r.invoke(ctx, x, uvArgs, stdin)
+	collectErr := x.result(result)
+	if err != nil {
+		return result, r.runError(ctx, result, err)
+	}
+	if collectErr != nil {
+		return result, collectErr
+	}
+	return result, nil
+}
+
+// prepare instruments an execution and assembles the uv arguments and stdin for a script run
+func (r *Runner) prepare(x *execution, scriptPath, scriptContent string, args []string) ([]string, io.Reader, error) {
+	if err := r.instrument(x); err != nil {
+		return nil, nil, err
+	}
+
+	if scriptPath != "-" && x.workDir != "" {
+		var err error
+		if scriptPath, err = filepath.Abs(scriptPath); err != nil {
+			return nil, nil, fmt.Errorf("failed to resolve script path: %w", err)
+		}
+	}
+
+	if scriptPath != "-" {
+		x.script = scriptPath
+	}
+
+	var stdin io.Reader
+	if scriptPath == "-" {
+		// the script content can only go to stdin when nothing else is fed to it
+		if x.instrumented() || r.stdin != nil {
+			var err error
+			if scriptPath, err = x.writeFile("script.py", scriptContent); err != nil {
+				return nil, nil, err
+			}
+		} else {
+			stdin = strings.NewReader(scriptContent)
+		}
+	}
+
+	command, err := x.command(scriptPath)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	uvArgs := append(r.runArgs(x.with...), command...)
+	return append(uvArgs, r.runScriptArgs(args)...), stdin, nil
+}
+
+// runScriptArgs returns the arguments a script runs with, which are the runner's default arguments unless the
+// run passes its own
+func (r *Runner) runScriptArgs(args []string) []string {
+	if len(args) > 0 {
+		return args
+	}
+	return r.scriptArgs
+}
+
+// runArgs returns the uv run arguments shared by every invocation, adding any extra packages to the environment
+func (r *Runner) runArgs(with ...string) []string {
 	uvArgs := []string{"run"}
 
-	if r.pythonVersion != "" {
-		uvArgs = append(uvArgs, "--python", r.pythonVersion)
+	if r.persistentEnv() {
+		// the persistent environment already holds the dependencies, so only the extra packages are layered on top
+		uvArgs = append(uvArgs, "--no-project", "--python", r.envPython())
+	} else {
+		if r.pythonVersion != "" {
+			uvArgs = append(uvArgs, "--python", r.pythonVersion)
+		}
+
+		for _, dep := range r.dependencies {
+			uvArgs = append(uvArgs, "--with", dep)
+		}
 	}
 
-	for _, dep := range r.dependencies {
+	for _, dep := range with {
 		uvArgs = append(uvArgs, "--with", dep)
 	}
 
-	uvArgs = append(uvArgs, r.extraFlags...)
-	uvArgs = append(uvArgs, scriptPath)
+	uvArgs = append(uvArgs, r.indexArgs()...)
+	return append(uvArgs, r.extraFlags...)
+}
 
-	var scriptArgs []string
-	if len(args) > 0 {
-		scriptArgs = args
-	} else if len(r.scriptArgs) > 0 {
-		scriptArgs = r.scriptArgs
+// indexArgs returns the uv arguments selecting the configured package indexes
+func (r *Runner) indexArgs() []string {
+	var uvArgs []string
+
+	// air-gapped mode installs from its wheel directory only, which uv refuses to combine with an index
+	if r.airGapped != "" {
+		return nil
+	}
+
+	if r.indexURL != "" {
+		uvArgs = append(uvArgs, "--index-url", r.indexURL)
+	}
+
+	for _, url := range r.extraIndexes {
+		uvArgs = append(uvArgs, "--extra-index-url", url)
+	}
+	if url := r.torchBackend.IndexURL(); url != "" {
+		uvArgs = append(uvArgs, "--extra-index-url", url)
 	}
+	return uvArgs
+}
 
-	if len(scriptArgs) > 0 {
-		uvArgs = append(uvArgs, scriptArgs...)
+// invoke runs uv with the given arguments and returns the captured output along with the raw process error
+func (r *Runner) invoke(ctx context.Context, x *execution, uvArgs []string, stdin io.Reader) (*Result, error) {
+	release, err := acquireUVProcess(ctx)
+	if err != nil {
+		// callers inspect the result of a failed invocation, so an empty one stands in for the run that never started
+		return &Result{}, err
 	}
+	defer release()
 
-	cmd := exec.CommandContext(ctx, "uv", uvArgs...)
+	if stdin == nil {
+		stdin = r.stdin
+	}
 
-	if r.workDir != "" {
-		cmd.Dir = r.workDir
+	stdout, stderr := capture(r.stdoutSink), capture(r.stderrSink)
+	inv := &Invocation{
+		Args:    uvArgs,
+		Env:     r.commandEnv(ctx, x),
+		Dir:     r.commandDir(x),
+		Script:  x.script,
+		TempDir: x.dir,
+		Stdin:   stdin,
+		Stdout:  r.events.output(x.runID, "stdout", stdout),
+		Stderr:  r.events.output(x.runID, "stderr", stderr),
+		TTY:     r.pty,
+		Started: func(pid int) {
+			r.logStarted(ctx, x.runID, pid)
+			r.events.publish(Event{Type: EventStarted, RunID: x.runID, PID: pid})
+		},
 	}
 
-	if len(r.env) > 0 {
-		cmd.Env = append(os.Environ(), r.env...)
+	if r.lineHandler != nil {
+		lines := &lineWriter{fn: r.outputHandler(r.lineHandler)}
+		defer lines.flush()
+		inv.Stdout = io.MultiWriter(inv.Stdout, lines)
+	}
+	if r.stderrHandler != nil {
+		lines := &lineWriter{fn: r.outputHandler(r.stderrHandler)}
+		defer lines.flush()
+		inv.Stderr = io.MultiWriter(inv.Stderr, lines)
 	}
 
-	var stdout, stderr bytes.Buffer
-	cmd.Stdout = &stdout
-	cmd.Stderr = &stderr
+	if r.signals != nil {
+		forwarder := forwardSignals(r.signals)
+		defer forwarder.stop()
+		started := inv.Started
+		inv.Started = func(pid int) {
+			forwarder.started(pid)
+			started(pid)
+		}
+	}
 
-	if scriptPath == "-" {
-		cmd.Stdin = strings.NewReader(scriptContent)
+	var channels *channelPipes
+	if len(r.channels) > 0 && x.scriptRun {
+		var err error
+		if channels, err = openChannels(r.channels); err != nil {
+			return nil, err
+		}
+		inv.Env = append(inv.Env, channels.env...)
+		inv.ExtraFiles = channels.files
+		started := inv.Started
+		inv.Started = func(pid int) {
+			channels.started()
+			started(pid)
+		}
 	}
 
-	err := cmd.Run()
+	var transcript *transcriptRecorder
+	if r.transcript {
+		transcript = &transcriptRecorder{}
+		inv.Stdout = io.MultiWriter(inv.Stdout, transcript.writer("stdout"))
+		inv.Stderr = io.MultiWriter(inv.Stderr, transcript.writer("stderr"))
+	}
+
+	var timings *Timings
+	if r.timings {
+		timings = &Timings{}
+		lines := &lineWriter{fn: timings.parseLine}
+		defer lines.flush()
+		inv.Stderr = io.MultiWriter(inv.Stderr, lines)
+	}
+
+	var directives *directiveWriter
+	var warnings []Warning
+	var contentType string
+	var schema SchemaVersion
+	if r.progress != nil || r.pythonLogger != nil || r.warnings || r.contentTypeHint || r.blobs || len(r.callbacks) > 0 || r.schemaHint {
+		directives = &directiveWriter{next: inv.Stderr, directives: map[string]func(string){}}
+		if progress := r.progress; progress != nil {
+			directives.directives[progressDirective] = func(payload string) { progress(parseProgress(payload)) }
+		}
+		if logger := r.pythonLogger; logger != nil {
+			directives.directives[logDirective] = func(payload string) { logPythonRecord(ctx, logger, payload) }
+		}
+		if r.warnings {
+			directives.directives[warningDirective] = func(payload string) { warnings = append(warnings, parseWarning(payload)) }
+		}
+		if r.blobs {
+			directives.directives[blobDirective] = x.addBlob
+		}
+		if len(r.callbacks) > 0 && x.scriptRun {
+			stdinReader, stdinWriter, err := os.Pipe()
+			if err != nil {
+				return nil, fmt.Errorf("failed to create callback pipe: %w", err)
+			}
+			defer stdinReader.Close()
+			defer stdinWriter.Close()
+			inv.Stdin = stdinReader
+			inv.InteractiveStdin = true
+			directives.directives[callDirective] = func(payload string) { r.call(ctx, stdinWriter, payload) }
+		}
+		if r.contentTypeHint {
+			directives.directives[contentTypeDirective] = func(payload string) { contentType = normalizeContentType(payload) }
+		}
+		if r.schemaHint {
+			directives.directives[schemaDirective] = func(payload string) { schema = parseSchemaVersion(payload) }
+		}
+		inv.Stderr = directives
+	}
+
+	r.logCommand(ctx, x.runID, inv)
+
+	start := time.Now()
+	executor := x.executor
+	if executor == nil {
+		executor = r.executor
+	}
+
+	status, err := executor.Execute(ctx, inv)
+	if directives != nil {
+		directives.flush()
+	}
+	var channelOutput map[string][]byte
+	if channels != nil {
+		channelOutput = channels.wait()
+	}
 
 	result := &Result{
-		Stdout:     stdout.String(),
-		Stderr:     stderr.String(),
-		SystemTime: cmd.ProcessState.SystemTime(),
-		UserTime:   cmd.ProcessState.UserTime(),
+		Stdout:      stdout.String(),
+		Stderr:      stderr.String(),
+		WallTime:    time.Since(start),
+		Transcript:  transcript.result(),
+		Warnings:    warnings,
+		ContentType: contentType,
+		Channels:    channelOutput,
+		Timings:     timings,
+		Schema:      schema,
+	}
+	if timings != nil {
+		timings.Total = result.WallTime
+	}
+	if r.stripANSI || r.utf8 {
+		result.Stdout = r.cleanOutput(result.Stdout)
+		result.Stderr = r.cleanOutput(result.Stderr)
+		for i := range result.Transcript {
+			result.Transcript[i].Data = r.cleanOutput(result.Transcript[i].Data)
+		}
+	}
+	if status == nil {
+		r.logFinished(ctx, x.runID, nil, err)
+		return result, err
 	}
 
+	result.SystemTime = status.SystemTime
+	result.UserTime = status.UserTime
+	result.ExitCode = status.ExitCode
+	r.logFinished(ctx, x.runID, result, err)
+	return result, err
+}
+
+// runUV runs an auxiliary uv command, such as uv venv or uv pip compile, fed only the given stdin. Unlike invoke
+// it leaves out everything belonging to script runs: callbacks, channels, the runner's stdin, signal
+// forwarding, output sinks and events.
+func (r *Runner) runUV(ctx context.Context, x *execution, uvArgs []string, stdin io.Reader) (*Result, error) {
+	release, err := acquireUVProcess(ctx)
 	if err != nil {
-		if ctx.Err() == context.DeadlineExceeded {
-			return result, fmt.Errorf("script execution timed out after %v: %w", r.timeout, err)
+		return &Result{}, err
+	}
+	defer release()
+
+	var stdout, stderr strings.Builder
+	inv := &Invocation{
+		Args:   uvArgs,
+		Env:    r.commandEnv(ctx, x),
+		Dir:    r.commandDir(x),
+		Stdin:  stdin,
+		Stdout: &stdout,
+		Stderr: &stderr,
+	}
+	r.logCommand(ctx, x.runID, inv)
+
+	start := time.Now()
+	executor := x.executor
+	if executor == nil {
+		executor = r.executor
+	}
+
+	status, err := executor.Execute(ctx, inv)
+	result := &Result{Stdout: stdout.String(), Stderr: stderr.String(), WallTime: time.Since(start)}
+	if status == nil {
+		r.logFinished(ctx, x.runID, nil, err)
+		return result, err
+	}
+
+	result.SystemTime = status.SystemTime
+	result.UserTime = status.UserTime
+	result.ExitCode = status.ExitCode
+	r.logFinished(ctx, x.runID, result, err)
+	return result, err
+}
+
+// outputHandler wraps a line handler with the runner's output processing
+func (r *Runner) outputHandler(fn func(string)) func(string) {
+	if !r.stripANSI && !r.utf8 {
+		return fn
+	}
+	return func(line string) { fn(r.cleanOutput(line)) }
+}
+
+// commandDir returns the working directory of a uv command
+func (r *Runner) commandDir(x *execution) string {
+	if x.workDir != "" {
+		return x.workDir
+	}
+	return r.workDir
+}
+
+// commandEnv returns the environment variables a uv command adds to the parent environment
+func (r *Runner) commandEnv(ctx context.Context, x *execution) []string {
+	env := append(r.concurrencyEnv(), r.networkEnv()...)
+	env = append(env, r.airGapEnv()...)
+	env = append(env, r.torchEnv()...)
+	env = append(env, r.env...)
+	env = append(env, r.traceEnv(ctx)...)
+	if r.unbuffered || r.lineHandler != nil || r.stderrHandler != nil || r.transcript || r.progress != nil {
+		env = append(env, "PYTHONUNBUFFERED=1")
+	}
+	return append(env, x.env...)
+}
+
+// runError converts a failed invocation into a descriptive error
+func (r *Runner) runError(ctx context.Context, result *Result, err error) error {
+	if ctx.Err() == context.DeadlineExceeded {
+		timeoutErr := &TimeoutError{Result: result, Err: err}
+		if runnerTimedOut(ctx) {
+			timeoutErr.Timeout = r.timeout
 		}
-		if exitError, ok := err.(*exec.ExitError); ok {
-			if result.Stderr != "" {
-				return result, fmt.Errorf("script execution failed: %s", result.Stderr)
+		return timeoutErr
+	}
+	var exitError *ExitError
+	if errors.As(err, &exitError) {
+		if result.Stderr != "" {
+			// a diagnostic of no recognized kind is as likely to come from the script as from uv
+			if uvErr := ParseUVError(result.Stderr); uvErr != nil && uvErr.Kind != UVErrorUnknown {
+				uvErr.Err = err
+				return uvErr
 			}
-			return result, fmt.Errorf("script execution failed with exit code %d: %w", exitError.ExitCode(), err)
+			return r.pythonError(result.Stderr, fmt.Errorf("script execution failed: %s", result.Stderr))
 		}
-		return result, fmt.Errorf("script execution failed: %w", err)
+		return fmt.Errorf("script execution failed with exit code %d: %w", exitError.Code, err)
 	}
-	return result, nil
+	return &PhaseError{Phase: PhaseBootstrap, Err: fmt.Errorf("script execution failed: %w", err)}
 }
 
 // StructuredResult adds typed data to the base Result
@@ -175,25 +753,29 @@ type StructuredResult[T any] struct {
 	Data T
 }
 
-// StructuredOutput runs a script and parses its output into the specified type
-func StructuredOutput[T any](ctx context.Context, r *Runner, scriptPath string, args ...string) (*StructuredResult[T], error) {
+// StructuredOutput runs a script and parses its output into the specified type with the decoder for the
+// content type the script declares, or the runner's content type, which defaults to JSON
+func StructuredOutput[T any](ctx context.Context, r *Runner, scriptPath string, args ...string) (_ *StructuredResult[T], err error) {
+	ctx, span := r.startSpan(ctx, "uvgo.StructuredOutput", "", scriptPath, "")
+	defer func() { endSpan(span, nil, err) }()
+
 	scriptContent, err := os.ReadFile(scriptPath)
 	if err != nil {
 		return nil, fmt.Errorf("failed to read script file: %w", err)
 	}
 
-	if err := validateJSONPrint(string(scriptContent)); err != nil {
+	if err := r.validateOutputScript(string(scriptContent)); err != nil {
 		return nil, fmt.Errorf("invalid script format: %w", err)
 	}
 
-	result, err := r.Run(ctx, scriptPath, args...)
+	result, err := r.With(withContentTypeHint()).Run(ctx, scriptPath, args...)
 	if err != nil {
 		return &StructuredResult[T]{Result: result}, err
 	}
 
 	var output T
-	if err := json.Unmarshal([]byte(result.Stdout), &output); err != nil {
-		return &StructuredResult[T]{Result: result}, fmt.Errorf("failed to unmarshal script output: %w", err)
+	if err := r.decodeOutput(result, &output); err != nil {
+		return &StructuredResult[T]{Result: result}, err
 	}
 
 	return &StructuredResult[T]{
@@ -203,19 +785,22 @@ func StructuredOutput[T any](ctx context.Context, r *Runner, scriptPath string,
 }
 
 // StructuredOutputFromString runs a script from a string and parses its output into the specified type
-func StructuredOutputFromString[T any](ctx context.Context, r *Runner, script string, args ...string) (*StructuredResult[T], error) {
-	if err := validateJSONPrint(script); err != nil {
+func StructuredOutputFromString[T any](ctx context.Context, r *Runner, script string, args ...string) (_ *StructuredResult[T], err error) {
+	ctx, span := r.startSpan(ctx, "uvgo.StructuredOutput", "", "-", script)
+	defer func() { endSpan(span, nil, err) }()
+
+	if err := r.validateOutputScript(script); err != nil {
 		return nil, fmt.Errorf("invalid script format: %w", err)
 	}
 
-	result, err := r.RunFromString(ctx, script, args...)
+	result, err := r.With(withContentTypeHint()).RunFromString(ctx, script, args...)
 	if err != nil {
 		return &StructuredResult[T]{Result: result}, err
 	}
 
 	var output T
-	if err := json.Unmarshal([]byte(result.Stdout), &output); err != nil {
-		return &StructuredResult[T]{Result: result}, fmt.Errorf("failed to unmarshal script output: %w", err)
+	if err := r.decodeOutput(result, &output); err != nil {
+		return &StructuredResult[T]{Result: result}, err
 	}
 
 	return &StructuredResult[T]{
@@ -224,6 +809,18 @@ func StructuredOutputFromString[T any](ctx context.Context, r *Runner, script st
 	}, nil
 }
 
+// validateOutputScript checks that a script prints JSON as its last statement, unless it declares the content
+// type of its output or the runner decodes another content type
+func (r *Runner) validateOutputScript(script string) error {
+	if r.contentType != "" && r.contentType != "application/json" || strings.Contains(script, contentTypeDirective) {
+		if strings.TrimSpace(script) == "" {
+			return fmt.Errorf("empty script provided")
+		}
+		return nil
+	}
+	return validateJSONPrint(script)
+}
+
 func validateJSONPrint(script string) error {
 	if strings.TrimSpace(script) == "" {
 		return fmt.Errorf("empty script provided")
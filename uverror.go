@@ -0,0 +1,146 @@
+package uvgo
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+	"unicode"
+)
+
+// UVErrorKind classifies the failures uv reports before the script runs
+type UVErrorKind int
+
+const (
+	// UVErrorUnknown is a uv failure of no recognized kind
+	UVErrorUnknown UVErrorKind = iota
+	// UVErrorResolution means the dependencies cannot be satisfied, such as when no version of a package
+	// matches its constraint or the package does not exist
+	UVErrorResolution
+	// UVErrorPython means no available interpreter satisfies the Python requirement
+	UVErrorPython
+	// UVErrorNetwork means a package index or download could not be reached
+	UVErrorNetwork
+	// UVErrorBuild means a source distribution failed to build
+	UVErrorBuild
+)
+
+func (k UVErrorKind) String() string {
+	switch k {
+	case UVErrorResolution:
+		return "dependency resolution failed"
+	case UVErrorPython:
+		return "no matching Python interpreter"
+	case UVErrorNetwork:
+		return "network failure"
+	case UVErrorBuild:
+		return "package build failed"
+	default:
+		return "failed"
+	}
+}
+
+// UVError reports a run that failed in uv while preparing the environment, before the script started
+type UVError struct {
+	Kind UVErrorKind
+	// Package is the offending package, or "python" for interpreter failures, when uv names one
+	Package string
+	// Constraint is the version constraint that could not be met, such as ">=2.0", when uv names one
+	Constraint string
+	// Message is the diagnostic printed by uv, without its decorations
+	Message string
+	Err     error
+}
+
+func (e *UVError) Error() string {
+	subject := e.Package + e.Constraint
+	if e.Constraint != "" && unicode.IsDigit(rune(e.Constraint[0])) {
+		subject = e.Package + " " + e.Constraint
+	}
+	if subject != "" {
+		subject = " (" + subject + ")"
+	}
+	return fmt.Sprintf("uv %v%s: %s", e.Kind, subject, e.Message)
+}
+
+func (e *UVError) Unwrap() error {
+	return e.Err
+}
+
+var (
+	uvDiagnosticPrefix = regexp.MustCompile(`^\s*(?:error:|×|[╰├│]─▶|Caused by:|help:|hint:)\s*`)
+	uvNoVersion        = regexp.MustCompile("(?i)there is no version of `?([A-Za-z0-9][A-Za-z0-9._-]*)(\\[[^\\]]*\\])?([<>=!~][^`\\s,]*)`?")
+	uvNotFound         = regexp.MustCompile("(?i)`?([A-Za-z0-9][A-Za-z0-9._-]*)`? was not found in the (?:package registry|cache)")
+	uvRequire          = regexp.MustCompile("(?i)you require `?([A-Za-z0-9][A-Za-z0-9._-]*)(\\[[^\\]]*\\])?([<>=!~][^`\\s,]*)?`?")
+	uvNoInterpreter    = regexp.MustCompile("(?i)no interpreter found for python ([^\\s]+)")
+	uvPythonRequire    = regexp.MustCompile("(?i)python requirement:? `([^`]+)`")
+	uvQuotedRequire    = regexp.MustCompile("`([A-Za-z0-9][A-Za-z0-9._-]*)(?:\\[[^\\]]*\\])?((?:==|>=|<=|!=|~=|>|<)[^`]*)?`")
+)
+
+// uvMarker matches the decorations uv prints around its diagnostics, which scripts do not mimic
+var uvMarker = regexp.MustCompile(`(?m)^\s*(?:×|[╰├│]─▶|Caused by:)`)
+
+// ParseUVError extracts the diagnostic of a failure uv reported while preparing the environment from the stderr
+// of a run, returning nil when stderr holds no uv error or the script itself raised an exception. Scripts often
+// report their own failures on lines starting with "error:", so such lines only count as a uv error when uv's
+// decorations accompany them, or when they report a missing interpreter, which stops uv before any script starts.
+func ParseUVError(stderr string) *UVError {
+	if ParseTraceback(stderr) != nil {
+		return nil
+	}
+
+	var lines []string
+	for _, line := range strings.Split(stderr, "\n") {
+		if lines == nil && !strings.HasPrefix(line, "error:") && !strings.HasPrefix(strings.TrimSpace(line), "× ") {
+			continue
+		}
+		if line = strings.TrimSpace(uvDiagnosticPrefix.ReplaceAllString(line, "")); line != "" {
+			lines = append(lines, line)
+		}
+	}
+	if len(lines) == 0 {
+		return nil
+	}
+
+	e := &UVError{Message: strings.Join(lines, "\n")}
+	text := strings.ToLower(e.Message)
+	switch {
+	case strings.Contains(text, "no interpreter found") || strings.Contains(text, "python requirement"):
+		e.Kind = UVErrorPython
+		e.Package = "python"
+		if m := uvNoInterpreter.FindStringSubmatch(e.Message); m != nil {
+			e.Constraint = m[1]
+		} else if m := uvPythonRequire.FindStringSubmatch(e.Message); m != nil {
+			e.Constraint = m[1]
+		}
+	case strings.Contains(text, "no solution found") || strings.Contains(text, "unsatisfiable"):
+		e.Kind = UVErrorResolution
+		if m := uvNoVersion.FindStringSubmatch(e.Message); m != nil {
+			e.Package, e.Constraint = m[1], m[3]
+		} else if m := uvNotFound.FindStringSubmatch(e.Message); m != nil {
+			e.Package = m[1]
+		} else if m := uvRequire.FindStringSubmatch(e.Message); m != nil {
+			e.Package, e.Constraint = m[1], m[3]
+		}
+	case strings.Contains(text, "failed to build") || strings.Contains(text, "build backend") ||
+		strings.Contains(text, "download and build"):
+		e.Kind = UVErrorBuild
+		e.Package, e.Constraint = uvQuotedPackage(e.Message)
+	case strings.Contains(text, "failed to fetch") || strings.Contains(text, "failed to download") ||
+		strings.Contains(text, "error sending request") || strings.Contains(text, "dns error") ||
+		strings.Contains(text, "connection refused") || strings.Contains(text, "network connectivity is disabled"):
+		e.Kind = UVErrorNetwork
+		e.Package, e.Constraint = uvQuotedPackage(e.Message)
+	}
+	if e.Kind != UVErrorPython && !uvMarker.MatchString(stderr) {
+		return nil
+	}
+	return e
+}
+
+// uvQuotedPackage returns the first requirement uv quoted in backticks, skipping URLs
+func uvQuotedPackage(message string) (name, constraint string) {
+	if m := uvQuotedRequire.FindStringSubmatch(message); m != nil {
+		return m[1], m[2]
+	}
+	return "", ""
+}
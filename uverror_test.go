@@ -0,0 +1,138 @@
+package uvgo
+
+import "testing"
+
+func TestParseUVError(t *testing.T) {
+	tests := []struct {
+		name       string
+		stderr     string
+		wantNil    bool
+		kind       UVErrorKind
+		pkg        string
+		constraint string
+		message    string
+	}{
+		{
+			name: "missing version",
+			stderr: "  × No solution found when resolving script dependencies:\n" +
+				"  ╰─▶ Because there is no version of requests==99.0 and you require requests==99.0, we can conclude that your requirements are unsatisfiable.\n",
+			kind:       UVErrorResolution,
+			pkg:        "requests",
+			constraint: "==99.0",
+			message: "No solution found when resolving script dependencies:\n" +
+				"Because there is no version of requests==99.0 and you require requests==99.0, we can conclude that your requirements are unsatisfiable.",
+		},
+		{
+			name: "package not found",
+			stderr: "  × No solution found when resolving script dependencies:\n" +
+				"  ╰─▶ Because not-a-real-package was not found in the package registry and you require not-a-real-package, we can conclude that your requirements are unsatisfiable.\n",
+			kind: UVErrorResolution,
+			pkg:  "not-a-real-package",
+		},
+		{
+			name:       "missing interpreter without decorations",
+			stderr:     "error: No interpreter found for Python >=3.99 in virtual environments, managed installations, or search path\n",
+			kind:       UVErrorPython,
+			pkg:        "python",
+			constraint: ">=3.99",
+		},
+		{
+			name: "network failure",
+			stderr: "error: Failed to fetch: `https://pypi.org/simple/requests/`\n" +
+				"  Caused by: Request failed after 3 retries\n" +
+				"  Caused by: error sending request for url (https://pypi.org/simple/requests/)\n",
+			kind: UVErrorNetwork,
+			message: "Failed to fetch: `https://pypi.org/simple/requests/`\n" +
+				"Request failed after 3 retries\n" +
+				"error sending request for url (https://pypi.org/simple/requests/)",
+		},
+		{
+			name: "build failure",
+			stderr: "  × Failed to build `numpy==1.0`\n" +
+				"  ├─▶ The build backend returned an error\n" +
+				"  ╰─▶ Call to `setuptools.build_meta.build_wheel` failed (exit status: 1)\n" +
+				"\n" +
+				"      hint: This usually indicates a problem with the package or the build environment.\n",
+			kind:       UVErrorBuild,
+			pkg:        "numpy",
+			constraint: "==1.0",
+		},
+		{
+			name: "decorated failure of no recognized kind",
+			stderr: "error: Failed to parse script metadata\n" +
+				"  Caused by: TOML parse error at line 1, column 1\n",
+			kind: UVErrorUnknown,
+		},
+		{
+			name:    "script reporting its own error",
+			stderr:  "error: failed to fetch data from the API\n",
+			wantNil: true,
+		},
+		{
+			name:    "script mimicking a resolution failure",
+			stderr:  "error: no solution found for the puzzle\n",
+			wantNil: true,
+		},
+		{
+			name: "script traceback",
+			stderr: "error: about to fail\n" +
+				"Traceback (most recent call last):\n" +
+				"  File \"/tmp/script.py\", line 1, in <module>\n" +
+				"    raise ValueError(\"x\")\n" +
+				"ValueError: x\n",
+			wantNil: true,
+		},
+		{
+			name:    "no diagnostic",
+			stderr:  "Installed 3 packages in 5ms\n",
+			wantNil: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := ParseUVError(tt.stderr)
+			if tt.wantNil {
+				if got != nil {
+					t.Fatalf("ParseUVError() = %+v, want nil", got)
+				}
+				return
+			}
+			if got == nil {
+				t.Fatal("ParseUVError() = nil, want an error")
+			}
+			if got.Kind != tt.kind || got.Package != tt.pkg || got.Constraint != tt.constraint {
+				t.Errorf("ParseUVError() = {%v %q %q}, want {%v %q %q}", got.Kind, got.Package, got.Constraint, tt.kind, tt.pkg, tt.constraint)
+			}
+			if tt.message != "" && got.Message != tt.message {
+				t.Errorf("ParseUVError().Message = %q, want %q", got.Message, tt.message)
+			}
+		})
+	}
+}
+
+func TestUVErrorError(t *testing.T) {
+	tests := []struct {
+		err  *UVError
+		want string
+	}{
+		{
+			err:  &UVError{Kind: UVErrorResolution, Package: "requests", Constraint: "==99.0", Message: "no solution"},
+			want: "uv dependency resolution failed (requests==99.0): no solution",
+		},
+		{
+			err:  &UVError{Kind: UVErrorPython, Package: "python", Constraint: "3.99", Message: "no interpreter"},
+			want: "uv no matching Python interpreter (python 3.99): no interpreter",
+		},
+		{
+			err:  &UVError{Kind: UVErrorUnknown, Message: "bad metadata"},
+			want: "uv failed: bad metadata",
+		},
+	}
+
+	for _, tt := range tests {
+		if got := tt.err.Error(); got != tt.want {
+			t.Errorf("Error() = %q, want %q", got, tt.want)
+		}
+	}
+}
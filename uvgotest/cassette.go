@@ -0,0 +1,195 @@
+package uvgotest
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"regexp"
+	"slices"
+	"strings"
+	"sync"
+
+	"github.com/joeychilson/uvgo"
+)
+
+// Mode controls whether a Recorder runs uv or replays recorded interactions
+type Mode int
+
+const (
+	// ModeReplay serves every invocation from the cassette and fails on unknown ones
+	ModeReplay Mode = iota
+	// ModeRecord runs every invocation and records it, replacing the cassette
+	ModeRecord
+	// ModeReplayOrRecord replays known invocations and records unknown ones
+	ModeReplayOrRecord
+)
+
+// Interaction is a single recorded uv invocation
+type Interaction struct {
+	Args     []string `json:"args"`
+	Stdin    string   `json:"stdin,omitempty"`
+	Stdout   string   `json:"stdout"`
+	Stderr   string   `json:"stderr"`
+	ExitCode int      `json:"exit_code"`
+}
+
+type cassette struct {
+	Interactions []Interaction `json:"interactions"`
+}
+
+// Recorder is a uvgo.Executor that records uv invocations to a cassette file and replays them
+// deterministically. Temporary paths created by uvgo are normalized, but files that instrumentation options
+// read back after a run are not recorded.
+type Recorder struct {
+	mu       sync.Mutex
+	path     string
+	mode     Mode
+	inner    uvgo.Executor
+	cassette cassette
+	used     map[int]bool
+}
+
+// NewRecorder creates a recorder for the cassette at path, running uv with inner when recording.
+// A nil inner executor defaults to uvgo.LocalExecutor.
+func NewRecorder(path string, mode Mode, inner uvgo.Executor) (*Recorder, error) {
+	if inner == nil {
+		inner = uvgo.LocalExecutor{}
+	}
+
+	r := &Recorder{path: path, mode: mode, inner: inner, used: make(map[int]bool)}
+	if mode == ModeRecord {
+		return r, nil
+	}
+
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) && mode == ModeReplayOrRecord {
+		return r, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read cassette: %w", err)
+	}
+	if err := json.Unmarshal(data, &r.cassette); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal cassette: %w", err)
+	}
+	return r, nil
+}
+
+// Execute implements uvgo.Executor. Matching a recorded interaction needs the whole of stdin up front, so
+// stdin is read before replaying, except interactive stdin, which only ends with the run and is left out of
+// the match and the recording. Runs only recorded stream stdin to the process as usual.
+func (r *Recorder) Execute(ctx context.Context, inv *uvgo.Invocation) (*uvgo.ExitStatus, error) {
+	recorded := *inv
+	var stdin bytes.Buffer
+	switch {
+	case inv.Stdin == nil || inv.InteractiveStdin:
+	case r.mode == ModeRecord:
+		recorded.Stdin = io.TeeReader(inv.Stdin, &stdin)
+	default:
+		if _, err := stdin.ReadFrom(inv.Stdin); err != nil {
+			return nil, fmt.Errorf("failed to read stdin: %w", err)
+		}
+		recorded.Stdin = bytes.NewReader(stdin.Bytes())
+	}
+	args := normalizeArgs(inv.Args)
+
+	if r.mode != ModeRecord {
+		if interaction, ok := r.lookup(args, stdin.String(), !inv.InteractiveStdin); ok {
+			return replay(inv, interaction)
+		}
+		if r.mode == ModeReplay {
+			return nil, fmt.Errorf("%w: no recorded interaction for uv %s", ErrUnexpectedCall, strings.Join(args, " "))
+		}
+	}
+
+	var stdout, stderr bytes.Buffer
+	recorded.Stdout = io.MultiWriter(inv.Stdout, &stdout)
+	recorded.Stderr = io.MultiWriter(inv.Stderr, &stderr)
+
+	status, err := r.inner.Execute(ctx, &recorded)
+	if status == nil {
+		return nil, err
+	}
+
+	saveErr := r.save(Interaction{
+		Args:     args,
+		Stdin:    stdin.String(),
+		Stdout:   stdout.String(),
+		Stderr:   stderr.String(),
+		ExitCode: status.ExitCode,
+	})
+	if saveErr != nil && err == nil {
+		return status, saveErr
+	}
+	return status, err
+}
+
+// lookup finds the next recorded interaction with the given arguments and, when matchStdin is set, stdin
+func (r *Recorder) lookup(args []string, stdin string, matchStdin bool) (Interaction, bool) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	last := -1
+	for i, interaction := range r.cassette.Interactions {
+		if (matchStdin && interaction.Stdin != stdin) || !slices.Equal(interaction.Args, args) {
+			continue
+		}
+		last = i
+		if !r.used[i] {
+			r.used[i] = true
+			return interaction, true
+		}
+	}
+
+	// repeated invocations beyond the recorded ones reuse the last recording
+	if last >= 0 {
+		return r.cassette.Interactions[last], true
+	}
+	return Interaction{}, false
+}
+
+func (r *Recorder) save(interaction Interaction) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.cassette.Interactions = append(r.cassette.Interactions, interaction)
+	r.used[len(r.cassette.Interactions)-1] = true
+
+	data, err := json.MarshalIndent(r.cassette, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal cassette: %w", err)
+	}
+	if err := os.WriteFile(r.path, data, 0o644); err != nil {
+		return fmt.Errorf("failed to write cassette: %w", err)
+	}
+	return nil
+}
+
+func replay(inv *uvgo.Invocation, interaction Interaction) (*uvgo.ExitStatus, error) {
+	if inv.Stdout != nil {
+		io.WriteString(inv.Stdout, interaction.Stdout)
+	}
+	if inv.Stderr != nil {
+		io.WriteString(inv.Stderr, interaction.Stderr)
+	}
+
+	status := &uvgo.ExitStatus{ExitCode: interaction.ExitCode}
+	if interaction.ExitCode != 0 {
+		return status, &uvgo.ExitError{Code: interaction.ExitCode}
+	}
+	return status, nil
+}
+
+// tempPattern matches the temporary files and directories uvgo creates for a run
+var tempPattern = regexp.MustCompile(`\S*uvgo-(?:[a-z]+-)?\d+(?:\.py)?`)
+
+func normalizeArgs(args []string) []string {
+	out := make([]string, len(args))
+	for i, arg := range args {
+		out[i] = tempPattern.ReplaceAllString(arg, "$$UVGO_TEMP")
+	}
+	return out
+}
@@ -0,0 +1,221 @@
+// Package uvgotest provides a fake runner for testing code that embeds uvgo without uv or Python installed.
+package uvgotest
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"regexp"
+	"slices"
+	"strings"
+	"sync"
+
+	"github.com/joeychilson/uvgo"
+)
+
+// ErrUnexpectedCall is returned for runs that match no expectation
+var ErrUnexpectedCall = errors.New("uvgotest: unexpected call")
+
+// TB is the subset of testing.TB used for assertions
+type TB interface {
+	Helper()
+	Errorf(format string, args ...any)
+}
+
+// Call records a single script run handled by the fake
+type Call struct {
+	ScriptPath string
+	Script     string
+	Args       []string
+}
+
+// FakeRunner returns scripted results for script runs and records every call
+type FakeRunner struct {
+	mu           sync.Mutex
+	expectations []*Expectation
+	calls        []Call
+}
+
+// NewFakeRunner creates a fake runner without expectations
+func NewFakeRunner() *FakeRunner {
+	return &FakeRunner{}
+}
+
+// Runner creates a uvgo.Runner whose script runs are served by the fake
+func (f *FakeRunner) Runner(options ...uvgo.Option) *uvgo.Runner {
+	r, err := uvgo.New(append(options, f.Options()...)...)
+	if err != nil {
+		panic(fmt.Sprintf("uvgotest: failed to create runner: %v", err))
+	}
+	return r
+}
+
+// Options returns the options wiring the fake into a uvgo.Runner
+func (f *FakeRunner) Options() []uvgo.Option {
+	return []uvgo.Option{
+		uvgo.WithExecutor(executor{}),
+		uvgo.WithMiddleware(f.middleware),
+	}
+}
+
+// Expect registers a new expectation, matching every run until narrowed down
+func (f *FakeRunner) Expect() *Expectation {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	e := &Expectation{result: &uvgo.Result{}}
+	f.expectations = append(f.expectations, e)
+	return e
+}
+
+// Calls returns the runs handled by the fake in order
+func (f *FakeRunner) Calls() []Call {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return slices.Clone(f.calls)
+}
+
+// AssertExpectations reports every expectation that was not called the expected number of times
+func (f *FakeRunner) AssertExpectations(t TB) {
+	t.Helper()
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	for _, e := range f.expectations {
+		switch {
+		case e.times > 0 && e.calls != e.times:
+			t.Errorf("uvgotest: expected %s to be called %d times, got %d", e, e.times, e.calls)
+		case e.times == 0 && e.calls == 0:
+			t.Errorf("uvgotest: expected %s to be called", e)
+		}
+	}
+}
+
+func (f *FakeRunner) middleware(uvgo.RunFunc) uvgo.RunFunc {
+	return func(ctx context.Context, req *uvgo.RunRequest) (*uvgo.Result, error) {
+		call := Call{ScriptPath: req.ScriptPath, Script: req.Script, Args: req.Args}
+		if req.ScriptPath != "-" {
+			content, err := os.ReadFile(req.ScriptPath)
+			if err != nil {
+				return nil, fmt.Errorf("uvgotest: failed to read script: %w", err)
+			}
+			call.Script = string(content)
+		}
+
+		f.mu.Lock()
+		defer f.mu.Unlock()
+
+		f.calls = append(f.calls, call)
+
+		for _, e := range f.expectations {
+			if e.exhausted() || !e.match(call) {
+				continue
+			}
+			e.calls++
+
+			result := *e.result
+			return &result, e.err
+		}
+		return nil, fmt.Errorf("%w: script run with args %q", ErrUnexpectedCall, call.Args)
+	}
+}
+
+// Expectation describes the runs it matches and the result returned for them
+type Expectation struct {
+	contains []string
+	pattern  *regexp.Regexp
+	args     []string
+	hasArgs  bool
+	times    int
+	calls    int
+	result   *uvgo.Result
+	err      error
+}
+
+// ScriptContains matches runs whose script content contains s
+func (e *Expectation) ScriptContains(s string) *Expectation {
+	e.contains = append(e.contains, s)
+	return e
+}
+
+// ScriptMatches matches runs whose script content matches the regular expression
+func (e *Expectation) ScriptMatches(pattern string) *Expectation {
+	e.pattern = regexp.MustCompile(pattern)
+	return e
+}
+
+// WithArgs matches runs called with exactly args
+func (e *Expectation) WithArgs(args ...string) *Expectation {
+	e.args = args
+	e.hasArgs = true
+	return e
+}
+
+// Times limits the expectation to n calls, after which it no longer matches
+func (e *Expectation) Times(n int) *Expectation {
+	e.times = n
+	return e
+}
+
+// Return sets the result returned for matching runs
+func (e *Expectation) Return(result *uvgo.Result) *Expectation {
+	e.result = result
+	return e
+}
+
+// ReturnStdout returns a result with the given standard output for matching runs
+func (e *Expectation) ReturnStdout(stdout string) *Expectation {
+	e.result = &uvgo.Result{Stdout: stdout}
+	return e
+}
+
+// ReturnError returns err for matching runs alongside the configured result
+func (e *Expectation) ReturnError(err error) *Expectation {
+	e.err = err
+	return e
+}
+
+func (e *Expectation) exhausted() bool {
+	return e.times > 0 && e.calls >= e.times
+}
+
+func (e *Expectation) match(call Call) bool {
+	for _, s := range e.contains {
+		if !strings.Contains(call.Script, s) {
+			return false
+		}
+	}
+	if e.pattern != nil && !e.pattern.MatchString(call.Script) {
+		return false
+	}
+	if e.hasArgs && !slices.Equal(e.args, call.Args) {
+		return false
+	}
+	return true
+}
+
+func (e *Expectation) String() string {
+	var parts []string
+	for _, s := range e.contains {
+		parts = append(parts, fmt.Sprintf("script containing %q", s))
+	}
+	if e.pattern != nil {
+		parts = append(parts, fmt.Sprintf("script matching %q", e.pattern))
+	}
+	if e.hasArgs {
+		parts = append(parts, fmt.Sprintf("args %q", e.args))
+	}
+	if len(parts) == 0 {
+		return "any run"
+	}
+	return strings.Join(parts, " and ")
+}
+
+// executor rejects uv invocations that bypass the run middleware, such as type checks and tests
+type executor struct{}
+
+func (executor) Execute(ctx context.Context, inv *uvgo.Invocation) (*uvgo.ExitStatus, error) {
+	return nil, fmt.Errorf("%w: uv %s", ErrUnexpectedCall, strings.Join(inv.Args, " "))
+}
@@ -0,0 +1,73 @@
+package uvgo
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"sync/atomic"
+)
+
+// uvProcesses bounds the uv processes of every runner in the process, nil when unlimited
+var uvProcesses atomic.Pointer[chan struct{}]
+
+// SetUVProcessLimit allows at most n uv processes, across every runner in the Go process, to run at once,
+// so many runs starting together do not all resolve and download at the same time. Further invocations wait
+// for a slot until their context ends. A limit of zero or less removes the limit. Invocations already holding
+// a slot keep it when the limit changes. Detached runs and servers, which outlive the call starting them,
+// are not counted.
+func SetUVProcessLimit(n int) {
+	if n <= 0 {
+		uvProcesses.Store(nil)
+		return
+	}
+	slots := make(chan struct{}, n)
+	uvProcesses.Store(&slots)
+}
+
+// acquireUVProcess takes a uv process slot, returning a function releasing it
+func acquireUVProcess(ctx context.Context) (func(), error) {
+	slots := uvProcesses.Load()
+	if slots == nil {
+		return func() {}, nil
+	}
+
+	select {
+	case *slots <- struct{}{}:
+		return func() { <-*slots }, nil
+	case <-ctx.Done():
+		return nil, fmt.Errorf("uv process wait cancelled: %w", ctx.Err())
+	}
+}
+
+// WithConcurrentDownloads sets the maximum number of packages uv downloads at once (UV_CONCURRENT_DOWNLOADS)
+func WithConcurrentDownloads(n int) Option {
+	return func(r *Runner) { r.downloadLimit = n }
+}
+
+// WithConcurrentBuilds sets the maximum number of source distributions uv builds at once (UV_CONCURRENT_BUILDS)
+func WithConcurrentBuilds(n int) Option {
+	return func(r *Runner) { r.buildLimit = n }
+}
+
+// WithConcurrentInstalls sets the maximum number of threads uv installs packages with (UV_CONCURRENT_INSTALLS)
+func WithConcurrentInstalls(n int) Option {
+	return func(r *Runner) { r.installLimit = n }
+}
+
+// concurrencyEnv returns the environment variables tuning uv's concurrency, leaving unset limits to uv
+func (r *Runner) concurrencyEnv() []string {
+	var env []string
+	for _, limit := range []struct {
+		name string
+		n    int
+	}{
+		{"UV_CONCURRENT_DOWNLOADS", r.downloadLimit},
+		{"UV_CONCURRENT_BUILDS", r.buildLimit},
+		{"UV_CONCURRENT_INSTALLS", r.installLimit},
+	} {
+		if limit.n > 0 {
+			env = append(env, limit.name+"="+strconv.Itoa(limit.n))
+		}
+	}
+	return env
+}
@@ -0,0 +1,72 @@
+package uvgo
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+)
+
+// warningDirective prefixes the stderr lines carrying Python warnings
+const warningDirective = "@uvgo:warning"
+
+// Warning is a Python warning emitted by a script
+type Warning struct {
+	// Category is the warning class, such as "DeprecationWarning"
+	Category string `json:"category"`
+	Message  string `json:"message"`
+	File     string `json:"file"`
+	Line     int    `json:"line"`
+}
+
+// String formats the warning the way Python prints it
+func (w Warning) String() string {
+	return fmt.Sprintf("%s:%d: %s: %s", w.File, w.Line, w.Category, w.Message)
+}
+
+// WithWarnings collects the Python warnings a script emits into Result.Warnings instead of printing them to
+// stderr. Filters use the -W syntax, such as "error::RuntimeWarning" or "ignore::UserWarning:mymodule", and
+// default to "default", which shows every warning once per location, including the deprecation warnings Python
+// hides by default.
+func WithWarnings(filters ...string) Option {
+	return func(r *Runner) {
+		r.warnings = true
+		r.warningFilters = filters
+	}
+}
+
+const warningsSetup = `
+import json as _uvgo_json
+import warnings as _uvgo_warnings
+
+_uvgo_showwarning_default = _uvgo_warnings.showwarning
+
+
+def _uvgo_showwarning(message, category, filename, lineno, file=None, line=None):
+    if file is not None and file is not sys.stderr:
+        _uvgo_showwarning_default(message, category, filename, lineno, file, line)
+        return
+    entry = {"category": category.__name__, "message": str(message), "file": filename, "line": lineno}
+    sys.stderr.write("@uvgo:warning " + _uvgo_json.dumps(entry) + "\n")
+    sys.stderr.flush()
+
+
+_uvgo_warnings.showwarning = _uvgo_showwarning
+`
+
+// withWarnings routes warnings through the directive protocol with the configured filters
+func (x *execution) withWarnings(filters []string) {
+	if len(filters) == 0 {
+		filters = []string{"default"}
+	}
+	x.env = append(x.env, "PYTHONWARNINGS="+strings.Join(filters, ","))
+	x.setup = append(x.setup, warningsSetup)
+}
+
+// parseWarning decodes the payload of a warning line
+func parseWarning(payload string) Warning {
+	var w Warning
+	if err := json.Unmarshal([]byte(payload), &w); err != nil {
+		return Warning{Category: "Warning", Message: payload}
+	}
+	return w
+}
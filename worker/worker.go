@@ -0,0 +1,189 @@
+// Package worker consumes script execution jobs from a message queue and publishes their results, so a fleet
+// of processes can share script execution. Brokers such as NATS, AMQP or SQS plug in through the Consumer and
+// Publisher interfaces; for example a NATS JetStream message maps Ack to msg.Ack and Nack to msg.Nak, and an
+// SQS message maps Ack to DeleteMessage and Nack to resetting its visibility timeout.
+package worker
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/joeychilson/uvgo"
+)
+
+// Job is the JSON body of a job message
+type Job struct {
+	ID string `json:"id"`
+	// Name selects a script from the worker's registry
+	Name string `json:"name,omitempty"`
+	// ScriptPath and Script run an arbitrary script, only accepted when the worker allows inline scripts
+	ScriptPath string `json:"script_path,omitempty"`
+	Script     string `json:"script,omitempty"`
+	// Args are passed to inline scripts, registered scripts using their registered arguments
+	Args []string `json:"args,omitempty"`
+	// Payload is passed to the script in the UVGO_INPUT environment variable
+	Payload json.RawMessage `json:"payload,omitempty"`
+}
+
+// JobResult is published for every job the worker processed
+type JobResult struct {
+	ID       string        `json:"id"`
+	Stdout   string        `json:"stdout"`
+	Stderr   string        `json:"stderr"`
+	ExitCode int           `json:"exit_code"`
+	Duration time.Duration `json:"duration"`
+	// Data holds stdout when it is valid JSON
+	Data  json.RawMessage `json:"data,omitempty"`
+	Error string          `json:"error,omitempty"`
+}
+
+// Delivery is a message received from a queue
+type Delivery interface {
+	Body() []byte
+	// Ack confirms the message was processed so the broker does not deliver it again
+	Ack(ctx context.Context) error
+	// Nack returns the message to the broker for redelivery
+	Nack(ctx context.Context) error
+}
+
+// Consumer receives messages from a queue
+type Consumer interface {
+	// Receive blocks until a message is available or ctx ends
+	Receive(ctx context.Context) (Delivery, error)
+}
+
+// Publisher publishes job results, typically to a results subject or the reply address of the delivery
+type Publisher interface {
+	Publish(ctx context.Context, d Delivery, result JobResult) error
+}
+
+// Worker runs jobs received from a Consumer and publishes their results
+type Worker struct {
+	Runner    *uvgo.Runner
+	Consumer  Consumer
+	Publisher Publisher
+	// Registry resolves jobs that name a script
+	Registry *uvgo.Registry
+	// AllowInline accepts jobs carrying a script path or script content. Only enable it when every producer
+	// is trusted, since such jobs run arbitrary code.
+	AllowInline bool
+	// Concurrency is the number of jobs run at once, defaulting to 1
+	Concurrency int
+	// OnError, when set, is called with errors that did not prevent the worker from continuing, such as
+	// failures to acknowledge or publish
+	OnError func(err error)
+}
+
+// Run processes messages until ctx is cancelled, then waits for in-flight jobs. Jobs interrupted by the
+// cancellation, or whose result could not be published, are returned to the queue for redelivery.
+func (w *Worker) Run(ctx context.Context) error {
+	var wg sync.WaitGroup
+	for range max(w.Concurrency, 1) {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			w.loop(ctx)
+		}()
+	}
+	wg.Wait()
+	return nil
+}
+
+func (w *Worker) loop(ctx context.Context) {
+	for ctx.Err() == nil {
+		d, err := w.Consumer.Receive(ctx)
+		if err != nil {
+			if ctx.Err() == nil {
+				w.report(fmt.Errorf("failed to receive message: %w", err))
+				select {
+				case <-time.After(time.Second):
+				case <-ctx.Done():
+				}
+			}
+			continue
+		}
+		w.process(ctx, d)
+	}
+}
+
+// process runs a delivered job and settles the delivery
+func (w *Worker) process(ctx context.Context, d Delivery) {
+	// settling must succeed even while shutting down
+	settleCtx := context.WithoutCancel(ctx)
+
+	var job Job
+	if err := json.Unmarshal(d.Body(), &job); err != nil {
+		// a malformed message can never succeed, so it is acknowledged rather than redelivered forever
+		w.publish(settleCtx, d, JobResult{Error: fmt.Sprintf("invalid job message: %v", err)})
+		return
+	}
+
+	result, err := w.run(ctx, job)
+	if err != nil && ctx.Err() != nil {
+		if nackErr := d.Nack(settleCtx); nackErr != nil {
+			w.report(fmt.Errorf("failed to nack job %s: %w", job.ID, nackErr))
+		}
+		return
+	}
+
+	jobResult := JobResult{ID: job.ID}
+	if result != nil {
+		jobResult.Stdout = result.Stdout
+		jobResult.Stderr = result.Stderr
+		jobResult.ExitCode = result.ExitCode
+		jobResult.Duration = result.WallTime
+		if data := []byte(result.Stdout); json.Valid(data) {
+			jobResult.Data = data
+		}
+	}
+	if err != nil {
+		jobResult.Error = err.Error()
+	}
+	w.publish(settleCtx, d, jobResult)
+}
+
+// publish sends a result and acknowledges the delivery, or returns it to the queue when publishing fails
+func (w *Worker) publish(ctx context.Context, d Delivery, result JobResult) {
+	if err := w.Publisher.Publish(ctx, d, result); err != nil {
+		w.report(fmt.Errorf("failed to publish result of job %s: %w", result.ID, err))
+		if err := d.Nack(ctx); err != nil {
+			w.report(fmt.Errorf("failed to nack job %s: %w", result.ID, err))
+		}
+		return
+	}
+	if err := d.Ack(ctx); err != nil {
+		w.report(fmt.Errorf("failed to ack job %s: %w", result.ID, err))
+	}
+}
+
+func (w *Worker) run(ctx context.Context, job Job) (*uvgo.Result, error) {
+	if job.Name != "" {
+		if w.Registry == nil {
+			return nil, errors.New("worker has no script registry")
+		}
+		return w.Registry.Run(ctx, w.Runner, job.Name, job.Payload)
+	}
+
+	if !w.AllowInline {
+		return nil, errors.New("inline scripts are not allowed")
+	}
+
+	runner := w.Runner
+	if len(job.Payload) > 0 {
+		runner = runner.With(uvgo.WithInput(job.Payload))
+	}
+	if job.ScriptPath != "" {
+		return runner.Run(ctx, job.ScriptPath, job.Args...)
+	}
+	return runner.RunFromString(ctx, job.Script, job.Args...)
+}
+
+func (w *Worker) report(err error) {
+	if w.OnError != nil {
+		w.OnError(err)
+	}
+}